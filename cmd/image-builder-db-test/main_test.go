@@ -1,3 +1,4 @@
+//go:build integration
 // +build integration
 
 package main
@@ -13,6 +14,7 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/require"
 
+	"github.com/osbuild/image-builder/internal/common"
 	"github.com/osbuild/image-builder/internal/config"
 	"github.com/osbuild/image-builder/internal/db"
 )
@@ -75,6 +77,9 @@ func connect(t *testing.T) *pgx.Conn {
 func tearDown(t *testing.T) {
 	conn := connect(t)
 	defer conn.Close(context.Background())
+	conn.Exec(context.Background(), "drop table audit")
+	conn.Exec(context.Background(), "drop table subscription_profiles")
+	conn.Exec(context.Background(), "drop table ostree_commits")
 	conn.Exec(context.Background(), "drop table clones")
 	conn.Exec(context.Background(), "drop table composes")
 	conn.Exec(context.Background(), "drop table if exists schema_migrations")
@@ -90,9 +95,9 @@ func testInsertCompose(t *testing.T) {
 	migrateTern(t)
 
 	// test
-	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"))
+	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"), "default")
 	require.NoError(t, err)
-	err = d.InsertCompose(uuid.New(), "", ORGID1, &imageName, []byte("{}"))
+	err = d.InsertCompose(uuid.New(), "", ORGID1, &imageName, []byte("{}"), "default")
 	require.NoError(t, err)
 }
 
@@ -102,24 +107,24 @@ func testGetCompose(t *testing.T) {
 
 	imageName := "MyImageName"
 
-	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"))
+	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"), "default")
 	require.NoError(t, err)
-	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"))
+	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"), "default")
 	require.NoError(t, err)
-	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"))
+	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"), "default")
 	require.NoError(t, err)
-	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"))
+	err = d.InsertCompose(uuid.New(), ANR1, ORGID1, &imageName, []byte("{}"), "default")
 	require.NoError(t, err)
 
 	// test
 	// GetComposes works as expected
-	composes, count, err := d.GetComposes(ORGID1, fortnight, 100, 0)
+	composes, count, err := d.GetComposes(ORGID1, db.ComposesFilter{MaxAge: fortnight}, nil, 100, 0)
 	require.NoError(t, err)
 	require.Equal(t, 4, count)
 	require.Equal(t, 4, len(composes))
 
 	// count returns total in db, ignoring limits
-	composes, count, err = d.GetComposes(ORGID1, fortnight, 1, 2)
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{MaxAge: fortnight}, nil, 1, 2)
 	require.NoError(t, err)
 	require.Equal(t, 4, count)
 	require.Equal(t, 1, len(composes))
@@ -180,7 +185,7 @@ func testCountGetComposesSince(t *testing.T) {
 	insert := "INSERT INTO composes(job_id, request, created_at, account_number, org_id) VALUES ($1, $2, CURRENT_TIMESTAMP - interval '2 days', $3, $4)"
 	_, err = conn.Exec(context.Background(), insert, job1, "{}", ANR3, ORGID3)
 
-	composes, count, err := d.GetComposes(ORGID3, fortnight, 100, 0)
+	composes, count, err := d.GetComposes(ORGID3, db.ComposesFilter{MaxAge: fortnight}, nil, 100, 0)
 	require.Equal(t, 1, count)
 	require.NoError(t, err)
 	require.Equal(t, job1, composes[0].Id)
@@ -190,13 +195,13 @@ func testCountGetComposesSince(t *testing.T) {
 	_, err = conn.Exec(context.Background(), insert, job2, "{}", ANR3, ORGID3)
 
 	// job2 is outside of time range
-	composes, count, err = d.GetComposes(ORGID3, fortnight, 100, 0)
+	composes, count, err = d.GetComposes(ORGID3, db.ComposesFilter{MaxAge: fortnight}, nil, 100, 0)
 	require.Equal(t, 1, count)
 	require.NoError(t, err)
 	require.Equal(t, job1, composes[0].Id)
 
 	// correct ordering (recent first)
-	composes, count, err = d.GetComposes(ORGID3, fortnight*2, 100, 0)
+	composes, count, err = d.GetComposes(ORGID3, db.ComposesFilter{MaxAge: fortnight * 2}, nil, 100, 0)
 	require.Equal(t, 2, count)
 	require.NoError(t, err)
 	require.Equal(t, job1, composes[0].Id)
@@ -257,7 +262,7 @@ func testDeleteCompose(t *testing.T) {
 	err = d.DeleteCompose(composeId, ORGID1)
 	require.NoError(t, err)
 
-	_, count, err := d.GetComposes(ORGID1, fortnight, 100, 0)
+	_, count, err := d.GetComposes(ORGID1, db.ComposesFilter{MaxAge: fortnight}, nil, 100, 0)
 	require.NoError(t, err)
 	require.Equal(t, 0, count)
 
@@ -267,6 +272,85 @@ func testDeleteCompose(t *testing.T) {
 	require.Equal(t, 1, count)
 }
 
+func testRestoreCompose(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+	conn := connect(t)
+	defer conn.Close(context.Background())
+
+	composeId := uuid.New()
+	require.NoError(t, d.InsertCompose(composeId, ANR1, ORGID1, nil, []byte("{}"), "default"))
+
+	// only deleted composes can be restored
+	err = d.RestoreCompose(composeId, ORGID1, time.Hour)
+	require.Equal(t, db.ComposeNotFoundError, err)
+
+	require.NoError(t, d.DeleteCompose(composeId, ORGID1))
+
+	// deleting twice isn't possible
+	err = d.DeleteCompose(composeId, ORGID1)
+	require.Equal(t, db.ComposeNotFoundError, err)
+
+	err = d.RestoreCompose(composeId, ORGID2, time.Hour)
+	require.Equal(t, db.ComposeNotFoundError, err)
+
+	require.NoError(t, d.RestoreCompose(composeId, ORGID1, time.Hour))
+	_, err = d.GetCompose(composeId, ORGID1)
+	require.NoError(t, err)
+
+	// outside of the grace period
+	require.NoError(t, d.DeleteCompose(composeId, ORGID1))
+	_, err = conn.Exec(context.Background(), "UPDATE composes SET deleted_at = CURRENT_TIMESTAMP - $1 WHERE job_id = $2", 2*time.Hour, composeId)
+	require.NoError(t, err)
+	err = d.RestoreCompose(composeId, ORGID1, time.Hour)
+	require.Equal(t, db.ComposeNotFoundError, err)
+}
+
+func testPurgeComposes(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+	conn := connect(t)
+	defer conn.Close(context.Background())
+
+	recent := uuid.New()
+	deletedRecently := uuid.New()
+	deletedLongAgo := uuid.New()
+	old := uuid.New()
+	day := 24 * time.Hour
+	insert := "INSERT INTO composes(job_id, request, created_at, account_number, org_id, deleted, deleted_at) VALUES ($1, '{}', CURRENT_TIMESTAMP - $2, $3, $4, $5, CURRENT_TIMESTAMP - $6)"
+	_, err = conn.Exec(context.Background(), insert, recent, day, ANR1, ORGID1, false, nil)
+	require.NoError(t, err)
+	_, err = conn.Exec(context.Background(), insert, deletedRecently, 3*day, ANR1, ORGID1, true, day)
+	require.NoError(t, err)
+	_, err = conn.Exec(context.Background(), insert, deletedLongAgo, 40*day, ANR1, ORGID1, true, 35*day)
+	require.NoError(t, err)
+	_, err = conn.Exec(context.Background(), insert, old, 100*day, ANR1, ORGID1, false, nil)
+	require.NoError(t, err)
+
+	// clones get removed along with their compose
+	cloneId := uuid.New()
+	require.NoError(t, d.InsertClone(deletedLongAgo, cloneId, []byte("{}"), nil))
+
+	purged, err := d.PurgeDeletedComposes(30 * day)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+	_, err = d.GetClone(cloneId, ORGID1)
+	require.ErrorIs(t, err, db.CloneNotFoundError)
+
+	purged, err = d.PurgeComposesOlderThan(90 * day)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+
+	composes, count, err := d.GetComposes(ORGID1, db.ComposesFilter{MaxAge: fortnight}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, recent, composes[0].Id)
+
+	count, err = d.CountComposesSince(ORGID1, fortnight)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
+
 func testClones(t *testing.T) {
 	d, err := db.InitDBConnectionPool(connStr(t))
 	require.NoError(t, err)
@@ -282,7 +366,7 @@ func testClones(t *testing.T) {
 {
   "region": "us-east-2"
 }
-`)))
+`), nil))
 
 	require.NoError(t, d.InsertCompose(composeId, ANR1, ORGID1, nil, []byte(`
 {
@@ -300,31 +384,31 @@ func testClones(t *testing.T) {
       }
     }
   ]
-}`)))
+}`), "default"))
 
 	require.NoError(t, d.InsertClone(composeId, cloneId, []byte(`
 {
   "region": "us-east-2"
 }
-`)))
+`), nil))
 	require.NoError(t, d.InsertClone(composeId, cloneId2, []byte(`
 {
   "region": "eu-central-1"
 }
-`)))
+`), nil))
 
-	clones, count, err := d.GetClonesForCompose(composeId, ORGID2, 100, 0)
+	clones, count, err := d.GetClonesForCompose(composeId, ORGID2, nil, 100, 0)
 	require.NoError(t, err)
 	require.Empty(t, clones)
 	require.Equal(t, 0, count)
 
-	clones, count, err = d.GetClonesForCompose(composeId, ORGID1, 1, 0)
+	clones, count, err = d.GetClonesForCompose(composeId, ORGID1, nil, 1, 0)
 	require.NoError(t, err)
 	require.Len(t, clones, 1)
 	require.Equal(t, 2, count)
 	require.Equal(t, cloneId2, clones[0].Id)
 
-	clones, count, err = d.GetClonesForCompose(composeId, ORGID1, 100, 0)
+	clones, count, err = d.GetClonesForCompose(composeId, ORGID1, nil, 100, 0)
 	require.NoError(t, err)
 	require.Len(t, clones, 2)
 	require.Equal(t, 2, count)
@@ -338,6 +422,439 @@ func testClones(t *testing.T) {
 	entry, err = d.GetClone(cloneId, ORGID1)
 	require.NoError(t, err)
 	require.Equal(t, clones[1], *entry)
+
+	// keyset pagination from either clone
+	clones, count, err = d.GetClonesForCompose(composeId, ORGID1, &db.Keyset{CreatedAt: clones[0].CreatedAt, Id: cloneId2}, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, clones, 1)
+	require.Equal(t, cloneId, clones[0].Id)
+
+	clones, _, err = d.GetClonesForCompose(composeId, ORGID1, &db.Keyset{CreatedAt: clones[0].CreatedAt, Id: cloneId, Before: true}, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, clones, 1)
+	require.Equal(t, cloneId2, clones[0].Id)
+}
+
+func testAudit(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+
+	composeId := uuid.New()
+	cloneId := uuid.New()
+
+	require.NoError(t, d.InsertAuditEntry(ORGID1, ANR1, "user1", db.AuditActionCompose, composeId, "abcd"))
+	require.NoError(t, d.InsertAuditEntry(ORGID1, ANR1, "user2", db.AuditActionClone, cloneId, "ef01"))
+	require.NoError(t, d.InsertAuditEntry(ORGID2, ANR2, "user3", db.AuditActionDelete, composeId, ""))
+
+	entries, count, err := d.GetAuditEntries(ORGID1, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, entries, 2)
+	// most recent first
+	require.Equal(t, db.AuditActionClone, entries[0].Action)
+	require.Equal(t, cloneId, entries[0].TargetId)
+	require.Equal(t, "user2", entries[0].Username)
+	require.Equal(t, "ef01", entries[0].RequestHash)
+	require.Equal(t, db.AuditActionCompose, entries[1].Action)
+
+	// count returns total in db, ignoring limits
+	entries, count, err = d.GetAuditEntries(ORGID1, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, entries, 1)
+	require.Equal(t, composeId, entries[0].TargetId)
+
+	entries, count, err = d.GetAuditEntries(ORGID3, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Empty(t, entries)
+}
+
+func testSubscriptionProfiles(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+
+	prod := db.SubscriptionProfileEntry{
+		Id:            uuid.New(),
+		Name:          "production",
+		Organization:  2040324,
+		ActivationKey: []byte("encrypted1"),
+		ServerUrl:     "subscription.rhsm.redhat.com",
+		BaseUrl:       "http://cdn.redhat.com/",
+		Insights:      true,
+	}
+	dev := prod
+	dev.Id = uuid.New()
+	dev.Name = "development"
+	dev.ActivationKey = []byte("encrypted2")
+	require.NoError(t, d.InsertSubscriptionProfile(ORGID1, prod))
+	require.NoError(t, d.InsertSubscriptionProfile(ORGID1, dev))
+
+	// names are unique within an organization
+	dup := prod
+	dup.Id = uuid.New()
+	require.ErrorIs(t, d.InsertSubscriptionProfile(ORGID1, dup), db.SubscriptionProfileExistsError)
+	require.NoError(t, d.InsertSubscriptionProfile(ORGID2, dup))
+
+	entries, count, err := d.GetSubscriptionProfiles(ORGID1, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, entries, 2)
+	require.Equal(t, dev.Id, entries[0].Id)
+	require.Equal(t, prod.Id, entries[1].Id)
+	require.Equal(t, []byte("encrypted1"), entries[1].ActivationKey)
+
+	entries, count, err = d.GetSubscriptionProfiles(ORGID1, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, entries, 1)
+	require.Equal(t, prod.Id, entries[0].Id)
+
+	_, err = d.GetSubscriptionProfile(prod.Id, ORGID2)
+	require.ErrorIs(t, err, db.SubscriptionProfileNotFoundError)
+
+	// the activation key is kept when the update doesn't carry one
+	update := prod
+	update.Name = "prod"
+	update.Rhc = true
+	update.ActivationKey = nil
+	require.NoError(t, d.UpdateSubscriptionProfile(ORGID1, update))
+	entry, err := d.GetSubscriptionProfile(prod.Id, ORGID1)
+	require.NoError(t, err)
+	require.Equal(t, "prod", entry.Name)
+	require.True(t, entry.Rhc)
+	require.Equal(t, []byte("encrypted1"), entry.ActivationKey)
+	require.False(t, entry.UpdatedAt.Before(entry.CreatedAt))
+
+	update.ActivationKey = []byte("encrypted3")
+	require.NoError(t, d.UpdateSubscriptionProfile(ORGID1, update))
+	entry, err = d.GetSubscriptionProfile(prod.Id, ORGID1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("encrypted3"), entry.ActivationKey)
+
+	update.Name = dev.Name
+	require.ErrorIs(t, d.UpdateSubscriptionProfile(ORGID1, update), db.SubscriptionProfileExistsError)
+	require.ErrorIs(t, d.UpdateSubscriptionProfile(ORGID2, prod), db.SubscriptionProfileNotFoundError)
+
+	require.ErrorIs(t, d.DeleteSubscriptionProfile(prod.Id, ORGID2), db.SubscriptionProfileNotFoundError)
+	require.NoError(t, d.DeleteSubscriptionProfile(prod.Id, ORGID1))
+	require.ErrorIs(t, d.DeleteSubscriptionProfile(prod.Id, ORGID1), db.SubscriptionProfileNotFoundError)
+	_, count, err = d.GetSubscriptionProfiles(ORGID1, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func testComposesFilter(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+	conn := connect(t)
+	defer conn.Close(context.Background())
+
+	request := func(distro, imageType, arch, uploadType string) string {
+		return fmt.Sprintf(`{"distribution": %q, "image_requests": [{"architecture": %q, "image_type": %q, "upload_request": {"type": %q, "options": {}}}]}`,
+			distro, arch, imageType, uploadType)
+	}
+
+	day := 24 * time.Hour
+	job1 := uuid.New()
+	job2 := uuid.New()
+	job3 := uuid.New()
+	insert := "INSERT INTO composes(job_id, request, created_at, account_number, org_id, image_name) VALUES ($1, $2, CURRENT_TIMESTAMP - $3, $4, $5, $6)"
+	_, err = conn.Exec(context.Background(), insert, job1, request("rhel-8", "aws", "x86_64", "aws"), 3*day, ANR1, ORGID1, "alpha")
+	require.NoError(t, err)
+	_, err = conn.Exec(context.Background(), insert, job2, request("rhel-9", "ami", "aarch64", "aws"), 2*day, ANR1, ORGID1, "beta_100%")
+	require.NoError(t, err)
+	_, err = conn.Exec(context.Background(), insert, job3, request("rhel-9", "guest-image", "x86_64", "aws.s3"), day, ANR1, ORGID1, "gamma")
+	require.NoError(t, err)
+
+	ids := func(composes []db.ComposeEntry) []uuid.UUID {
+		res := []uuid.UUID{}
+		for _, c := range composes {
+			res = append(res, c.Id)
+		}
+		return res
+	}
+
+	// defaults to most recent first
+	composes, count, err := d.GetComposes(ORGID1, db.ComposesFilter{MaxAge: fortnight}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, []uuid.UUID{job3, job2, job1}, ids(composes))
+
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{Sort: db.SortCreatedAtAsc}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, []uuid.UUID{job1, job2, job3}, ids(composes))
+
+	composes, _, err = d.GetComposes(ORGID1, db.ComposesFilter{Sort: db.SortImageNameDesc}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{job3, job2, job1}, ids(composes))
+
+	since := time.Now().Add(-time.Hour * 60)
+	until := time.Now().Add(-time.Hour * 36)
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{Since: &since, Until: &until}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, []uuid.UUID{job2}, ids(composes))
+
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{Distribution: common.StringToPtr("rhel-9")}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Equal(t, []uuid.UUID{job3, job2}, ids(composes))
+
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{ImageTypes: []string{"aws", "ami"}}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Equal(t, []uuid.UUID{job2, job1}, ids(composes))
+
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{
+		Architecture: common.StringToPtr("x86_64"),
+		UploadType:   common.StringToPtr("aws.s3"),
+	}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, []uuid.UUID{job3}, ids(composes))
+
+	// substring match, wildcards in the search are taken literally
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{ImageName: common.StringToPtr("A_1")}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, []uuid.UUID{job2}, ids(composes))
+	_, count, err = d.GetComposes(ORGID1, db.ComposesFilter{ImageName: common.StringToPtr("a%a")}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	// the status of new composes is unknown until polled
+	_, count, err = d.GetComposes(ORGID1, db.ComposesFilter{Status: common.StringToPtr(db.ComposeStatusUnknown)}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	_, count, err = d.GetComposes(ORGID1, db.ComposesFilter{Status: common.StringToPtr("pending")}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.NoError(t, d.UpdateComposeStatus(job1, "success"))
+	composes, count, err = d.GetComposes(ORGID1, db.ComposesFilter{Status: common.StringToPtr("success")}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, []uuid.UUID{job1}, ids(composes))
+	_, count, err = d.GetComposes(ORGID1, db.ComposesFilter{Status: common.StringToPtr(db.ComposeStatusUnknown)}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	_, count, err = d.GetComposes(ORGID2, db.ComposesFilter{}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
+
+func testComposesKeyset(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+
+	// a and b share a name, c has none, so ties and NULLs are crossed
+	nameA := "a"
+	a := uuid.New()
+	b := uuid.New()
+	c := uuid.New()
+	z := uuid.New()
+	require.NoError(t, d.InsertCompose(a, ANR1, ORGID1, &nameA, []byte("{}"), "default"))
+	require.NoError(t, d.InsertCompose(b, ANR1, ORGID1, &nameA, []byte("{}"), "default"))
+	require.NoError(t, d.InsertCompose(c, ANR1, ORGID1, nil, []byte("{}"), "default"))
+	nameZ := "z"
+	require.NoError(t, d.InsertCompose(z, ANR1, ORGID1, &nameZ, []byte("{}"), "default"))
+
+	for _, sort := range []db.ComposesSort{db.SortCreatedAtDesc, db.SortCreatedAtAsc, db.SortImageNameAsc, db.SortImageNameDesc} {
+		filter := db.ComposesFilter{Sort: sort}
+		all, count, err := d.GetComposes(ORGID1, filter, nil, 100, 0)
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+		require.Len(t, all, 4)
+
+		// walking forwards and backwards one at a time visits all composes in order
+		for i := 0; i < len(all)-1; i++ {
+			ks := db.Keyset{CreatedAt: all[i].CreatedAt, ImageName: all[i].ImageName, Id: all[i].Id}
+			page, count, err := d.GetComposes(ORGID1, filter, &ks, 1, 100)
+			require.NoError(t, err)
+			require.Equal(t, 4, count)
+			require.Equal(t, []db.ComposeEntry{all[i+1]}, page, "sort %s, after %d", sort, i)
+
+			ks = db.Keyset{CreatedAt: all[i+1].CreatedAt, ImageName: all[i+1].ImageName, Id: all[i+1].Id, Before: true}
+			page, _, err = d.GetComposes(ORGID1, filter, &ks, 100, 0)
+			require.NoError(t, err)
+			require.Equal(t, all[:i+1], page, "sort %s, before %d", sort, i+1)
+		}
+	}
+
+	all, _, err := d.GetComposes(ORGID1, db.ComposesFilter{Sort: db.SortImageNameAsc}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Nil(t, all[3].ImageName)
+	require.Equal(t, z, all[2].Id)
+}
+
+func testComposesLabels(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+
+	prod := uuid.New()
+	staging := uuid.New()
+	unlabelled := uuid.New()
+	require.NoError(t, d.InsertCompose(prod, ANR1, ORGID1, nil, []byte(`{"labels": {"team": "edge", "environment": "production", "git_sha": "abc"}}`), "default"))
+	require.NoError(t, d.InsertCompose(staging, ANR1, ORGID1, nil, []byte(`{"labels": {"team": "edge", "environment": "staging"}}`), "default"))
+	require.NoError(t, d.InsertCompose(unlabelled, ANR1, ORGID1, nil, []byte(`{}`), "default"))
+
+	ids := func(reqs ...db.LabelRequirement) []uuid.UUID {
+		composes, count, err := d.GetComposes(ORGID1, db.ComposesFilter{Labels: reqs}, nil, 100, 0)
+		require.NoError(t, err)
+		require.Equal(t, len(composes), count)
+		res := []uuid.UUID{}
+		for _, c := range composes {
+			res = append(res, c.Id)
+		}
+		return res
+	}
+
+	require.Equal(t, []uuid.UUID{staging, prod}, ids(db.LabelRequirement{Key: "team", Value: common.StringToPtr("edge")}))
+	require.Equal(t, []uuid.UUID{prod}, ids(
+		db.LabelRequirement{Key: "team", Value: common.StringToPtr("edge")},
+		db.LabelRequirement{Key: "git_sha"},
+	))
+	// composes without the label don't have it set to the value either
+	require.Equal(t, []uuid.UUID{unlabelled, staging}, ids(db.LabelRequirement{Key: "environment", Value: common.StringToPtr("production"), Negate: true}))
+	require.Equal(t, []uuid.UUID{unlabelled}, ids(db.LabelRequirement{Key: "team", Negate: true}))
+	require.Equal(t, []uuid.UUID{}, ids(db.LabelRequirement{Key: "team", Value: common.StringToPtr("core")}))
+}
+
+func testComposeBackend(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+	conn := connect(t)
+	defer conn.Close(context.Background())
+
+	composeId := uuid.New()
+	cloneId := uuid.New()
+	require.NoError(t, d.InsertCompose(composeId, ANR1, ORGID1, nil, []byte("{}"), "aarch64"))
+	require.NoError(t, d.InsertClone(composeId, cloneId, []byte("{}"), nil))
+
+	compose, err := d.GetCompose(composeId, ORGID1)
+	require.NoError(t, err)
+	require.Equal(t, "aarch64", compose.Backend)
+
+	composes, _, err := d.GetComposes(ORGID1, db.ComposesFilter{}, nil, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, composes, 1)
+	require.Equal(t, "aarch64", composes[0].Backend)
+
+	clone, err := d.GetClone(cloneId, ORGID1)
+	require.NoError(t, err)
+	require.Equal(t, "aarch64", clone.Backend)
+
+	clones, _, err := d.GetClonesForCompose(composeId, ORGID1, nil, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, clones, 1)
+	require.Equal(t, "aarch64", clones[0].Backend)
+
+	// composes from before the column existed belong to the default backend
+	legacyId := uuid.New()
+	_, err = conn.Exec(context.Background(),
+		"INSERT INTO composes(job_id, request, created_at, account_number, org_id) VALUES ($1, '{}', CURRENT_TIMESTAMP, $2, $3)",
+		legacyId, ANR1, ORGID1)
+	require.NoError(t, err)
+	compose, err = d.GetCompose(legacyId, ORGID1)
+	require.NoError(t, err)
+	require.Equal(t, "default", compose.Backend)
+}
+
+func testCloneBatch(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+	conn := connect(t)
+	defer conn.Close(context.Background())
+
+	composeId := uuid.New()
+	batchId := uuid.New()
+	cloneIds := []uuid.UUID{uuid.New(), uuid.New()}
+	require.NoError(t, d.InsertCompose(composeId, ANR1, ORGID1, nil, []byte("{}"), "aarch64"))
+	require.NoError(t, d.InsertClone(composeId, cloneIds[0], []byte(`{"region": "us-east-1"}`), &batchId))
+	require.NoError(t, d.InsertClone(composeId, cloneIds[1], []byte(`{"region": "us-east-2"}`), &batchId))
+	require.NoError(t, d.InsertClone(composeId, uuid.New(), []byte(`{"region": "us-west-1"}`), nil))
+
+	clones, err := d.GetCloneBatch(batchId, ORGID1)
+	require.NoError(t, err)
+	require.Len(t, clones, 2)
+	require.ElementsMatch(t, cloneIds, []uuid.UUID{clones[0].Id, clones[1].Id})
+	require.Equal(t, "aarch64", clones[0].Backend)
+
+	// batched clones are listed along with the others
+	_, count, err := d.GetClonesForCompose(composeId, ORGID1, nil, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	_, err = d.GetCloneBatch(batchId, ORGID2)
+	require.ErrorIs(t, err, db.CloneBatchNotFoundError)
+	_, err = d.GetCloneBatch(uuid.New(), ORGID1)
+	require.ErrorIs(t, err, db.CloneBatchNotFoundError)
+}
+
+func testOSTreeCommits(t *testing.T) {
+	d, err := db.InitDBConnectionPool(connStr(t))
+	require.NoError(t, err)
+	conn := connect(t)
+	defer conn.Close(context.Background())
+
+	url := "https://ostree.example.com/repo"
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, id := range ids {
+		require.NoError(t, d.InsertCompose(id, ANR1, ORGID1, nil, []byte("{}"), "default"))
+		var parent *string
+		var parentComposeId *uuid.UUID
+		if i > 0 {
+			parent = common.StringToPtr(fmt.Sprintf("commit%d", i-1))
+			parentComposeId = &ids[i-1]
+		}
+		require.NoError(t, d.InsertOSTreeCommit(id, ORGID1, "rhel/8/x86_64/edge", &url, parent, parentComposeId))
+		_, err = conn.Exec(context.Background(), "UPDATE ostree_commits SET created_at = $2 WHERE compose_id = $1", id, time.Now().Add(time.Duration(i-10)*time.Minute))
+		require.NoError(t, err)
+	}
+	other := uuid.New()
+	require.NoError(t, d.InsertCompose(other, ANR1, ORGID1, nil, []byte("{}"), "default"))
+	require.NoError(t, d.InsertOSTreeCommit(other, ORGID1, "rhel/9/x86_64/edge", nil, nil, nil))
+
+	require.NoError(t, d.UpdateOSTreeCommit(ids[0], "commit0"))
+	require.ErrorIs(t, d.UpdateOSTreeCommit(uuid.New(), "commit"), db.OSTreeCommitNotFoundError)
+
+	commits, count, err := d.GetOSTreeCommits(ORGID1, "rhel/8/x86_64/edge", 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, []uuid.UUID{ids[2], ids[1], ids[0]}, []uuid.UUID{commits[0].ComposeId, commits[1].ComposeId, commits[2].ComposeId})
+	require.Equal(t, "commit0", *commits[2].Commit)
+	require.Nil(t, commits[1].Commit)
+	require.Equal(t, "commit0", *commits[1].Parent)
+	require.Equal(t, ids[0], *commits[1].ParentComposeId)
+	require.Equal(t, url, *commits[1].Url)
+	require.Equal(t, "pending", *commits[0].Status)
+	require.Equal(t, "default", commits[0].Backend)
+
+	commits, _, err = d.GetOSTreeCommits(ORGID1, "rhel/8/x86_64/edge", 1, 1)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, ids[1], commits[0].ComposeId)
+
+	// the commits of deleted composes drop out of the lineage
+	require.NoError(t, d.DeleteCompose(ids[2], ORGID1))
+	_, count, err = d.GetOSTreeCommits(ORGID1, "rhel/8/x86_64/edge", 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	_, count, err = d.GetOSTreeCommits(ORGID2, "rhel/8/x86_64/edge", 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	refs, count, err := d.GetOSTreeRefs(ORGID1, 100, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Equal(t, "rhel/8/x86_64/edge", refs[0].Ref)
+	require.Equal(t, 2, refs[0].Commits)
+	require.Equal(t, "rhel/9/x86_64/edge", refs[1].Ref)
+	require.Equal(t, 1, refs[1].Commits)
 }
 
 func TestMain(t *testing.T) {
@@ -345,9 +862,19 @@ func TestMain(t *testing.T) {
 		testInsertCompose,
 		testGetCompose,
 		testCountComposesSince,
+		testComposesFilter,
+		testComposesKeyset,
+		testComposesLabels,
+		testComposeBackend,
 		testGetComposeImageType,
 		testDeleteCompose,
+		testRestoreCompose,
+		testPurgeComposes,
 		testClones,
+		testCloneBatch,
+		testAudit,
+		testOSTreeCommits,
+		testSubscriptionProfiles,
 	}
 
 	for _, f := range fns {
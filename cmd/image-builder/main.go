@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/gommon/random"
 	"github.com/osbuild/image-builder/internal/common"
@@ -13,7 +16,12 @@ import (
 	"github.com/osbuild/image-builder/internal/db"
 	"github.com/osbuild/image-builder/internal/distribution"
 	"github.com/osbuild/image-builder/internal/logger"
+	"github.com/osbuild/image-builder/internal/oauth"
+	"github.com/osbuild/image-builder/internal/ostree"
 	"github.com/osbuild/image-builder/internal/provisioning"
+	"github.com/osbuild/image-builder/internal/retention"
+	"github.com/osbuild/image-builder/internal/secrets"
+	"github.com/osbuild/image-builder/internal/tlsconfig"
 	v1 "github.com/osbuild/image-builder/internal/v1"
 
 	"github.com/labstack/echo/v4"
@@ -30,6 +38,10 @@ func main() {
 		PGUser:        "postgres",
 		PGPassword:    "foobar",
 		PGSSLMode:     "prefer",
+
+		RestoreGracePeriod: "168h",
+		DeletedRetention:   "720h",
+		RetentionInterval:  "1h",
 	}
 
 	err := config.LoadConfigFromEnv(&conf)
@@ -62,20 +74,81 @@ func main() {
 		panic(err)
 	}
 
+	tlsMinVersion, err := tlsconfig.ParseVersion(conf.TLSMinVersion)
+	if err != nil {
+		panic(err)
+	}
+	var certReloadInterval time.Duration
+	if conf.CertReloadInterval != "" {
+		certReloadInterval, err = time.ParseDuration(conf.CertReloadInterval)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	composerConf := composer.ComposerClientConfig{
-		ComposerURL:  conf.ComposerURL,
-		CA:           conf.ComposerCA,
-		TokenURL:     conf.ComposerTokenURL,
-		ClientId:     conf.ComposerClientId,
-		OfflineToken: conf.ComposerOfflineToken,
-		ClientSecret: conf.ComposerClientSecret,
+		ComposerURL: conf.ComposerURL,
+		TLS: tlsconfig.Config{
+			CA:             conf.ComposerCA,
+			Cert:           conf.ComposerClientCert,
+			Key:            conf.ComposerClientKey,
+			ReloadInterval: certReloadInterval,
+			MinVersion:     tlsMinVersion,
+		},
+	}
+	// composer authenticates image-builder by its client certificate when
+	// there's no token endpoint
+	if conf.ComposerTokenURL != "" || conf.ComposerClientCert == "" {
+		composerTokens, err := oauth.NewTokenSource(oauth.TokenSourceConfig{
+			TokenURL:     conf.ComposerTokenURL,
+			ClientId:     conf.ComposerClientId,
+			OfflineToken: conf.ComposerOfflineToken,
+			ClientSecret: conf.ComposerClientSecret,
+			Name:         "composer",
+		})
+		if err != nil {
+			panic(err)
+		}
+		go composerTokens.Run(context.Background())
+		composerConf.TokenSource = composerTokens
+	}
+	if conf.ComposerTimeout != "" {
+		composerConf.Timeout, err = time.ParseDuration(conf.ComposerTimeout)
+		if err != nil {
+			panic(err)
+		}
 	}
 	compClient, err := composer.NewClient(composerConf)
 	if err != nil {
 		panic(err)
 	}
+
+	// the other backends share the token source and TLS settings of the
+	// default one, unless they configure their own certificates
+	backendsFile, err := composer.LoadBackendsFile(conf.ComposerBackendsFile)
+	if err != nil {
+		panic(err)
+	}
+	compClients := map[string]*composer.ComposerClient{composer.DefaultBackend: compClient}
+	for name, backend := range backendsFile.Backends {
+		compClients[name], err = composer.NewClient(backend.ClientConfig(composerConf))
+		if err != nil {
+			panic(err)
+		}
+	}
+	composers, err := composer.NewBackends(compClients, backendsFile.Routes)
+	if err != nil {
+		panic(err)
+	}
 	provClient, err := provisioning.NewClient(provisioning.ProvisioningClientConfig{
 		URL: conf.ProvisioningURL,
+		TLS: tlsconfig.Config{
+			CA:             conf.ProvisioningCA,
+			Cert:           conf.ProvisioningCert,
+			Key:            conf.ProvisioningKey,
+			ReloadInterval: certReloadInterval,
+			MinVersion:     tlsMinVersion,
+		},
 	})
 	if err != nil {
 		panic(err)
@@ -90,6 +163,41 @@ func main() {
 		panic("no distributions defined")
 	}
 
+	restoreGracePeriod, err := time.ParseDuration(conf.RestoreGracePeriod)
+	if err != nil {
+		panic(err)
+	}
+	retentionConf := retention.Config{}
+	retentionConf.DeletedRetention, err = time.ParseDuration(conf.DeletedRetention)
+	if err != nil {
+		panic(err)
+	}
+	retentionConf.Interval, err = time.ParseDuration(conf.RetentionInterval)
+	if err != nil {
+		panic(err)
+	}
+	if conf.ArtifactLifetime != "" {
+		retentionConf.ArtifactLifetime, err = time.ParseDuration(conf.ArtifactLifetime)
+		if err != nil {
+			panic(err)
+		}
+	}
+	if retentionConf.DeletedRetention < restoreGracePeriod {
+		panic("deleted compose retention is shorter than the restore grace period")
+	}
+	// purged composes no longer count towards the quota
+	quotaWindow, err := common.LongestSlidingWindow(conf.QuotaFile)
+	if err != nil {
+		panic(err)
+	}
+	if retentionConf.DeletedRetention < quotaWindow {
+		panic("deleted compose retention is shorter than the quota sliding window")
+	}
+	if retentionConf.ArtifactLifetime != 0 && retentionConf.ArtifactLifetime < quotaWindow {
+		panic("artifact lifetime is shorter than the quota sliding window")
+	}
+	go retention.Run(context.Background(), dbase, retentionConf)
+
 	echoServer := echo.New()
 	echoServer.HideBanner = true
 	echoServer.Logger = common.Logger()
@@ -122,21 +230,46 @@ func main() {
 	if conf.IsDebug() {
 		echoServer.Debug = true
 	}
+	var ostreeRemote *ostree.Remote
+	if conf.OSTreeVerifyRemote != "" {
+		verify, err := strconv.ParseBool(conf.OSTreeVerifyRemote)
+		if err != nil {
+			panic(err)
+		}
+		if verify {
+			ostreeRemote = ostree.NewRemote(nil)
+		}
+	}
+
+	var secretsCipher *secrets.Cipher
+	if conf.SecretsKey != "" {
+		secretsCipher, err = secrets.NewCipherFromBase64(conf.SecretsKey)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	serverConfig := &v1.ServerConfig{
 		EchoServer: echoServer,
-		CompClient: compClient,
+		Composers:  composers,
 		ProvClient: provClient,
 		DBase:      dbase,
 		AwsConfig: v1.AWSConfig{
-			Region: conf.OsbuildRegion,
+			Region:         conf.OsbuildRegion,
+			AllowedRegions: config.ParseList(conf.OsbuildAWSRegions),
 		},
 		GcpConfig: v1.GCPConfig{
-			Region: conf.OsbuildGCPRegion,
-			Bucket: conf.OsbuildGCPBucket,
+			Region:         conf.OsbuildGCPRegion,
+			Bucket:         conf.OsbuildGCPBucket,
+			AllowedRegions: config.ParseList(conf.OsbuildGCPRegions),
 		},
 		QuotaFile:  conf.QuotaFile,
 		AllowFile:  conf.AllowFile,
 		AllDistros: adr,
+
+		RestoreGracePeriod: restoreGracePeriod,
+		OSTreeRemote:       ostreeRemote,
+		Secrets:            secretsCipher,
 	}
 
 	err = v1.Attach(serverConfig)
@@ -7,3 +7,7 @@ func StringToPtr(s string) *string {
 func BoolToPtr(b bool) *bool {
 	return &b
 }
+
+func IntToPtr(i int) *int {
+	return &i
+}
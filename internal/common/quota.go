@@ -53,28 +53,18 @@ func CheckQuota(orgID string, dB db.DB, quotaFile string) (bool, error) {
 	var slidingWindow time.Duration
 
 	// read proper values from quotas' file
-	var quotas map[string]Quota
-	jsonFile, err := os.Open(filepath.Clean(quotaFile))
-	if _, ok := err.(*os.PathError); ok {
-		return false, fmt.Errorf("No config file for quotas found at %s\n", quotaFile)
+	quotas, err := loadQuotas(quotaFile)
+	if err != nil {
+		return false, err
+	}
+	if quota, ok := quotas[orgID]; ok {
+		authorizedRequests = quota.Quota
+		slidingWindow = quota.SlidingWindow
+	} else if quota, ok := quotas["default"]; ok {
+		authorizedRequests = quota.Quota
+		slidingWindow = quota.SlidingWindow
 	} else {
-		rawJsonFile, err := io.ReadAll(jsonFile)
-		if err != nil {
-			return false, fmt.Errorf("Failed to read quota file %q: %s", quotaFile, err.Error())
-		}
-		err = json.Unmarshal(rawJsonFile, &quotas)
-		if err != nil {
-			return false, fmt.Errorf("Failed to unmarshal quota file %q: %s", quotaFile, err.Error())
-		}
-		if quota, ok := quotas[orgID]; ok {
-			authorizedRequests = quota.Quota
-			slidingWindow = quota.SlidingWindow
-		} else if quota, ok := quotas["default"]; ok {
-			authorizedRequests = quota.Quota
-			slidingWindow = quota.SlidingWindow
-		} else {
-			return false, fmt.Errorf("No default values in the quotas' file %s\n", quotaFile)
-		}
+		return false, fmt.Errorf("No default values in the quotas' file %s\n", quotaFile)
 	}
 
 	// read user created requests
@@ -84,3 +74,41 @@ func CheckQuota(orgID string, dB db.DB, quotaFile string) (bool, error) {
 	}
 	return count < authorizedRequests, nil
 }
+
+// LongestSlidingWindow returns the longest sliding window of the quotas'
+// file, composes have to be kept at least that long to be counted. It's zero
+// when the check is disabled.
+func LongestSlidingWindow(quotaFile string) (time.Duration, error) {
+	if quotaFile == "" {
+		return 0, nil
+	}
+	quotas, err := loadQuotas(quotaFile)
+	if err != nil {
+		return 0, err
+	}
+	var longest time.Duration
+	for _, quota := range quotas {
+		if quota.SlidingWindow > longest {
+			longest = quota.SlidingWindow
+		}
+	}
+	return longest, nil
+}
+
+func loadQuotas(quotaFile string) (map[string]Quota, error) {
+	var quotas map[string]Quota
+	jsonFile, err := os.Open(filepath.Clean(quotaFile))
+	if _, ok := err.(*os.PathError); ok {
+		return nil, fmt.Errorf("No config file for quotas found at %s\n", quotaFile)
+	}
+	defer jsonFile.Close()
+	rawJsonFile, err := io.ReadAll(jsonFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read quota file %q: %s", quotaFile, err.Error())
+	}
+	err = json.Unmarshal(rawJsonFile, &quotas)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal quota file %q: %s", quotaFile, err.Error())
+	}
+	return quotas, nil
+}
@@ -0,0 +1,29 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongestSlidingWindow(t *testing.T) {
+	window, err := LongestSlidingWindow("")
+	require.NoError(t, err)
+	require.Zero(t, window)
+
+	quotaFile := filepath.Join(t.TempDir(), "quotas.json")
+	err = os.WriteFile(quotaFile, []byte(`{
+		"000000": {"quota": 2, "slidingWindow": 2592000000000000},
+		"default": {"quota": 100, "slidingWindow": 1209600000000000}
+	}`), 0600)
+	require.NoError(t, err)
+
+	window, err = LongestSlidingWindow(quotaFile)
+	require.NoError(t, err)
+	require.Equal(t, 30*day, window)
+
+	_, err = LongestSlidingWindow(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
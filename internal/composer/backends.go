@@ -0,0 +1,161 @@
+package composer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultBackend is the name of the backend composes are sent to when no
+// route matches, and of the one configured through COMPOSER_URL.
+const DefaultBackend = "default"
+
+// Route sends the composes matching all of its set fields to Backend.
+// Distribution is a glob pattern, e.g. rhel-9*.
+type Route struct {
+	OrgId        string `json:"org_id,omitempty"`
+	Distribution string `json:"distribution,omitempty"`
+	Architecture string `json:"architecture,omitempty"`
+	Backend      string `json:"backend"`
+}
+
+// Backends are the named composer instances composes can be built by.
+type Backends struct {
+	clients map[string]*ComposerClient
+	routes  []Route
+}
+
+// BackendConfig is a backend as configured in the backends file.
+type BackendConfig struct {
+	URL  string `json:"url"`
+	CA   string `json:"ca,omitempty"`
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+}
+
+// ClientConfig returns the client configuration of the backend, the settings
+// it doesn't set are inherited from the default backend. The certificate and
+// key are a pair, setting either replaces both.
+func (b BackendConfig) ClientConfig(defaults ComposerClientConfig) ComposerClientConfig {
+	conf := defaults
+	conf.ComposerURL = b.URL
+	if b.CA != "" {
+		conf.TLS.CA = b.CA
+	}
+	if b.Cert != "" || b.Key != "" {
+		conf.TLS.Cert = b.Cert
+		conf.TLS.Key = b.Key
+	}
+	return conf
+}
+
+// BackendsFile configures the backends in addition to the default one, and
+// the routes to them. Routes are tried in order.
+//
+//	{
+//		"backends": {
+//			"aarch64": {"url": "https://composer-aarch64.example.com", "ca": "/etc/ca.crt"}
+//		},
+//		"routes": [
+//			{"architecture": "aarch64", "backend": "aarch64"}
+//		]
+//	}
+type BackendsFile struct {
+	Backends map[string]BackendConfig `json:"backends"`
+	Routes   []Route                  `json:"routes"`
+}
+
+// LoadBackendsFile reads the backends file, an empty path results in no
+// additional backends.
+func LoadBackendsFile(backendsFile string) (*BackendsFile, error) {
+	if backendsFile == "" {
+		return &BackendsFile{}, nil
+	}
+
+	jsonFile, err := os.Open(filepath.Clean(backendsFile))
+	if err != nil {
+		return nil, fmt.Errorf("No backends file found at %s: %v", backendsFile, err)
+	}
+	defer func() {
+		if err := jsonFile.Close(); err != nil {
+			logrus.Errorf("Error closing file: %v", err)
+		}
+	}()
+
+	rawJsonFile, err := io.ReadAll(jsonFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read backends file %q: %v", backendsFile, err)
+	}
+
+	var bf BackendsFile
+	err = json.Unmarshal(rawJsonFile, &bf)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal backends file %q: %v", backendsFile, err)
+	}
+	if _, ok := bf.Backends[DefaultBackend]; ok {
+		return nil, fmt.Errorf("Backends file %q can't configure the %s backend", backendsFile, DefaultBackend)
+	}
+	return &bf, nil
+}
+
+func NewBackends(clients map[string]*ComposerClient, routes []Route) (*Backends, error) {
+	if clients[DefaultBackend] == nil {
+		return nil, fmt.Errorf("No %s composer backend", DefaultBackend)
+	}
+	for _, r := range routes {
+		if clients[r.Backend] == nil {
+			return nil, fmt.Errorf("Route to unknown composer backend %q", r.Backend)
+		}
+		if _, err := path.Match(r.Distribution, ""); err != nil {
+			return nil, fmt.Errorf("Invalid distribution pattern %q: %v", r.Distribution, err)
+		}
+	}
+	return &Backends{
+		clients: clients,
+		routes:  routes,
+	}, nil
+}
+
+// Route returns the name of the backend a compose should be built by, the
+// one of the first matching route or the default backend.
+func (b *Backends) Route(orgId, distribution, architecture string) string {
+	for _, r := range b.routes {
+		if r.OrgId != "" && r.OrgId != orgId {
+			continue
+		}
+		if r.Architecture != "" && r.Architecture != architecture {
+			continue
+		}
+		if r.Distribution != "" {
+			// the patterns are checked in NewBackends
+			if match, _ := path.Match(r.Distribution, distribution); !match {
+				continue
+			}
+		}
+		return r.Backend
+	}
+	return DefaultBackend
+}
+
+// Client returns the client of the backend, composes stored before there were
+// multiple backends belong to the default one.
+func (b *Backends) Client(name string) (*ComposerClient, error) {
+	if name == "" {
+		name = DefaultBackend
+	}
+	client, ok := b.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown composer backend %q", name)
+	}
+	return client, nil
+}
+
+// Default returns the client of the default backend.
+func (b *Backends) Default() *ComposerClient {
+	return b.clients[DefaultBackend]
+}
@@ -0,0 +1,105 @@
+package composer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/image-builder/internal/tlsconfig"
+)
+
+func TestRoute(t *testing.T) {
+	clients := map[string]*ComposerClient{
+		DefaultBackend: {},
+		"aarch64":      {},
+		"partner":      {},
+	}
+	b, err := NewBackends(clients, []Route{
+		{OrgId: "000001", Backend: "partner"},
+		{Architecture: "aarch64", Distribution: "rhel-9*", Backend: "aarch64"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "partner", b.Route("000001", "rhel-90", "aarch64"))
+	require.Equal(t, "aarch64", b.Route("000000", "rhel-90", "aarch64"))
+	require.Equal(t, DefaultBackend, b.Route("000000", "rhel-87", "aarch64"))
+	require.Equal(t, DefaultBackend, b.Route("000000", "rhel-90", "x86_64"))
+
+	client, err := b.Client("aarch64")
+	require.NoError(t, err)
+	require.Same(t, clients["aarch64"], client)
+	// composes from before the backends were stored
+	client, err = b.Client("")
+	require.NoError(t, err)
+	require.Same(t, clients[DefaultBackend], client)
+	_, err = b.Client("gone")
+	require.Error(t, err)
+}
+
+func TestNewBackendsValidation(t *testing.T) {
+	_, err := NewBackends(map[string]*ComposerClient{"aarch64": {}}, nil)
+	require.Error(t, err)
+
+	clients := map[string]*ComposerClient{DefaultBackend: {}}
+	_, err = NewBackends(clients, []Route{{Architecture: "aarch64", Backend: "aarch64"}})
+	require.Error(t, err)
+	_, err = NewBackends(clients, []Route{{Distribution: "rhel-[", Backend: DefaultBackend}})
+	require.Error(t, err)
+}
+
+func TestLoadBackendsFile(t *testing.T) {
+	bf, err := LoadBackendsFile("")
+	require.NoError(t, err)
+	require.Empty(t, bf.Backends)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backends.json")
+	err = os.WriteFile(path, []byte(`{
+		"backends": {"aarch64": {"url": "https://composer-aarch64.example.com"}},
+		"routes": [{"architecture": "aarch64", "backend": "aarch64"}]
+	}`), 0600)
+	require.NoError(t, err)
+	bf, err = LoadBackendsFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "https://composer-aarch64.example.com", bf.Backends["aarch64"].URL)
+	require.Equal(t, []Route{{Architecture: "aarch64", Backend: "aarch64"}}, bf.Routes)
+
+	err = os.WriteFile(path, []byte(`{"backends": {"default": {"url": "https://composer.example.com"}}}`), 0600)
+	require.NoError(t, err)
+	_, err = LoadBackendsFile(path)
+	require.Error(t, err)
+}
+
+func TestBackendClientConfig(t *testing.T) {
+	defaults := ComposerClientConfig{
+		ComposerURL: "https://composer.example.com",
+		TLS: tlsconfig.Config{
+			CA:   "/etc/ca.crt",
+			Cert: "/etc/client.crt",
+			Key:  "/etc/client.key",
+		},
+		Timeout: time.Minute,
+	}
+
+	// inherits everything but the url
+	conf := BackendConfig{URL: "https://composer-aarch64.example.com"}.ClientConfig(defaults)
+	expected := defaults
+	expected.ComposerURL = "https://composer-aarch64.example.com"
+	require.Equal(t, expected, conf)
+
+	conf = BackendConfig{URL: "https://composer-aarch64.example.com", CA: "/etc/aarch64-ca.crt"}.ClientConfig(defaults)
+	require.Equal(t, "/etc/aarch64-ca.crt", conf.TLS.CA)
+	require.Equal(t, "/etc/client.crt", conf.TLS.Cert)
+	require.Equal(t, "/etc/client.key", conf.TLS.Key)
+
+	conf = BackendConfig{URL: "https://composer-aarch64.example.com", Cert: "/etc/aarch64.crt", Key: "/etc/aarch64.key"}.ClientConfig(defaults)
+	require.Equal(t, "/etc/ca.crt", conf.TLS.CA)
+	require.Equal(t, "/etc/aarch64.crt", conf.TLS.Cert)
+	require.Equal(t, "/etc/aarch64.key", conf.TLS.Key)
+
+	// the default backend is left alone
+	require.Equal(t, "https://composer.example.com", defaults.ComposerURL)
+}
@@ -0,0 +1,70 @@
+package composer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// breaker stops calls to composer for a while after consecutive failures, so
+// an outage fails fast instead of piling up requests waiting on their
+// deadlines. Once the cooldown is over a single call is let through to probe
+// whether composer is back.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures >= b.threshold {
+		logrus.Info("Composer is available again, closing the circuit breaker")
+	}
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		if b.failures == b.threshold {
+			logrus.Warnf("%d consecutive failed calls to composer, opening the circuit breaker", b.failures)
+		}
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// abort releases a probe which ended without telling whether composer is
+// available.
+func (b *breaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
@@ -3,104 +3,230 @@
 package composer
 
 import (
-	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/sirupsen/logrus"
+
+	"github.com/osbuild/image-builder/internal/oauth"
+	"github.com/osbuild/image-builder/internal/tlsconfig"
 )
 
 type ComposerClient struct {
 	composerURL string
+	tokens      *oauth.TokenSource
 
-	tokenURL     string
-	offlineToken string
-	accessToken  string
-	clientId     string
-	clientSecret string
-	tokenMu      sync.RWMutex
-
-	client *http.Client
+	// deadline of a call, including its retries
+	timeout time.Duration
+	client  *retryablehttp.Client
+	breaker *breaker
 }
 
 type ComposerClientConfig struct {
 	ComposerURL  string
-	CA           string
 	TokenURL     string
 	ClientId     string
 	OfflineToken string
 	ClientSecret string
-}
 
-type tokenResponse struct {
-	AccessToken string `json:"access_token"`
+	// Used instead of a token source made from the fields above if set, so
+	// it can be shared and refreshed in the background
+	TokenSource *oauth.TokenSource
+
+	// CA and client certificate, composer can authenticate the client by
+	// its certificate instead of a token
+	TLS tlsconfig.Config
+
+	// Deadline of a call to composer, including retries. Defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
 }
 
+const (
+	DefaultTimeout = time.Minute
+
+	// Idempotent requests are retried on connection errors and 5xx
+	// responses, with a jittered backoff.
+	retryMax     = 3
+	retryWaitMin = 500 * time.Millisecond
+	retryWaitMax = 5 * time.Second
+
+	// After this many consecutive failed calls the breaker opens, calls fail
+	// right away until the cooldown is over.
+	breakerThreshold = 5
+	breakerCooldown  = 30 * time.Second
+)
+
+// ErrUnavailable is returned when composer can't be reached, or doesn't respond
+// within the deadline.
+var ErrUnavailable = errors.New("composer is unavailable")
+
+// ErrCircuitOpen is returned without contacting composer after too many failed
+// calls in a row.
+var ErrCircuitOpen = fmt.Errorf("%w: too many failed requests, backing off", ErrUnavailable)
+
+// ErrAuthentication is returned when no token to authenticate to composer can
+// be obtained, it doesn't count as a failed call to composer.
+var ErrAuthentication = errors.New("unable to authenticate to composer")
+
 var contentHeaders = map[string]string{"Content-Type": "application/json"}
 
 func NewClient(conf ComposerClientConfig) (*ComposerClient, error) {
-	if conf.TokenURL == "" {
-		return nil, fmt.Errorf("Client needs token endpoint")
-	}
-	if conf.ClientId == "" {
-		return nil, fmt.Errorf("Client needs clientId")
-	}
-	if conf.OfflineToken == "" && conf.ClientSecret == "" {
-		return nil, fmt.Errorf("Client needs offline token, or client secret")
+	// without a token source the client certificate authenticates the requests
+	tokens := conf.TokenSource
+	if tokens == nil && (conf.TokenURL != "" || conf.TLS.Cert == "") {
+		var err error
+		tokens, err = oauth.NewTokenSource(oauth.TokenSourceConfig{
+			TokenURL:     conf.TokenURL,
+			ClientId:     conf.ClientId,
+			OfflineToken: conf.OfflineToken,
+			ClientSecret: conf.ClientSecret,
+			Name:         "composer",
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	client, err := createClient(conf.ComposerURL, conf.CA)
+	httpClient, err := createClient(conf.ComposerURL, conf.TLS)
 	if err != nil {
-		return nil, fmt.Errorf("Error creating compose http client")
+		return nil, fmt.Errorf("Error creating compose http client: %v", err)
+	}
+
+	timeout := conf.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
 	}
 
+	client := retryablehttp.NewClient()
+	client.HTTPClient = httpClient
+	client.Logger = nil
+	client.RetryMax = retryMax
+	client.RetryWaitMin = retryWaitMin
+	client.RetryWaitMax = retryWaitMax
+	client.Backoff = retryablehttp.LinearJitterBackoff
+	client.CheckRetry = checkRetry
+	// hand the last response to the caller, composer's error responses
+	// carry the details
+	client.ErrorHandler = retryablehttp.PassthroughErrorHandler
+
 	cc := ComposerClient{
-		composerURL:  fmt.Sprintf("%s/api/image-builder-composer/v2", conf.ComposerURL),
-		tokenURL:     conf.TokenURL,
-		clientId:     conf.ClientId,
-		offlineToken: conf.OfflineToken,
-		clientSecret: conf.ClientSecret,
-		client:       client,
+		composerURL: fmt.Sprintf("%s/api/image-builder-composer/v2", conf.ComposerURL),
+		tokens:      tokens,
+		timeout:     timeout,
+		client:      client,
+		breaker: &breaker{
+			threshold: breakerThreshold,
+			cooldown:  breakerCooldown,
+		},
 	}
 
 	return &cc, nil
 }
 
-func createClient(composerURL string, ca string) (*http.Client, error) {
-	if !strings.HasPrefix(composerURL, "https") || ca == "" {
+func createClient(composerURL string, conf tlsconfig.Config) (*http.Client, error) {
+	if !strings.HasPrefix(composerURL, "https") || conf.IsZero() {
 		return &http.Client{}, nil
 	}
 
-	var tlsConfig *tls.Config
-	caCert, err := os.ReadFile(filepath.Clean(ca))
+	tlsConfig, err := tlsconfig.New(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	return &http.Client{Transport: transport}, nil
+}
+
+type idempotentKey struct{}
 
-	tlsConfig = &tls.Config{
-		MinVersion: tls.VersionTLS12,
-		RootCAs:    caCertPool,
+// checkRetry retries the requests marked as idempotent in their context, on
+// the same conditions as the default policy.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if idempotent, _ := ctx.Value(idempotentKey{}).(bool); !idempotent {
+		return false, nil
 	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
 
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
-	return &http.Client{Transport: transport}, nil
+// cancelOnClose cancels the context of a call once its response body has been
+// read, the deadline covers reading the body as well.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+func (cc *ComposerClient) request(ctx context.Context, method, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	callCtx, cancel := context.WithTimeout(ctx, cc.timeout)
+	if method == http.MethodGet {
+		callCtx = context.WithValue(callCtx, idempotentKey{}, true)
+	}
+
+	// the token is fetched before the breaker, failing to get one says
+	// nothing about composer
+	var token string
+	if cc.tokens != nil {
+		var err error
+		token, err = cc.tokens.Token(callCtx)
+		if err != nil {
+			cancel()
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", ErrAuthentication, err)
+		}
+	}
+
+	if !cc.breaker.allow() {
+		cancel()
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := cc.do(callCtx, method, url, headers, body, token)
+	if err != nil {
+		cancel()
+		if ctx.Err() != nil {
+			// the caller gave up, that says nothing about composer
+			cc.breaker.abort()
+			return nil, err
+		}
+		if errors.Is(err, ErrAuthentication) {
+			// composer responded, refusing the token
+			cc.breaker.success()
+			return nil, err
+		}
+		cc.breaker.failure()
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		cc.breaker.failure()
+	} else {
+		cc.breaker.success()
+	}
+	resp.Body = cancelOnClose{resp.Body, cancel}
+	return resp, nil
 }
 
-func (cc *ComposerClient) request(method, url string, headers map[string]string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, body)
+func (cc *ComposerClient) do(ctx context.Context, method, url string, headers map[string]string, body []byte, token string) (*http.Response, error) {
+	var rawBody interface{}
+	if body != nil {
+		rawBody = body
+	}
+	req, err := retryablehttp.NewRequestWithContext(ctx, method, url, rawBody)
 	if err != nil {
 		return nil, err
 	}
@@ -109,12 +235,11 @@ func (cc *ComposerClient) request(method, url string, headers map[string]string,
 		req.Header.Add(k, v)
 	}
 
-	token := func() string {
-		cc.tokenMu.RLock()
-		defer cc.tokenMu.RUnlock()
-		return cc.accessToken
+	if cc.tokens == nil {
+		return cc.client.Do(req)
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token()))
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
 	resp, err := cc.client.Do(req)
 	if err != nil {
@@ -122,83 +247,109 @@ func (cc *ComposerClient) request(method, url string, headers map[string]string,
 	}
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		err = cc.refreshToken()
+		closeBody(resp.Body)
+		cc.tokens.Invalidate(token)
+		token, err = cc.tokens.Token(ctx)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w: %v", ErrAuthentication, err)
 		}
 
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token()))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 		resp, err = cc.client.Do(req)
 	}
 
 	return resp, err
 }
 
-func (cc *ComposerClient) refreshToken() error {
-	cc.tokenMu.Lock()
-	defer cc.tokenMu.Unlock()
-
-	data := url.Values{}
-	if cc.offlineToken != "" {
-		data.Set("grant_type", "refresh_token")
-		data.Set("client_id", cc.clientId)
-		data.Set("refresh_token", cc.offlineToken)
-	}
-	if cc.clientSecret != "" {
-		data.Set("grant_type", "client_credentials")
-		data.Set("client_id", cc.clientId)
-		data.Set("client_secret", cc.clientSecret)
+func closeBody(body io.Closer) {
+	err := body.Close()
+	if err != nil {
+		logrus.Errorf("closing response body failed: %v", err)
 	}
+}
 
-	resp, err := http.PostForm(cc.tokenURL, data)
+// call does a request and decodes the response into result if it has the
+// expected status code, other status codes result in an *APIError.
+func (cc *ComposerClient) call(ctx context.Context, method, url string, body []byte, expected int, result interface{}) error {
+	var headers map[string]string
+	if body != nil {
+		headers = contentHeaders
+	}
+	resp, err := cc.request(ctx, method, url, headers, body)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			logrus.Errorf("Error closing body after refreshing composer client token: %v", err)
-		}
-	}()
+	defer closeBody(resp.Body)
 
-	var tr tokenResponse
-	err = json.NewDecoder(resp.Body).Decode(&tr)
+	if resp.StatusCode != expected {
+		return newAPIError(resp)
+	}
+	if result == nil {
+		return nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(result)
 	if err != nil {
-		return err
+		return fmt.Errorf("Unable to decode composer response: %w", err)
 	}
-
-	cc.accessToken = tr.AccessToken
 	return nil
 }
 
-func (cc *ComposerClient) ComposeStatus(id uuid.UUID) (*http.Response, error) {
-	return cc.request("GET", fmt.Sprintf("%s/composes/%s", cc.composerURL, id), nil, nil)
+func (cc *ComposerClient) ComposeStatus(ctx context.Context, id uuid.UUID) (*ComposeStatus, error) {
+	var status ComposeStatus
+	err := cc.call(ctx, "GET", fmt.Sprintf("%s/composes/%s", cc.composerURL, id), nil, http.StatusOK, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
 }
 
-func (cc *ComposerClient) ComposeMetadata(id uuid.UUID) (*http.Response, error) {
-	return cc.request("GET", fmt.Sprintf("%s/composes/%s/metadata", cc.composerURL, id), nil, nil)
+func (cc *ComposerClient) ComposeMetadata(ctx context.Context, id uuid.UUID) (*ComposeMetadata, error) {
+	var metadata ComposeMetadata
+	err := cc.call(ctx, "GET", fmt.Sprintf("%s/composes/%s/metadata", cc.composerURL, id), nil, http.StatusOK, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
 }
 
-func (cc *ComposerClient) Compose(compose ComposeRequest) (*http.Response, error) {
+func (cc *ComposerClient) Compose(ctx context.Context, compose ComposeRequest) (*ComposeId, error) {
 	buf, err := json.Marshal(compose)
 	if err != nil {
 		return nil, err
 	}
 
-	return cc.request("POST", fmt.Sprintf("%s/compose", cc.composerURL), contentHeaders, bytes.NewReader(buf))
+	var composeId ComposeId
+	err = cc.call(ctx, "POST", fmt.Sprintf("%s/compose", cc.composerURL), buf, http.StatusCreated, &composeId)
+	if err != nil {
+		return nil, err
+	}
+	return &composeId, nil
 }
 
-func (cc *ComposerClient) OpenAPI() (*http.Response, error) {
-	return cc.request("GET", fmt.Sprintf("%s/openapi", cc.composerURL), nil, nil)
+// OpenAPI fetches composer's spec, to check whether it's reachable
+func (cc *ComposerClient) OpenAPI(ctx context.Context) error {
+	return cc.call(ctx, "GET", fmt.Sprintf("%s/openapi", cc.composerURL), nil, http.StatusOK, nil)
 }
 
-func (cc *ComposerClient) CloneCompose(id uuid.UUID, clone CloneComposeBody) (*http.Response, error) {
+func (cc *ComposerClient) CloneCompose(ctx context.Context, id uuid.UUID, clone CloneComposeBody) (*CloneComposeResponse, error) {
 	buf, err := json.Marshal(clone)
 	if err != nil {
 		return nil, err
 	}
-	return cc.request("POST", fmt.Sprintf("%s/composes/%s/clone", cc.composerURL, id), contentHeaders, bytes.NewReader(buf))
+
+	var cloneResponse CloneComposeResponse
+	err = cc.call(ctx, "POST", fmt.Sprintf("%s/composes/%s/clone", cc.composerURL, id), buf, http.StatusCreated, &cloneResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &cloneResponse, nil
 }
 
-func (cc *ComposerClient) CloneStatus(id uuid.UUID) (*http.Response, error) {
-	return cc.request("GET", fmt.Sprintf("%s/clones/%s", cc.composerURL, id), nil, nil)
+func (cc *ComposerClient) CloneStatus(ctx context.Context, id uuid.UUID) (*CloneStatus, error) {
+	var status CloneStatus
+	err := cc.call(ctx, "GET", fmt.Sprintf("%s/clones/%s", cc.composerURL, id), nil, http.StatusOK, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
 }
@@ -0,0 +1,282 @@
+package composer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/image-builder/internal/tlsconfig"
+)
+
+func newTestClient(t *testing.T, composerURL string, timeout time.Duration) *ComposerClient {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "accesstoken",
+			"expires_in":   900,
+		})
+		require.NoError(t, err)
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	cc, err := NewClient(ComposerClientConfig{
+		ComposerURL:  composerURL,
+		TokenURL:     tokenServer.URL,
+		ClientId:     "rhsm-api",
+		OfflineToken: "offlinetoken",
+		Timeout:      timeout,
+	})
+	require.NoError(t, err)
+	cc.client.RetryWaitMin = time.Millisecond
+	cc.client.RetryWaitMax = time.Millisecond
+	return cc
+}
+
+func TestRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(`{"image_status": {"status": "building"}}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cc := newTestClient(t, srv.URL, 0)
+	status, err := cc.ComposeStatus(context.Background(), uuid.New())
+	require.NoError(t, err)
+	require.Equal(t, ImageStatusValueBuilding, status.ImageStatus.Status)
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestDoesNotRetryCompose(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte(`{"id": "10", "code": "IMAGE-BUILDER-COMPOSER-10", "reason": "Error resolving OSTree repo"}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cc := newTestClient(t, srv.URL, 0)
+	_, err := cc.Compose(context.Background(), ComposeRequest{})
+	// the error response is handed to the caller
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	require.Equal(t, "10", apiErr.Err.Id)
+	require.EqualError(t, err, "composer returned 500: IMAGE-BUILDER-COMPOSER-10: Error resolving OSTree repo")
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRefreshesTokenAndResendsBody(t *testing.T) {
+	var issued int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token%d", n),
+		})
+		require.NoError(t, err)
+	}))
+	defer tokenServer.Close()
+
+	// only the second token is accepted, as if the first one got revoked
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer token2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, err = w.Write([]byte(`{"id": "d3d2cf2e-6ad6-4a7d-9f8e-3bd1e4a0b4c4"}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cc, err := NewClient(ComposerClientConfig{
+		ComposerURL:  srv.URL,
+		TokenURL:     tokenServer.URL,
+		ClientId:     "rhsm-api",
+		OfflineToken: "offlinetoken",
+	})
+	require.NoError(t, err)
+
+	composeId, err := cc.Compose(context.Background(), ComposeRequest{Distribution: "rhel-9"})
+	require.NoError(t, err)
+	require.Equal(t, "d3d2cf2e-6ad6-4a7d-9f8e-3bd1e4a0b4c4", composeId.Id.String())
+	require.Len(t, bodies, 2)
+	require.Equal(t, bodies[0], bodies[1])
+	require.Contains(t, bodies[1], "rhel-9")
+	require.Equal(t, int32(2), atomic.LoadInt32(&issued))
+}
+
+func TestDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	cc := newTestClient(t, srv.URL, 50*time.Millisecond)
+	start := time.Now()
+	_, err := cc.ComposeStatus(context.Background(), uuid.New())
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls int32
+	var healthy int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, err := w.Write([]byte(`{}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cc := newTestClient(t, srv.URL, 0)
+	cc.client.RetryMax = 0
+	cc.breaker.cooldown = 50 * time.Millisecond
+
+	for i := 0; i < breakerThreshold; i++ {
+		_, err := cc.ComposeStatus(context.Background(), uuid.New())
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	}
+
+	// open, composer isn't contacted anymore
+	_, err := cc.ComposeStatus(context.Background(), uuid.New())
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.Equal(t, int32(breakerThreshold), atomic.LoadInt32(&calls))
+
+	// after the cooldown a probe goes through and closes the breaker
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(60 * time.Millisecond)
+	_, err = cc.ComposeStatus(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	_, err = cc.ComposeStatus(context.Background(), uuid.New())
+	require.NoError(t, err)
+	require.Equal(t, int32(breakerThreshold+2), atomic.LoadInt32(&calls))
+}
+
+func TestCancelledCallerDoesNotTripBreaker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	cc := newTestClient(t, srv.URL, 0)
+	for i := 0; i < breakerThreshold; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := cc.ComposeStatus(ctx, uuid.New())
+		cancel()
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrUnavailable)
+	}
+	require.True(t, cc.breaker.allow())
+}
+
+func TestFailedTokenDoesNotTripBreaker(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer tokenServer.Close()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+
+	cc, err := NewClient(ComposerClientConfig{
+		ComposerURL:  srv.URL,
+		TokenURL:     tokenServer.URL,
+		ClientId:     "rhsm-api",
+		OfflineToken: "offlinetoken",
+	})
+	require.NoError(t, err)
+	for i := 0; i < breakerThreshold; i++ {
+		_, err := cc.ComposeStatus(context.Background(), uuid.New())
+		require.ErrorIs(t, err, ErrAuthentication)
+		require.NotErrorIs(t, err, ErrUnavailable)
+	}
+	require.True(t, cc.breaker.allow())
+	require.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestClientCertificateInsteadOfToken(t *testing.T) {
+	_, err := NewClient(ComposerClientConfig{ComposerURL: "https://composer.example.com"})
+	require.Error(t, err)
+
+	// the certificate is only loaded for https, the test server is plain http
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Empty(t, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cc, err := NewClient(ComposerClientConfig{
+		ComposerURL: srv.URL,
+		TLS:         tlsconfig.Config{Cert: "cert.pem", Key: "key.pem"},
+	})
+	require.NoError(t, err)
+	err = cc.OpenAPI(context.Background())
+	require.NoError(t, err)
+}
+
+func TestAPIErrorWithoutComposerBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("404 page not found"))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cc := newTestClient(t, srv.URL, 0)
+	_, err := cc.CloneStatus(context.Background(), uuid.New())
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.True(t, apiErr.NotFound())
+	require.Equal(t, Error{}, apiErr.Err)
+	require.EqualError(t, err, "composer returned 404: 404 page not found")
+}
+
+func TestUndecodableResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`"not a compose status"`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cc := newTestClient(t, srv.URL, 0)
+	_, err := cc.ComposeMetadata(context.Background(), uuid.New())
+	require.Error(t, err)
+	var apiErr *APIError
+	require.False(t, errors.As(err, &apiErr))
+}
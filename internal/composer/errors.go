@@ -0,0 +1,48 @@
+package composer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error bodies are cut off after this many bytes
+const maxErrorBody = 64 * 1024
+
+// APIError is an error response of composer, a response with an unexpected
+// status code.
+type APIError struct {
+	StatusCode int
+	// The error composer responded with, zero if the body isn't a composer
+	// error
+	Err Error
+	// The raw body, for logging
+	Body []byte
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := APIError{StatusCode: resp.StatusCode}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		// the status code is all there is
+		return &apiErr
+	}
+	apiErr.Body = body
+	// not every error response is a composer error, e.g. the ones of proxies
+	_ = json.Unmarshal(body, &apiErr.Err)
+	return &apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.Err.Reason != "" {
+		return fmt.Sprintf("composer returned %d: %s: %s", e.StatusCode, e.Err.Code, e.Err.Reason)
+	}
+	return fmt.Sprintf("composer returned %d: %s", e.StatusCode, e.Body)
+}
+
+// NotFound reports whether composer doesn't know the compose or clone, which
+// happens once composes expire.
+func (e *APIError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
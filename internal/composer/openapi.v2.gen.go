@@ -18,6 +18,13 @@ const (
 	ComposeStatusValueSuccess ComposeStatusValue = "success"
 )
 
+// Defines values for CustomizationsPartitioningMode.
+const (
+	AutoLvm CustomizationsPartitioningMode = "auto-lvm"
+	Lvm     CustomizationsPartitioningMode = "lvm"
+	Raw     CustomizationsPartitioningMode = "raw"
+)
+
 // Defines values for ImageStatusValue.
 const (
 	ImageStatusValueBuilding    ImageStatusValue = "building"
@@ -276,12 +283,24 @@ type Customizations struct {
 	// repositories will be used to depsolve and retrieve packages. Additionally,
 	// these packages will be saved and imported to the `/etc/yum.repos.d/` directory
 	// on the image
-	CustomRepositories *[]CustomRepository `json:"custom_repositories,omitempty"`
-	Directories        *[]Directory        `json:"directories,omitempty"`
-	Files              *[]File             `json:"files,omitempty"`
-	Filesystem         *[]Filesystem       `json:"filesystem,omitempty"`
-	Openscap           *OpenSCAP           `json:"openscap,omitempty"`
-	Packages           *[]string           `json:"packages,omitempty"`
+	CustomRepositories *[]CustomRepository    `json:"custom_repositories,omitempty"`
+	Directories        *[]Directory           `json:"directories,omitempty"`
+	Files              *[]File                `json:"files,omitempty"`
+	Filesystem         *[]Filesystem          `json:"filesystem,omitempty"`
+	Fips               *FIPS                  `json:"fips,omitempty"`
+	Firewall           *FirewallCustomization `json:"firewall,omitempty"`
+	Groups             *[]Group               `json:"groups,omitempty"`
+	Hostname           *string                `json:"hostname,omitempty"`
+	Kernel             *Kernel                `json:"kernel,omitempty"`
+	Locale             *Locale                `json:"locale,omitempty"`
+	Openscap           *OpenSCAP              `json:"openscap,omitempty"`
+	Packages           *[]string              `json:"packages,omitempty"`
+
+	// Select how the disk image will be partitioned. 'auto-lvm' will use raw unless
+	// there are one or more mountpoints in which case it will use LVM. 'lvm' always
+	// uses LVM, even when there are no extra mountpoints. 'raw' uses raw partitions
+	// even when there are one or more mountpoints.
+	PartitioningMode *CustomizationsPartitioningMode `json:"partitioning_mode,omitempty"`
 
 	// Extra repositories for packages specified in customizations. These
 	// repositories will only be used to depsolve and retrieve packages
@@ -289,17 +308,18 @@ type Customizations struct {
 	// any other part of the build process). The package_sets field for these
 	// repositories is ignored.
 	PayloadRepositories *[]Repository `json:"payload_repositories,omitempty"`
-	Services            *struct {
-		// List of services to disable by default
-		Disabled *[]string `json:"disabled,omitempty"`
-
-		// List of services to enable by default
-		Enabled *[]string `json:"enabled,omitempty"`
-	} `json:"services,omitempty"`
-	Subscription *Subscription `json:"subscription,omitempty"`
-	Users        *[]User       `json:"users,omitempty"`
+	Services            *Services     `json:"services,omitempty"`
+	Subscription        *Subscription `json:"subscription,omitempty"`
+	Timezone            *Timezone     `json:"timezone,omitempty"`
+	Users               *[]User       `json:"users,omitempty"`
 }
 
+// Select how the disk image will be partitioned. 'auto-lvm' will use raw unless
+// there are one or more mountpoints in which case it will use LVM. 'lvm' always
+// uses LVM, even when there are no extra mountpoints. 'raw' uses raw partitions
+// even when there are one or more mountpoints.
+type CustomizationsPartitioningMode string
+
 // A custom directory to create in the final artifact.
 type Directory struct {
 	// Ensure that the parent directories exist
@@ -338,6 +358,12 @@ type ErrorList struct {
 	Total int     `json:"total"`
 }
 
+// FIPS defines model for FIPS.
+type FIPS struct {
+	// Enables the system FIPS mode
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
 // A custom file to create in the final artifact.
 type File struct {
 	// Contents of the file as plain text
@@ -365,6 +391,21 @@ type Filesystem struct {
 	Mountpoint string `json:"mountpoint"`
 }
 
+// FirewallCustomization defines model for FirewallCustomization.
+type FirewallCustomization struct {
+	// Ports or port ranges to open, with their protocol
+	Ports *[]string `json:"ports,omitempty"`
+
+	// Firewalld services to allow or deny
+	Services *FirewallServices `json:"services,omitempty"`
+}
+
+// Firewalld services to allow or deny
+type FirewallServices struct {
+	Disabled *[]string `json:"disabled,omitempty"`
+	Enabled  *[]string `json:"enabled,omitempty"`
+}
+
 // GCPUploadOptions defines model for GCPUploadOptions.
 type GCPUploadOptions struct {
 	// Name of an existing STANDARD Storage class Bucket.
@@ -403,6 +444,12 @@ type GCPUploadStatus struct {
 	ProjectId string `json:"project_id"`
 }
 
+// Group defines model for Group.
+type Group struct {
+	Gid  *int   `json:"gid,omitempty"`
+	Name string `json:"name"`
+}
+
 // ImageRequest defines model for ImageRequest.
 type ImageRequest struct {
 	Architecture string       `json:"architecture"`
@@ -431,6 +478,15 @@ type ImageStatusValue string
 // ImageTypes defines model for ImageTypes.
 type ImageTypes string
 
+// Kernel defines model for Kernel.
+type Kernel struct {
+	// Arguments to append to the kernel command line
+	Append *string `json:"append,omitempty"`
+
+	// Name of the kernel package to install
+	Name *string `json:"name,omitempty"`
+}
+
 // Koji defines model for Koji.
 type Koji struct {
 	Name    string `json:"name"`
@@ -459,6 +515,15 @@ type List struct {
 	Total int    `json:"total"`
 }
 
+// Locale defines model for Locale.
+type Locale struct {
+	// Keyboard layout
+	Keyboard *string `json:"keyboard,omitempty"`
+
+	// Languages to install, the first one is the default one
+	Languages *[]string `json:"languages,omitempty"`
+}
+
 // OSTree defines model for OSTree.
 type OSTree struct {
 	// A URL which, if set, is used for fetching content. Implies that `url` is set as well,
@@ -485,7 +550,14 @@ type ObjectReference struct {
 
 // OpenSCAP defines model for OpenSCAP.
 type OpenSCAP struct {
-	ProfileId string `json:"profile_id"`
+	ProfileId string             `json:"profile_id"`
+	Tailoring *OpenSCAPTailoring `json:"tailoring,omitempty"`
+}
+
+// OpenSCAPTailoring defines model for OpenSCAPTailoring.
+type OpenSCAPTailoring struct {
+	Selected   *[]string `json:"selected,omitempty"`
+	Unselected *[]string `json:"unselected,omitempty"`
 }
 
 // PackageMetadata defines model for PackageMetadata.
@@ -525,6 +597,15 @@ type Repository struct {
 	Rhsm *bool `json:"rhsm,omitempty"`
 }
 
+// Services defines model for Services.
+type Services struct {
+	// List of services to disable by default
+	Disabled *[]string `json:"disabled,omitempty"`
+
+	// List of services to enable by default
+	Enabled *[]string `json:"enabled,omitempty"`
+}
+
 // Subscription defines model for Subscription.
 type Subscription struct {
 	ActivationKey string `json:"activation_key"`
@@ -537,6 +618,15 @@ type Subscription struct {
 	ServerUrl string `json:"server_url"`
 }
 
+// Timezone defines model for Timezone.
+type Timezone struct {
+	// NTP servers to use instead of the default ones
+	Ntpservers *[]string `json:"ntpservers,omitempty"`
+
+	// Name of the timezone, from the tz database
+	Timezone *string `json:"timezone,omitempty"`
+}
+
 // This should really be oneOf but AWSS3UploadOptions is a subset of
 // AWSEC2UploadOptions. This means that all AWSEC2UploadOptions objects
 // are also valid AWSS3UploadOptionas objects which violates the oneOf
@@ -559,9 +649,19 @@ type UploadTypes string
 
 // User defines model for User.
 type User struct {
+	Gid    *int      `json:"gid,omitempty"`
 	Groups *[]string `json:"groups,omitempty"`
-	Key    *string   `json:"key,omitempty"`
-	Name   string    `json:"name"`
+	Home   *string   `json:"home,omitempty"`
+
+	// SSH public keys of the user, one per line, as in an authorized_keys file
+	Key  *string `json:"key,omitempty"`
+	Name string  `json:"name"`
+
+	// Password of the user, if it starts with $6$, $5$, $y$ or $2b$ it's
+	// taken as the hash of the password
+	Password *string `json:"password,omitempty"`
+	Shell    *string `json:"shell,omitempty"`
+	Uid      *int    `json:"uid,omitempty"`
 }
 
 // Page defines model for page.
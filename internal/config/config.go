@@ -16,9 +16,15 @@ type ImageBuilderConfig struct {
 	ComposerOfflineToken string `env:"COMPOSER_OFFLINE_TOKEN"`
 	ComposerClientSecret string `env:"COMPOSER_CLIENT_SECRET"`
 	ComposerCA           string `env:"COMPOSER_CA_PATH"`
+	ComposerClientCert   string `env:"COMPOSER_CLIENT_CERT_PATH"`
+	ComposerClientKey    string `env:"COMPOSER_CLIENT_KEY_PATH"`
+	ComposerTimeout      string `env:"COMPOSER_TIMEOUT"`
+	ComposerBackendsFile string `env:"COMPOSER_BACKENDS_FILE"`
 	OsbuildRegion        string `env:"OSBUILD_AWS_REGION"`
 	OsbuildGCPRegion     string `env:"OSBUILD_GCP_REGION"`
 	OsbuildGCPBucket     string `env:"OSBUILD_GCP_BUCKET"`
+	OsbuildAWSRegions    string `env:"OSBUILD_AWS_ALLOWED_REGIONS"`
+	OsbuildGCPRegions    string `env:"OSBUILD_GCP_ALLOWED_REGIONS"`
 	DistributionsDir     string `env:"DISTRIBUTIONS_DIR"`
 	MigrationsDir        string `env:"MIGRATIONS_DIR"`
 	TernExecutable       string `env:"TERN_EXECUTABLE"`
@@ -35,6 +41,29 @@ type ImageBuilderConfig struct {
 	SplunkPort           string `env:"SPLUNK_HEC_PORT"`
 	SplunkToken          string `env:"SPLUNK_HEC_TOKEN"`
 	ProvisioningURL      string `env:"PROVISIONING_URL"`
+	ProvisioningCA       string `env:"PROVISIONING_CA_PATH"`
+	ProvisioningCert     string `env:"PROVISIONING_CLIENT_CERT_PATH"`
+	ProvisioningKey      string `env:"PROVISIONING_CLIENT_KEY_PATH"`
+	TLSMinVersion        string `env:"TLS_MIN_VERSION"`
+	CertReloadInterval   string `env:"CLIENT_CERT_RELOAD_INTERVAL"`
+	RestoreGracePeriod   string `env:"COMPOSE_RESTORE_GRACE_PERIOD"`
+	DeletedRetention     string `env:"DELETED_COMPOSE_RETENTION"`
+	ArtifactLifetime     string `env:"COMPOSER_ARTIFACT_LIFETIME"`
+	RetentionInterval    string `env:"RETENTION_JOB_INTERVAL"`
+	OSTreeVerifyRemote   string `env:"OSTREE_VERIFY_REMOTE"`
+	SecretsKey           string `env:"SECRETS_ENCRYPTION_KEY"`
+}
+
+// ParseList splits a comma separated config value, ignoring empty items.
+func ParseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func (ibc *ImageBuilderConfig) IsDebug() bool {
@@ -58,3 +58,9 @@ func TestEnvPointerValues(t *testing.T) {
 	require.Empty(t, config.CwAccessKeyID)
 	require.Empty(t, config.CwSecretAccessKey)
 }
+
+func TestParseList(t *testing.T) {
+	require.Equal(t, []string{"us-east-1", "eu-central-1"}, ParseList("us-east-1, eu-central-1,"))
+	require.Empty(t, ParseList(""))
+	require.Empty(t, ParseList(" , "))
+}
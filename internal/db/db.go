@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
@@ -14,6 +17,22 @@ import (
 // ComposeNotFoundError occurs when no compose request is found for a user.
 var ComposeNotFoundError = errors.New("Compose not found")
 var CloneNotFoundError = errors.New("Clone not found")
+var CloneBatchNotFoundError = errors.New("Clone batch not found")
+var OSTreeCommitNotFoundError = errors.New("OSTree commit not found")
+var SubscriptionProfileNotFoundError = errors.New("Subscription profile not found")
+var SubscriptionProfileExistsError = errors.New("Subscription profile with that name exists")
+
+// Actions recorded in the audit table for mutating API calls.
+const (
+	AuditActionCompose = "compose"
+	AuditActionClone   = "clone"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+
+	AuditActionCreateSubscriptionProfile = "create_subscription_profile"
+	AuditActionUpdateSubscriptionProfile = "update_subscription_profile"
+	AuditActionDeleteSubscriptionProfile = "delete_subscription_profile"
+)
 
 type dB struct {
 	Pool *pgxpool.Pool
@@ -24,41 +43,179 @@ type ComposeEntry struct {
 	Request   json.RawMessage
 	CreatedAt time.Time
 	ImageName *string
+	// Name of the composer backend building the compose
+	Backend string
+}
+
+type ComposesSort string
+
+const (
+	SortCreatedAtDesc ComposesSort = "created_at_desc"
+	SortCreatedAtAsc  ComposesSort = "created_at_asc"
+	SortImageNameAsc  ComposesSort = "image_name_asc"
+	SortImageNameDesc ComposesSort = "image_name_desc"
+)
+
+// ComposeStatusUnknown filters the composes whose status was never polled
+const ComposeStatusUnknown = "unknown"
+
+// ComposesFilter narrows down the composes returned by GetComposes, unset
+// fields don't filter anything.
+type ComposesFilter struct {
+	// Only composes created less than MaxAge ago
+	MaxAge time.Duration
+	Since  *time.Time
+	Until  *time.Time
+
+	Distribution *string
+	// Composes matching any of the image types
+	ImageTypes   []string
+	Architecture *string
+	UploadType   *string
+	// Case insensitive substring of the image name
+	ImageName *string
+	// Last polled status, or ComposeStatusUnknown for the composes whose
+	// status was never polled
+	Status *string
+	// Composes matching all of the label requirements
+	Labels []LabelRequirement
+
+	// Defaults to SortCreatedAtDesc
+	Sort ComposesSort
+}
+
+// Keyset is a position in a listing, by the sort key and id of the row at the
+// position. Paging by keyset instead of an offset keeps the pages stable while
+// rows are being inserted.
+type Keyset struct {
+	CreatedAt time.Time
+	// Only used when sorting composes by image name
+	ImageName *string
+	Id        uuid.UUID
+	// Return the rows preceding the position instead of the ones following it
+	Before bool
+}
+
+// LabelRequirement matches the composes having the label Key set to Value, or
+// having the label Key at all when Value is nil. Negate inverts the match.
+type LabelRequirement struct {
+	Key    string
+	Value  *string
+	Negate bool
 }
 
 type CloneEntry struct {
 	Id        uuid.UUID
 	Request   json.RawMessage
 	CreatedAt time.Time
+	// Backend of the compose the clone was made from
+	Backend string
+}
+
+// OSTreeCommitEntry is an edge commit compose in the lineage of its ref
+type OSTreeCommitEntry struct {
+	ComposeId uuid.UUID
+	Ref       string
+	// Repository the parent was pulled from
+	Url    *string
+	Parent *string
+	// Compose of the parent, when the parent was taken from the lineage
+	ParentComposeId *uuid.UUID
+	// Checksum of the commit, known once the compose succeeded
+	Commit *string
+	// Last known status of the compose
+	Status    *string
+	Backend   string
+	CreatedAt time.Time
+}
+
+// OSTreeRefEntry summarizes the lineage of a ref
+type OSTreeRefEntry struct {
+	Ref          string
+	Commits      int
+	LastCommitAt time.Time
+}
+
+// SubscriptionProfileEntry is a named subscription of an organization, the
+// activation key is stored encrypted
+type SubscriptionProfileEntry struct {
+	Id            uuid.UUID
+	Name          string
+	Organization  int
+	ActivationKey []byte
+	ServerUrl     string
+	BaseUrl       string
+	Insights      bool
+	Rhc           bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type AuditEntry struct {
+	Id            uuid.UUID
+	OrgId         string
+	AccountNumber string
+	Username      string
+	Action        string
+	TargetId      uuid.UUID
+	RequestHash   string
+	CreatedAt     time.Time
 }
 
 type DB interface {
-	InsertCompose(jobId uuid.UUID, accountNumber, orgId string, imageName *string, request json.RawMessage) error
-	GetComposes(orgId string, since time.Duration, limit, offset int) ([]ComposeEntry, int, error)
+	InsertCompose(jobId uuid.UUID, accountNumber, orgId string, imageName *string, request json.RawMessage, backend string) error
+	GetComposes(orgId string, filter ComposesFilter, keyset *Keyset, limit, offset int) ([]ComposeEntry, int, error)
 	GetCompose(jobId uuid.UUID, orgId string) (*ComposeEntry, error)
 	GetComposeImageType(jobId uuid.UUID, orgId string) (string, error)
 	CountComposesSince(orgId string, duration time.Duration) (int, error)
+	UpdateComposeStatus(jobId uuid.UUID, status string) error
 	DeleteCompose(jobId uuid.UUID, orgId string) error
+	RestoreCompose(jobId uuid.UUID, orgId string, gracePeriod time.Duration) error
+	PurgeDeletedComposes(deletedFor time.Duration) (int64, error)
+	PurgeComposesOlderThan(age time.Duration) (int64, error)
 
-	InsertClone(composeId, cloneId uuid.UUID, request json.RawMessage) error
-	GetClonesForCompose(composeId uuid.UUID, orgId string, limit, offset int) ([]CloneEntry, int, error)
+	InsertClone(composeId, cloneId uuid.UUID, request json.RawMessage, batchId *uuid.UUID) error
+	GetClonesForCompose(composeId uuid.UUID, orgId string, keyset *Keyset, limit, offset int) ([]CloneEntry, int, error)
 	GetClone(id uuid.UUID, orgId string) (*CloneEntry, error)
+	GetCloneBatch(batchId uuid.UUID, orgId string) ([]CloneEntry, error)
+
+	InsertOSTreeCommit(composeId uuid.UUID, orgId, ref string, url, parent *string, parentComposeId *uuid.UUID) error
+	UpdateOSTreeCommit(composeId uuid.UUID, commit string) error
+	GetOSTreeCommits(orgId, ref string, limit, offset int) ([]OSTreeCommitEntry, int, error)
+	GetOSTreeRefs(orgId string, limit, offset int) ([]OSTreeRefEntry, int, error)
+
+	InsertSubscriptionProfile(orgId string, profile SubscriptionProfileEntry) error
+	GetSubscriptionProfiles(orgId string, limit, offset int) ([]SubscriptionProfileEntry, int, error)
+	GetSubscriptionProfile(id uuid.UUID, orgId string) (*SubscriptionProfileEntry, error)
+	UpdateSubscriptionProfile(orgId string, profile SubscriptionProfileEntry) error
+	DeleteSubscriptionProfile(id uuid.UUID, orgId string) error
+
+	InsertAuditEntry(orgId, accountNumber, username, action string, targetId uuid.UUID, requestHash string) error
+	GetAuditEntries(orgId string, limit, offset int) ([]AuditEntry, int, error)
 }
 
 const (
+	// the labels are part of the request, they're copied into their own
+	// column so they can be indexed
 	sqlInsertCompose = `
-		INSERT INTO composes(job_id, request, created_at, account_number, org_id, image_name)
-		VALUES ($1, $2, CURRENT_TIMESTAMP, $3, $4, $5)`
+		INSERT INTO composes(job_id, request, created_at, account_number, org_id, image_name, labels, backend)
+		VALUES ($1, $2, CURRENT_TIMESTAMP, $3, $4, $5, COALESCE($2::jsonb->'labels', '{}'), $6)`
 
+	// filled in by ComposesFilter.where and ComposesFilter.orderBy
 	sqlGetComposes = `
-		SELECT job_id, request, created_at, image_name
+		SELECT job_id, request, created_at, image_name, backend
 		FROM composes
-		WHERE org_id=$1 AND CURRENT_TIMESTAMP - created_at <= $2 AND deleted=FALSE
-		ORDER BY created_at DESC
-		LIMIT $3 OFFSET $4`
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d`
+
+	sqlCountComposes = `
+		SELECT COUNT(*)
+		FROM composes
+		WHERE %s`
 
 	sqlGetCompose = `
-		SELECT job_id, request, created_at, image_name
+		SELECT job_id, request, created_at, image_name, backend
 		FROM composes
 		WHERE org_id=$1 AND job_id=$2 AND deleted=FALSE`
 
@@ -67,35 +224,46 @@ const (
 		FROM composes,jsonb_array_elements(composes.request->'image_requests') as req
 		WHERE org_id=$1 AND job_id = $2`
 
-	sqlCountActiveComposesSince = `
-		SELECT COUNT(*)
-		FROM composes
-		WHERE org_id=$1 AND CURRENT_TIMESTAMP - created_at <= $2 AND deleted = FALSE`
-
 	sqlCountComposesSince = `
 		SELECT COUNT(*)
 		FROM composes
 		WHERE org_id=$1 AND CURRENT_TIMESTAMP - created_at <= $2`
 
+	sqlUpdateComposeStatus = `
+		UPDATE composes
+		SET status = $2
+		WHERE job_id=$1`
+
 	sqlDeleteCompose = `
 		UPDATE composes
-		SET deleted = TRUE
-		WHERE org_id=$1 AND job_id=$2
-        `
+		SET deleted = TRUE, deleted_at = CURRENT_TIMESTAMP
+		WHERE org_id=$1 AND job_id=$2 AND deleted = FALSE`
+
+	sqlRestoreCompose = `
+		UPDATE composes
+		SET deleted = FALSE, deleted_at = NULL
+		WHERE org_id=$1 AND job_id=$2 AND deleted = TRUE AND CURRENT_TIMESTAMP - deleted_at <= $3`
+
+	sqlPurgeDeletedComposes = `
+		DELETE FROM composes
+		WHERE deleted = TRUE AND CURRENT_TIMESTAMP - deleted_at > $1`
+
+	sqlPurgeComposesOlderThan = `
+		DELETE FROM composes
+		WHERE CURRENT_TIMESTAMP - created_at > $1`
 
 	sqlInsertClone = `
-		INSERT INTO clones(id, compose_id, request, created_at)
-		VALUES($1, $2, $3, CURRENT_TIMESTAMP)`
+		INSERT INTO clones(id, compose_id, request, batch_id, created_at)
+		VALUES($1, $2, $3, $4, CURRENT_TIMESTAMP)`
 
+	// filled in by keysetWhere and keysetOrderBy
 	sqlGetClonesForCompose = `
-		SELECT clones.id, clones.request, clones.created_at
+		SELECT clones.id, clones.request, clones.created_at, composes.backend
 		FROM clones
-		WHERE clones.compose_id=$1 AND $1 in (
-			SELECT composes.job_id
-			FROM composes
-			WHERE composes.org_id=$2)
-		ORDER BY created_at DESC
-		LIMIT $3 OFFSET $4`
+		JOIN composes ON composes.job_id = clones.compose_id
+		WHERE clones.compose_id=$1 AND composes.org_id=$2 AND %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d`
 
 	sqlCountClonesForCompose = `
 		SELECT COUNT(*)
@@ -106,12 +274,107 @@ const (
 			WHERE composes.org_id=$2)`
 
 	sqlGetClone = `
-		SELECT clones.id, clones.request, clones.created_at
+		SELECT clones.id, clones.request, clones.created_at, composes.backend
 		FROM clones
-		WHERE clones.id=$1 AND clones.compose_id in (
-			SELECT composes.job_id
-			FROM composes
-			WHERE composes.org_id=$2)`
+		JOIN composes ON composes.job_id = clones.compose_id
+		WHERE clones.id=$1 AND composes.org_id=$2`
+
+	sqlGetCloneBatch = `
+		SELECT clones.id, clones.request, clones.created_at, composes.backend
+		FROM clones
+		JOIN composes ON composes.job_id = clones.compose_id
+		WHERE clones.batch_id=$1 AND composes.org_id=$2
+		ORDER BY clones.created_at, clones.id`
+
+	sqlInsertOSTreeCommit = `
+		INSERT INTO ostree_commits(compose_id, org_id, ref, url, parent, parent_compose_id, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)`
+
+	sqlUpdateOSTreeCommit = `
+		UPDATE ostree_commits
+		SET commit_id = $2
+		WHERE compose_id = $1`
+
+	// the commits of deleted composes drop out of the lineage
+	sqlGetOSTreeCommits = `
+		SELECT ostree_commits.compose_id, ostree_commits.ref, ostree_commits.url, ostree_commits.parent,
+			ostree_commits.parent_compose_id, ostree_commits.commit_id, composes.status, composes.backend,
+			ostree_commits.created_at
+		FROM ostree_commits
+		JOIN composes ON composes.job_id = ostree_commits.compose_id
+		WHERE ostree_commits.org_id=$1 AND ostree_commits.ref=$2 AND composes.deleted=FALSE
+		ORDER BY ostree_commits.created_at DESC, ostree_commits.compose_id DESC
+		LIMIT $3 OFFSET $4`
+
+	sqlCountOSTreeCommits = `
+		SELECT COUNT(*)
+		FROM ostree_commits
+		JOIN composes ON composes.job_id = ostree_commits.compose_id
+		WHERE ostree_commits.org_id=$1 AND ostree_commits.ref=$2 AND composes.deleted=FALSE`
+
+	sqlGetOSTreeRefs = `
+		SELECT ostree_commits.ref, COUNT(*), MAX(ostree_commits.created_at)
+		FROM ostree_commits
+		JOIN composes ON composes.job_id = ostree_commits.compose_id
+		WHERE ostree_commits.org_id=$1 AND composes.deleted=FALSE
+		GROUP BY ostree_commits.ref
+		ORDER BY ostree_commits.ref
+		LIMIT $2 OFFSET $3`
+
+	sqlCountOSTreeRefs = `
+		SELECT COUNT(DISTINCT ostree_commits.ref)
+		FROM ostree_commits
+		JOIN composes ON composes.job_id = ostree_commits.compose_id
+		WHERE ostree_commits.org_id=$1 AND composes.deleted=FALSE`
+
+	sqlInsertSubscriptionProfile = `
+		INSERT INTO subscription_profiles(id, org_id, name, organization, activation_key, server_url, base_url,
+			insights, rhc, created_at, updated_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`
+
+	sqlGetSubscriptionProfiles = `
+		SELECT id, name, organization, activation_key, server_url, base_url, insights, rhc, created_at, updated_at
+		FROM subscription_profiles
+		WHERE org_id=$1
+		ORDER BY name
+		LIMIT $2 OFFSET $3`
+
+	sqlCountSubscriptionProfiles = `
+		SELECT COUNT(*)
+		FROM subscription_profiles
+		WHERE org_id=$1`
+
+	sqlGetSubscriptionProfile = `
+		SELECT id, name, organization, activation_key, server_url, base_url, insights, rhc, created_at, updated_at
+		FROM subscription_profiles
+		WHERE id=$1 AND org_id=$2`
+
+	// the activation key is kept when the update doesn't carry one
+	sqlUpdateSubscriptionProfile = `
+		UPDATE subscription_profiles
+		SET name=$3, organization=$4, activation_key=COALESCE($5, activation_key), server_url=$6,
+			base_url=$7, insights=$8, rhc=$9, updated_at=CURRENT_TIMESTAMP
+		WHERE id=$1 AND org_id=$2`
+
+	sqlDeleteSubscriptionProfile = `
+		DELETE FROM subscription_profiles
+		WHERE id=$1 AND org_id=$2`
+
+	sqlInsertAuditEntry = `
+		INSERT INTO audit(id, org_id, account_number, username, action, target_id, request_hash, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)`
+
+	sqlGetAuditEntries = `
+		SELECT id, org_id, account_number, username, action, target_id, request_hash, created_at
+		FROM audit
+		WHERE org_id=$1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	sqlCountAuditEntries = `
+		SELECT COUNT(*)
+		FROM audit
+		WHERE org_id=$1`
 )
 
 func InitDBConnectionPool(connStr string) (DB, error) {
@@ -128,7 +391,7 @@ func InitDBConnectionPool(connStr string) (DB, error) {
 	return &dB{pool}, nil
 }
 
-func (db *dB) InsertCompose(jobId uuid.UUID, accountNumber, orgId string, imageName *string, request json.RawMessage) error {
+func (db *dB) InsertCompose(jobId uuid.UUID, accountNumber, orgId string, imageName *string, request json.RawMessage, backend string) error {
 	ctx := context.Background()
 	conn, err := db.Pool.Acquire(ctx)
 	if err != nil {
@@ -136,7 +399,7 @@ func (db *dB) InsertCompose(jobId uuid.UUID, accountNumber, orgId string, imageN
 	}
 	defer conn.Release()
 
-	_, err = conn.Exec(ctx, sqlInsertCompose, jobId, request, accountNumber, orgId, imageName)
+	_, err = conn.Exec(ctx, sqlInsertCompose, jobId, request, accountNumber, orgId, imageName, backend)
 	return err
 }
 
@@ -151,7 +414,7 @@ func (db *dB) GetCompose(jobId uuid.UUID, orgId string) (*ComposeEntry, error) {
 	result := conn.QueryRow(ctx, sqlGetCompose, orgId, jobId)
 
 	var compose ComposeEntry
-	err = result.Scan(&compose.Id, &compose.Request, &compose.CreatedAt, &compose.ImageName)
+	err = result.Scan(&compose.Id, &compose.Request, &compose.CreatedAt, &compose.ImageName, &compose.Backend)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ComposeNotFoundError
@@ -186,7 +449,146 @@ func (db *dB) GetComposeImageType(jobId uuid.UUID, orgId string) (string, error)
 	return imageType, nil
 }
 
-func (db *dB) GetComposes(orgId string, since time.Duration, limit, offset int) ([]ComposeEntry, int, error) {
+// where returns the WHERE clause selecting the non-deleted composes of orgId
+// matching the filter, along with its arguments.
+func (f ComposesFilter) where(orgId string) (string, []interface{}) {
+	conds := []string{"org_id=$1", "deleted=FALSE"}
+	args := []interface{}{orgId}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.MaxAge > 0 {
+		add("CURRENT_TIMESTAMP - created_at <= $%d", f.MaxAge)
+	}
+	if f.Since != nil {
+		add("created_at >= $%d::timestamptz", *f.Since)
+	}
+	if f.Until != nil {
+		add("created_at < $%d::timestamptz", *f.Until)
+	}
+	if f.Distribution != nil {
+		add("request->>'distribution' = $%d", *f.Distribution)
+	}
+	if len(f.ImageTypes) > 0 {
+		add("request->'image_requests'->0->>'image_type' = ANY($%d)", f.ImageTypes)
+	}
+	if f.Architecture != nil {
+		add("request->'image_requests'->0->>'architecture' = $%d", *f.Architecture)
+	}
+	if f.UploadType != nil {
+		add("request->'image_requests'->0->'upload_request'->>'type' = $%d", *f.UploadType)
+	}
+	if f.ImageName != nil {
+		add("image_name ILIKE $%d", "%"+escapeLike(*f.ImageName)+"%")
+	}
+	if f.Status != nil && *f.Status == ComposeStatusUnknown {
+		conds = append(conds, "status IS NULL")
+	} else if f.Status != nil {
+		add("status = $%d", *f.Status)
+	}
+	for _, l := range f.Labels {
+		not := ""
+		if l.Negate {
+			not = "NOT "
+		}
+		if l.Value != nil {
+			add(not+"labels @> $%d", map[string]string{l.Key: *l.Value})
+		} else {
+			add(not+"labels ? $%d", l.Key)
+		}
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// sortKey is an expression the rows of a listing are ordered by.
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+// sortKeys returns the keys composes are ordered by, along with the values of
+// those keys for the keyset. The image name can be NULL, which doesn't compare,
+// so it's split into whether it's NULL and its value.
+func (f ComposesFilter) sortKeys(k Keyset) ([]sortKey, []interface{}) {
+	switch f.Sort {
+	case SortCreatedAtAsc:
+		return []sortKey{{"created_at", false}, {"job_id", false}},
+			[]interface{}{k.CreatedAt, k.Id}
+	case SortImageNameAsc, SortImageNameDesc:
+		imageName := ""
+		if k.ImageName != nil {
+			imageName = *k.ImageName
+		}
+		return []sortKey{
+				{"(image_name IS NULL)", false},
+				{"COALESCE(image_name, '')", f.Sort == SortImageNameDesc},
+				{"created_at", true},
+				{"job_id", true},
+			},
+			[]interface{}{k.ImageName == nil, imageName, k.CreatedAt, k.Id}
+	default:
+		return []sortKey{{"created_at", true}, {"job_id", true}},
+			[]interface{}{k.CreatedAt, k.Id}
+	}
+}
+
+// keysetOrderBy returns the ORDER BY clause for the keys, reversed when paging
+// backwards.
+func keysetOrderBy(keys []sortKey, before bool) string {
+	var order []string
+	for _, k := range keys {
+		if k.desc != before {
+			order = append(order, k.expr+" DESC")
+		} else {
+			order = append(order, k.expr+" ASC")
+		}
+	}
+	return strings.Join(order, ", ")
+}
+
+// keysetWhere returns the condition selecting the rows following (or
+// preceding) the keyset in the order of the keys. The values of the keyset
+// are appended to args.
+func keysetWhere(keys []sortKey, values []interface{}, before bool, args []interface{}) (string, []interface{}) {
+	first := len(args) + 1
+	args = append(args, values...)
+
+	var ors []string
+	for i, k := range keys {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = $%d", keys[j].expr, first+j))
+		}
+		op := ">"
+		if k.desc != before {
+			op = "<"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s $%d", k.expr, op, first+i))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// reverse reverses a slice of length n, rows fetched backwards are returned in
+// the order of the listing.
+func reverse(n int, swap func(i, j int)) {
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// GetComposes returns a page of the composes matching the filter. If keyset is
+// set, the page starts right after (or ends right before) it and offset is
+// ignored. The count is the number of matching composes, regardless of paging.
+func (db *dB) GetComposes(orgId string, filter ComposesFilter, keyset *Keyset, limit, offset int) ([]ComposeEntry, int, error) {
 	ctx := context.Background()
 	conn, err := db.Pool.Acquire(ctx)
 	if err != nil {
@@ -194,10 +596,26 @@ func (db *dB) GetComposes(orgId string, since time.Duration, limit, offset int)
 	}
 	defer conn.Release()
 
-	result, err := conn.Query(ctx, sqlGetComposes, orgId, since, limit, offset)
+	where, countArgs := filter.where(orgId)
+	args := countArgs
+	pageWhere := where
+	before := false
+	keys, _ := filter.sortKeys(Keyset{})
+	if keyset != nil {
+		var values []interface{}
+		keys, values = filter.sortKeys(*keyset)
+		before = keyset.Before
+		offset = 0
+		var cond string
+		cond, args = keysetWhere(keys, values, before, append([]interface{}{}, countArgs...))
+		pageWhere = where + " AND " + cond
+	}
+	query := fmt.Sprintf(sqlGetComposes, pageWhere, keysetOrderBy(keys, before), len(args)+1, len(args)+2)
+	result, err := conn.Query(ctx, query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
+	defer result.Close()
 
 	var composes []ComposeEntry
 	for result.Next() {
@@ -205,7 +623,8 @@ func (db *dB) GetComposes(orgId string, since time.Duration, limit, offset int)
 		var request json.RawMessage
 		var createdAt time.Time
 		var imageName *string
-		err = result.Scan(&jobId, &request, &createdAt, &imageName)
+		var backend string
+		err = result.Scan(&jobId, &request, &createdAt, &imageName, &backend)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -214,14 +633,18 @@ func (db *dB) GetComposes(orgId string, since time.Duration, limit, offset int)
 			request,
 			createdAt,
 			imageName,
+			backend,
 		})
 	}
 	if err = result.Err(); err != nil {
 		return nil, 0, err
 	}
+	if before {
+		reverse(len(composes), func(i, j int) { composes[i], composes[j] = composes[j], composes[i] })
+	}
 
 	var count int
-	err = conn.QueryRow(ctx, sqlCountActiveComposesSince, orgId, since).Scan(&count)
+	err = conn.QueryRow(ctx, fmt.Sprintf(sqlCountComposes, where), countArgs...).Scan(&count)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -248,6 +671,20 @@ func (db *dB) CountComposesSince(orgId string, duration time.Duration) (int, err
 	return count, nil
 }
 
+// UpdateComposeStatus stores the last known status of a compose, so composes
+// can be filtered by it.
+func (db *dB) UpdateComposeStatus(jobId uuid.UUID, status string) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sqlUpdateComposeStatus, jobId, status)
+	return err
+}
+
 func (db *dB) DeleteCompose(jobId uuid.UUID, orgId string) error {
 	ctx := context.Background()
 	conn, err := db.Pool.Acquire(ctx)
@@ -264,7 +701,66 @@ func (db *dB) DeleteCompose(jobId uuid.UUID, orgId string) error {
 	return err
 }
 
-func (db *dB) InsertClone(composeId, cloneId uuid.UUID, request json.RawMessage) error {
+// RestoreCompose undoes DeleteCompose, as long as the compose was deleted less
+// than gracePeriod ago.
+func (db *dB) RestoreCompose(jobId uuid.UUID, orgId string, gracePeriod time.Duration) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sqlRestoreCompose, orgId, jobId, gracePeriod)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return ComposeNotFoundError
+	}
+
+	return nil
+}
+
+// PurgeDeletedComposes removes composes which were deleted more than
+// deletedFor ago, their clones are removed along with them. Returns the number
+// of removed composes.
+func (db *dB) PurgeDeletedComposes(deletedFor time.Duration) (int64, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sqlPurgeDeletedComposes, deletedFor)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeComposesOlderThan removes all composes created more than age ago,
+// deleted or not, their clones are removed along with them. Returns the number
+// of removed composes.
+func (db *dB) PurgeComposesOlderThan(age time.Duration) (int64, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sqlPurgeComposesOlderThan, age)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// InsertClone stores a clone, batchId is set for the clones made by one bulk
+// clone request.
+func (db *dB) InsertClone(composeId, cloneId uuid.UUID, request json.RawMessage, batchId *uuid.UUID) error {
 	ctx := context.Background()
 	conn, err := db.Pool.Acquire(ctx)
 	if err != nil {
@@ -272,11 +768,13 @@ func (db *dB) InsertClone(composeId, cloneId uuid.UUID, request json.RawMessage)
 	}
 	defer conn.Release()
 
-	_, err = conn.Exec(ctx, sqlInsertClone, cloneId, composeId, request)
+	_, err = conn.Exec(ctx, sqlInsertClone, cloneId, composeId, request, batchId)
 	return err
 }
 
-func (db *dB) GetClonesForCompose(composeId uuid.UUID, orgId string, limit, offset int) ([]CloneEntry, int, error) {
+// GetClonesForCompose returns a page of the clones of a compose, most recent
+// first. Paging works like GetComposes.
+func (db *dB) GetClonesForCompose(composeId uuid.UUID, orgId string, keyset *Keyset, limit, offset int) ([]CloneEntry, int, error) {
 	ctx := context.Background()
 	conn, err := db.Pool.Acquire(ctx)
 	if err != nil {
@@ -284,7 +782,17 @@ func (db *dB) GetClonesForCompose(composeId uuid.UUID, orgId string, limit, offs
 	}
 	defer conn.Release()
 
-	rows, err := conn.Query(ctx, sqlGetClonesForCompose, composeId, orgId, limit, offset)
+	keys := []sortKey{{"clones.created_at", true}, {"clones.id", true}}
+	args := []interface{}{composeId, orgId}
+	where := "TRUE"
+	before := false
+	if keyset != nil {
+		before = keyset.Before
+		offset = 0
+		where, args = keysetWhere(keys, []interface{}{keyset.CreatedAt, keyset.Id}, before, args)
+	}
+	query := fmt.Sprintf(sqlGetClonesForCompose, where, keysetOrderBy(keys, before), len(args)+1, len(args)+2)
+	rows, err := conn.Query(ctx, query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -295,7 +803,8 @@ func (db *dB) GetClonesForCompose(composeId uuid.UUID, orgId string, limit, offs
 		var id uuid.UUID
 		var request json.RawMessage
 		var createdAt time.Time
-		err = rows.Scan(&id, &request, &createdAt)
+		var backend string
+		err = rows.Scan(&id, &request, &createdAt, &backend)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -303,11 +812,15 @@ func (db *dB) GetClonesForCompose(composeId uuid.UUID, orgId string, limit, offs
 			id,
 			request,
 			createdAt,
+			backend,
 		})
 	}
 	if err = rows.Err(); err != nil {
 		return nil, 0, err
 	}
+	if before {
+		reverse(len(clones), func(i, j int) { clones[i], clones[j] = clones[j], clones[i] })
+	}
 
 	var count int
 	err = conn.QueryRow(ctx, sqlCountClonesForCompose, composeId, orgId).Scan(&count)
@@ -328,7 +841,7 @@ func (db *dB) GetClone(id uuid.UUID, orgId string) (*CloneEntry, error) {
 	defer conn.Release()
 
 	var clone CloneEntry
-	err = conn.QueryRow(ctx, sqlGetClone, id, orgId).Scan(&clone.Id, &clone.Request, &clone.CreatedAt)
+	err = conn.QueryRow(ctx, sqlGetClone, id, orgId).Scan(&clone.Id, &clone.Request, &clone.CreatedAt, &clone.Backend)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, CloneNotFoundError
@@ -339,3 +852,309 @@ func (db *dB) GetClone(id uuid.UUID, orgId string) (*CloneEntry, error) {
 
 	return &clone, nil
 }
+
+// GetCloneBatch returns the clones of a batch in the order they were made.
+func (db *dB) GetCloneBatch(batchId uuid.UUID, orgId string) ([]CloneEntry, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sqlGetCloneBatch, batchId, orgId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clones []CloneEntry
+	for rows.Next() {
+		var clone CloneEntry
+		err = rows.Scan(&clone.Id, &clone.Request, &clone.CreatedAt, &clone.Backend)
+		if err != nil {
+			return nil, err
+		}
+		clones = append(clones, clone)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(clones) == 0 {
+		return nil, CloneBatchNotFoundError
+	}
+
+	return clones, nil
+}
+
+func (db *dB) InsertAuditEntry(orgId, accountNumber, username, action string, targetId uuid.UUID, requestHash string) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sqlInsertAuditEntry, uuid.New(), orgId, accountNumber, username, action, targetId, requestHash)
+	return err
+}
+
+func (db *dB) GetAuditEntries(orgId string, limit, offset int) ([]AuditEntry, int, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sqlGetAuditEntries, orgId, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		err = rows.Scan(&entry.Id, &entry.OrgId, &entry.AccountNumber, &entry.Username, &entry.Action, &entry.TargetId, &entry.RequestHash, &entry.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var count int
+	err = conn.QueryRow(ctx, sqlCountAuditEntries, orgId).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, count, nil
+}
+
+// InsertOSTreeCommit adds an edge commit compose to the lineage of its ref
+func (db *dB) InsertOSTreeCommit(composeId uuid.UUID, orgId, ref string, url, parent *string, parentComposeId *uuid.UUID) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sqlInsertOSTreeCommit, composeId, orgId, ref, url, parent, parentComposeId)
+	return err
+}
+
+// UpdateOSTreeCommit stores the checksum of the commit built by a compose
+func (db *dB) UpdateOSTreeCommit(composeId uuid.UUID, commit string) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sqlUpdateOSTreeCommit, composeId, commit)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return OSTreeCommitNotFoundError
+	}
+	return nil
+}
+
+// GetOSTreeCommits returns the lineage of a ref, the most recent commit first
+func (db *dB) GetOSTreeCommits(orgId, ref string, limit, offset int) ([]OSTreeCommitEntry, int, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sqlGetOSTreeCommits, orgId, ref, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []OSTreeCommitEntry
+	for rows.Next() {
+		var entry OSTreeCommitEntry
+		err = rows.Scan(&entry.ComposeId, &entry.Ref, &entry.Url, &entry.Parent, &entry.ParentComposeId, &entry.Commit, &entry.Status, &entry.Backend, &entry.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var count int
+	err = conn.QueryRow(ctx, sqlCountOSTreeCommits, orgId, ref).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, count, nil
+}
+
+// GetOSTreeRefs returns the refs having a lineage, sorted by name
+func (db *dB) GetOSTreeRefs(orgId string, limit, offset int) ([]OSTreeRefEntry, int, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sqlGetOSTreeRefs, orgId, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []OSTreeRefEntry
+	for rows.Next() {
+		var entry OSTreeRefEntry
+		err = rows.Scan(&entry.Ref, &entry.Commits, &entry.LastCommitAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var count int
+	err = conn.QueryRow(ctx, sqlCountOSTreeRefs, orgId).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, count, nil
+}
+
+// subscriptionProfileError maps the violation of the unique name of the
+// profiles of an organization
+func subscriptionProfileError(err error) error {
+	var pgErr *pgconn.PgError
+	// 23505 is unique_violation
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return SubscriptionProfileExistsError
+	}
+	return err
+}
+
+func (db *dB) InsertSubscriptionProfile(orgId string, p SubscriptionProfileEntry) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, sqlInsertSubscriptionProfile, p.Id, orgId, p.Name, p.Organization, p.ActivationKey, p.ServerUrl, p.BaseUrl, p.Insights, p.Rhc)
+	return subscriptionProfileError(err)
+}
+
+// GetSubscriptionProfiles returns the subscription profiles of the
+// organization, sorted by name
+func (db *dB) GetSubscriptionProfiles(orgId string, limit, offset int) ([]SubscriptionProfileEntry, int, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, sqlGetSubscriptionProfiles, orgId, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []SubscriptionProfileEntry
+	for rows.Next() {
+		var entry SubscriptionProfileEntry
+		err = rows.Scan(&entry.Id, &entry.Name, &entry.Organization, &entry.ActivationKey, &entry.ServerUrl, &entry.BaseUrl, &entry.Insights, &entry.Rhc, &entry.CreatedAt, &entry.UpdatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var count int
+	err = conn.QueryRow(ctx, sqlCountSubscriptionProfiles, orgId).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, count, nil
+}
+
+func (db *dB) GetSubscriptionProfile(id uuid.UUID, orgId string) (*SubscriptionProfileEntry, error) {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	var entry SubscriptionProfileEntry
+	err = conn.QueryRow(ctx, sqlGetSubscriptionProfile, id, orgId).Scan(&entry.Id, &entry.Name, &entry.Organization, &entry.ActivationKey, &entry.ServerUrl, &entry.BaseUrl, &entry.Insights, &entry.Rhc, &entry.CreatedAt, &entry.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, SubscriptionProfileNotFoundError
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// UpdateSubscriptionProfile replaces the profile, keeping the activation key
+// when the entry has none
+func (db *dB) UpdateSubscriptionProfile(orgId string, p SubscriptionProfileEntry) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sqlUpdateSubscriptionProfile, p.Id, orgId, p.Name, p.Organization, p.ActivationKey, p.ServerUrl, p.BaseUrl, p.Insights, p.Rhc)
+	if err != nil {
+		return subscriptionProfileError(err)
+	}
+	if tag.RowsAffected() != 1 {
+		return SubscriptionProfileNotFoundError
+	}
+	return nil
+}
+
+func (db *dB) DeleteSubscriptionProfile(id uuid.UUID, orgId string) error {
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, sqlDeleteSubscriptionProfile, id, orgId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return SubscriptionProfileNotFoundError
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS audit(
+       id uuid PRIMARY KEY,
+       org_id varchar NOT NULL,
+       account_number varchar NOT NULL,
+       username varchar NOT NULL,
+       action varchar NOT NULL,
+       target_id uuid NOT NULL,
+       request_hash varchar NOT NULL,
+       created_at timestamp NOT NULL,
+
+       CONSTRAINT org_id_constraint CHECK (org_id NOT SIMILAR TO '[ ]*')
+);
+
+CREATE INDEX IF NOT EXISTS audit_org_id_created_at_idx ON audit(org_id, created_at DESC);
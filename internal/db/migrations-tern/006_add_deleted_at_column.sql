@@ -0,0 +1,2 @@
+ALTER TABLE composes ADD deleted_at timestamp;
+UPDATE composes SET deleted_at = CURRENT_TIMESTAMP WHERE deleted = TRUE;
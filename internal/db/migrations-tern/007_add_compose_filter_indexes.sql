@@ -0,0 +1,9 @@
+-- the status is only known once polled, new composes start out unknown
+ALTER TABLE composes ADD status varchar;
+
+CREATE INDEX IF NOT EXISTS composes_org_id_created_at_idx ON composes(org_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS composes_org_id_status_idx ON composes(org_id, status);
+CREATE INDEX IF NOT EXISTS composes_org_id_distribution_idx ON composes(org_id, (request->>'distribution'));
+CREATE INDEX IF NOT EXISTS composes_org_id_image_type_idx ON composes(org_id, (request->'image_requests'->0->>'image_type'));
+CREATE INDEX IF NOT EXISTS composes_org_id_architecture_idx ON composes(org_id, (request->'image_requests'->0->>'architecture'));
+CREATE INDEX IF NOT EXISTS composes_org_id_upload_type_idx ON composes(org_id, (request->'image_requests'->0->'upload_request'->>'type'));
@@ -0,0 +1,5 @@
+ALTER TABLE composes ADD labels jsonb NOT NULL DEFAULT '{}';
+
+UPDATE composes SET labels = request->'labels' WHERE jsonb_typeof(request->'labels') = 'object';
+
+CREATE INDEX IF NOT EXISTS composes_labels_idx ON composes USING GIN (labels);
@@ -0,0 +1 @@
+ALTER TABLE composes ADD backend varchar NOT NULL DEFAULT 'default';
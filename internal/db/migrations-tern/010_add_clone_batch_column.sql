@@ -0,0 +1,3 @@
+ALTER TABLE clones ADD batch_id uuid;
+
+CREATE INDEX IF NOT EXISTS clones_batch_id_idx ON clones(batch_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS ostree_commits(
+       compose_id uuid PRIMARY KEY REFERENCES composes(job_id) ON DELETE CASCADE,
+       org_id varchar NOT NULL,
+       ref varchar NOT NULL,
+       url varchar,
+       parent varchar,
+       parent_compose_id uuid,
+       commit_id varchar,
+       created_at timestamp NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ostree_commits_org_id_ref_idx ON ostree_commits(org_id, ref, created_at DESC);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS subscription_profiles(
+       id uuid PRIMARY KEY,
+       org_id varchar NOT NULL,
+       name varchar NOT NULL,
+       organization bigint NOT NULL,
+       activation_key bytea NOT NULL,
+       server_url varchar NOT NULL,
+       base_url varchar NOT NULL,
+       insights boolean NOT NULL,
+       rhc boolean NOT NULL,
+       created_at timestamp NOT NULL,
+       updated_at timestamp NOT NULL,
+
+       CONSTRAINT subscription_profiles_org_id_name_key UNIQUE (org_id, name),
+       CONSTRAINT org_id_constraint CHECK (org_id NOT SIMILAR TO '[ ]*')
+);
+
+-- activation keys embedded in compose requests are no longer stored
+UPDATE composes
+SET request = jsonb_set(request, '{customizations,subscription,activation-key}', '"<REDACTED>"')
+WHERE request #> '{customizations,subscription,activation-key}' IS NOT NULL;
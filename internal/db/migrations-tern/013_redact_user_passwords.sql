@@ -0,0 +1,11 @@
+-- password hashes of users embedded in compose requests are no longer stored
+UPDATE composes
+SET request = jsonb_set(request, '{customizations,users}', (
+    SELECT jsonb_agg(CASE WHEN u ? 'password' AND u->'password' <> 'null' THEN jsonb_set(u, '{password}', '"<REDACTED>"') ELSE u END)
+    FROM jsonb_array_elements(request #> '{customizations,users}') AS u
+))
+WHERE jsonb_typeof(request #> '{customizations,users}') = 'array'
+AND EXISTS (
+    SELECT 1 FROM jsonb_array_elements(request #> '{customizations,users}') AS u
+    WHERE u ? 'password'
+);
@@ -14,6 +14,7 @@ import (
 
 var DistributionNotFound = errors.New("Distribution not available")
 var RepoSourceError = errors.New("Repository must always have one of these properties: baseurl, metalink")
+var OscapProfileNotFound = errors.New("OpenSCAP profile not available")
 
 type DistributionItem struct {
 	Description      string  `json:"description"`
@@ -29,10 +30,14 @@ type DistributionItem struct {
 }
 
 type DistributionFile struct {
-	ModulePlatformID string           `json:"module_platform_id"`
-	Distribution     DistributionItem `json:"distribution"`
-	ArchX86          *Architecture    `json:"x86_64,omitempty"`
-	Aarch64          *Architecture    `json:"aarch64,omitempty"`
+	ModulePlatformID string               `json:"module_platform_id"`
+	Distribution     DistributionItem     `json:"distribution"`
+	Customizations   CustomizationSupport `json:"customizations"`
+	ArchX86          *Architecture        `json:"x86_64,omitempty"`
+	Aarch64          *Architecture        `json:"aarch64,omitempty"`
+
+	// not part of distro.json, loaded dynamically in ReadDistribution
+	OscapProfiles []OscapProfile `json:"-"`
 }
 
 type Architecture struct {
@@ -56,6 +61,27 @@ type Package struct {
 	Summary string `json:"summary"`
 }
 
+// CustomizationSupport lists the image types which support the customizations
+// not every distribution can apply
+type CustomizationSupport struct {
+	Fips []string `json:"fips"`
+}
+
+// OscapProfile is a profile of the SCAP security guide of the distribution,
+// along with the packages and services the profile's remediations need
+type OscapProfile struct {
+	Id          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Packages    []string      `json:"packages"`
+	Services    OscapServices `json:"services"`
+}
+
+type OscapServices struct {
+	Enabled  []string `json:"enabled"`
+	Disabled []string `json:"disabled"`
+}
+
 type PackagesFile struct {
 	Data []Package `json:"data"`
 }
@@ -92,6 +118,28 @@ func (dist DistributionFile) Architecture(arch string) (*Architecture, error) {
 	}
 }
 
+func (dist DistributionFile) SupportsFips(imageType string) bool {
+	return contains(dist.Customizations.Fips, imageType)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (dist DistributionFile) OscapProfile(id string) (*OscapProfile, error) {
+	for i := range dist.OscapProfiles {
+		if dist.OscapProfiles[i].Id == id {
+			return &dist.OscapProfiles[i], nil
+		}
+	}
+	return nil, OscapProfileNotFound
+}
+
 func (arch Architecture) FindPackages(search string) []Package {
 	if arch.Packages == nil {
 		return nil
@@ -206,6 +254,7 @@ func readDistribution(distsDir, distroIn string) (d DistributionFile, err error)
 		d.Aarch64.Packages = aarch64
 	}
 
+	d.OscapProfiles, err = readOscapProfiles(distsDir, distroIn)
 	return
 }
 
@@ -232,3 +281,32 @@ func readPackages(repos []Repository, archName, distsDir, distroIn string) (map[
 
 	return pkgs, nil
 }
+
+// readOscapProfiles reads the profile catalogue of the distribution, the
+// distributions without the SCAP security guide have none
+func readOscapProfiles(distsDir, distroIn string) ([]OscapProfile, error) {
+	p, err := filepath.EvalSymlinks(filepath.Join(distsDir, distroIn))
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Clean(filepath.Join(distsDir, distroIn, fmt.Sprintf("%s-oscap-profiles.json", filepath.Base(p)))))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("Error closing file: %v", err)
+		}
+	}()
+
+	var profiles []OscapProfile
+	err = json.NewDecoder(f).Decode(&profiles)
+	if err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
@@ -137,6 +137,42 @@ func TestArchitecture_FindPackages(t *testing.T) {
 
 }
 
+func TestDistributionFile_OscapProfile(t *testing.T) {
+	adr, err := LoadDistroRegistry("../../distributions")
+	require.NoError(t, err)
+	d, err := adr.Available(true).Get("rhel-8")
+	require.NoError(t, err)
+	require.NotEmpty(t, d.OscapProfiles)
+
+	profile, err := d.OscapProfile("xccdf_org.ssgproject.content_profile_stig")
+	require.NoError(t, err)
+	require.Equal(t, "DISA STIG for Red Hat Enterprise Linux 8", profile.Name)
+	require.Contains(t, profile.Packages, "aide")
+	require.Contains(t, profile.Services.Enabled, "auditd")
+
+	_, err = d.OscapProfile("xccdf_org.ssgproject.content_profile_none")
+	require.Equal(t, OscapProfileNotFound, err)
+
+	// distributions without the SCAP security guide have no profiles
+	d, err = adr.Available(true).Get("fedora-38")
+	require.NoError(t, err)
+	require.Empty(t, d.OscapProfiles)
+}
+
+func TestDistributionFile_SupportsCustomizations(t *testing.T) {
+	adr, err := LoadDistroRegistry("../../distributions")
+	require.NoError(t, err)
+	d, err := adr.Available(true).Get("rhel-92")
+	require.NoError(t, err)
+
+	require.True(t, d.SupportsFips("aws"))
+	require.False(t, d.SupportsFips("edge-commit"))
+
+	d, err = adr.Available(true).Get("fedora-38")
+	require.NoError(t, err)
+	require.False(t, d.SupportsFips("aws"))
+}
+
 func TestInvalidDistribution(t *testing.T) {
 	_, err := readDistribution("../../distributions", "none")
 	require.Error(t, err, DistributionNotFound)
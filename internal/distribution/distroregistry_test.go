@@ -36,9 +36,10 @@ func TestDistroRegistry_Get(t *testing.T) {
 	require.Equal(t, "rhel-86", result.Distribution.Name)
 	require.Nil(t, err)
 
-	// don't test packages, they are huge
+	// don't test packages and profiles, they are huge
 	result.ArchX86.Packages = nil
 	result.Aarch64.Packages = nil
+	result.OscapProfiles = nil
 
 	require.Equal(t, &DistributionFile{
 		ModulePlatformID: "platform:el8",
@@ -47,6 +48,9 @@ func TestDistroRegistry_Get(t *testing.T) {
 			Name:             "rhel-86",
 			RestrictedAccess: false,
 		},
+		Customizations: CustomizationSupport{
+			Fips: []string{"aws", "gcp", "azure", "guest-image", "image-installer", "vsphere"},
+		},
 		ArchX86: &Architecture{
 			ImageTypes: []string{"aws", "gcp", "azure", "rhel-edge-commit", "rhel-edge-installer", "edge-commit", "edge-installer", "guest-image", "image-installer", "vsphere"},
 			Repositories: []Repository{
@@ -0,0 +1,124 @@
+// Package gpgkey checks the GPG keys of repositories ahead of submitting them
+// to composer, which would otherwise only fail the compose once it depsolves.
+package gpgkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/osbuild/image-builder/internal/publichttp"
+
+	// The successor of openpgp isn't vendored, and parsing armored public
+	// keys is all that's needed from it
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck
+)
+
+const (
+	// Deadline of fetching a single key
+	fetchTimeout = 10 * time.Second
+
+	// MaxKeySize bounds the size of a fetched key, keys with many
+	// signatures are a few tens of kilobytes
+	MaxKeySize = 1024 * 1024
+
+	armorStart = "-----BEGIN PGP "
+)
+
+var InvalidKeyError = errors.New("not an armored PGP public key")
+
+// IsURL tells whether the key refers to an http or https url to fetch it
+// from, rather than holding the key itself
+func IsURL(key string) bool {
+	u, err := url.Parse(key)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Validate checks that the key holds one or more armored PGP public key
+// blocks, and nothing else
+func Validate(key string) error {
+	start := strings.Index(key, armorStart)
+	if start == -1 || strings.TrimSpace(key[:start]) != "" {
+		return InvalidKeyError
+	}
+
+	blocks := strings.Split(key[start:], armorStart)
+	for _, b := range blocks[1:] {
+		block, err := armor.Decode(strings.NewReader(armorStart + b))
+		if err != nil || block.Type != openpgp.PublicKeyType {
+			return InvalidKeyError
+		}
+		entities, err := openpgp.ReadKeyRing(block.Body)
+		if err != nil {
+			return fmt.Errorf("%w: %v", InvalidKeyError, err)
+		}
+		if len(entities) == 0 {
+			return InvalidKeyError
+		}
+		for _, e := range entities {
+			if e.PrivateKey != nil {
+				return InvalidKeyError
+			}
+		}
+	}
+	return nil
+}
+
+// Fetcher downloads the keys repositories refer to by url, so they can be
+// inlined in the compose request.
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher returns a fetcher using the client, or when nil a client which
+// only reaches public addresses, as the urls come from users
+func NewFetcher(client *http.Client) *Fetcher {
+	if client == nil {
+		client = publichttp.NewClient(fetchTimeout)
+	}
+	return &Fetcher{
+		client: client,
+	}
+}
+
+// Fetch downloads and validates the key at the url
+func (f *Fetcher) Fetch(ctx context.Context, keyURL string) (string, error) {
+	if !IsURL(keyURL) {
+		return "", fmt.Errorf("Invalid gpg key url %q, expected an http or https url", keyURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keyURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Fetching %s returned status %d", keyURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxKeySize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > MaxKeySize {
+		return "", fmt.Errorf("Key at %s is larger than %d bytes", keyURL, MaxKeySize)
+	}
+
+	key := string(body)
+	err = Validate(key)
+	if err != nil {
+		return "", fmt.Errorf("Key at %s: %w", keyURL, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,130 @@
+package gpgkey
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck
+
+	"github.com/osbuild/image-builder/internal/publichttp"
+)
+
+func newEntity(t *testing.T) *openpgp.Entity {
+	e, err := openpgp.NewEntity("Repository", "", "repo@example.com", nil)
+	require.NoError(t, err)
+	return e
+}
+
+func armored(t *testing.T, blockType string, serialize func(*bytes.Buffer) error) string {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, blockType, nil)
+	require.NoError(t, err)
+	var body bytes.Buffer
+	require.NoError(t, serialize(&body))
+	_, err = w.Write(body.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String() + "\n"
+}
+
+// publicKey returns an armored public key, as repositories publish them
+func publicKey(t *testing.T) string {
+	e := newEntity(t)
+	return armored(t, openpgp.PublicKeyType, func(b *bytes.Buffer) error {
+		return e.Serialize(b)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	key := publicKey(t)
+	require.NoError(t, Validate(key))
+	require.NoError(t, Validate("\n"+key))
+	// several keys can be concatenated
+	require.NoError(t, Validate(key+publicKey(t)))
+
+	e := newEntity(t)
+	private := armored(t, openpgp.PrivateKeyType, func(b *bytes.Buffer) error {
+		return e.SerializePrivate(b, nil)
+	})
+	// the private key smuggled in a public key block
+	disguised := armored(t, openpgp.PublicKeyType, func(b *bytes.Buffer) error {
+		return e.SerializePrivate(b, nil)
+	})
+	signature := armored(t, "PGP SIGNATURE", func(b *bytes.Buffer) error {
+		return openpgp.DetachSign(b, e, strings.NewReader("data"), nil)
+	})
+	truncated := key[:len(key)/2]
+	corrupted := strings.Replace(key, "\n\n", "\n\nAAAA", 1)
+
+	for name, invalid := range map[string]string{
+		"empty":         "",
+		"text":          "not a key",
+		"leading text":  "key: " + key,
+		"private key":   private,
+		"disguised":     disguised,
+		"signature":     signature,
+		"truncated":     truncated,
+		"corrupted":     corrupted,
+		"second broken": key + truncated,
+		"empty block":   "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\n-----END PGP PUBLIC KEY BLOCK-----\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, Validate(invalid), InvalidKeyError)
+		})
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	require.True(t, IsURL("https://example.com/RPM-GPG-KEY"))
+	require.True(t, IsURL("http://example.com/RPM-GPG-KEY"))
+	require.False(t, IsURL("file:///etc/pki/rpm-gpg/RPM-GPG-KEY-redhat-release"))
+	require.False(t, IsURL("https:///RPM-GPG-KEY"))
+	require.False(t, IsURL("-----BEGIN PGP PUBLIC KEY BLOCK-----"))
+}
+
+func TestFetch(t *testing.T) {
+	key := publicKey(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/key":
+			_, _ = w.Write([]byte(key))
+		case "/invalid":
+			_, _ = w.Write([]byte("<html>not found</html>"))
+		case "/large":
+			_, _ = w.Write([]byte(key))
+			_, _ = w.Write(bytes.Repeat([]byte("\n"), MaxKeySize))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	f := NewFetcher(srv.Client())
+	ctx := context.Background()
+
+	fetched, err := f.Fetch(ctx, srv.URL+"/key")
+	require.NoError(t, err)
+	require.Equal(t, key, fetched)
+
+	_, err = f.Fetch(ctx, srv.URL+"/invalid")
+	require.ErrorIs(t, err, InvalidKeyError)
+
+	_, err = f.Fetch(ctx, srv.URL+"/large")
+	require.ErrorContains(t, err, "larger than")
+
+	_, err = f.Fetch(ctx, srv.URL+"/missing")
+	require.ErrorContains(t, err, "status 404")
+
+	_, err = f.Fetch(ctx, "file:///etc/passwd")
+	require.Error(t, err)
+
+	// the default client doesn't reach the test server on loopback
+	_, err = NewFetcher(nil).Fetch(ctx, srv.URL+"/key")
+	require.ErrorIs(t, err, publichttp.ForbiddenAddressError)
+}
@@ -0,0 +1,291 @@
+// Package oauth provides access tokens from an OAuth token endpoint, for the
+// clients of the services image-builder talks to.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/osbuild/image-builder/internal/prometheus"
+)
+
+const (
+	// Tokens are refreshed this long before they expire, or half way
+	// through their lifetime when they live shorter than twice the margin
+	DefaultRefreshMargin = time.Minute
+	// The background refresh waits at least this long between refreshes,
+	// however short lived the tokens are
+	DefaultMinRefreshInterval = 5 * time.Second
+
+	// Deadline of a single call to the token endpoint
+	fetchTimeout = 30 * time.Second
+
+	// Backoff of the background refresh after a failed refresh
+	retryWaitMin = 5 * time.Second
+	retryWaitMax = time.Minute
+
+	// Without an expiry the background refresh only checks back this often
+	idleWait = 5 * time.Minute
+)
+
+type TokenSourceConfig struct {
+	TokenURL     string
+	ClientId     string
+	OfflineToken string
+	ClientSecret string
+
+	// Name of the client using the tokens, used as metrics label
+	Name string
+	// Defaults to DefaultRefreshMargin
+	RefreshMargin time.Duration
+	// Defaults to DefaultMinRefreshInterval
+	MinRefreshInterval time.Duration
+	// Defaults to a client with a timeout
+	HTTPClient *http.Client
+}
+
+// TokenSource hands out an access token and refreshes it ahead of its expiry.
+// Concurrent refreshes are collapsed into a single call to the token endpoint.
+type TokenSource struct {
+	conf   TokenSourceConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	token string
+	// When the token is due for a refresh, zero when it doesn't expire
+	refreshAt time.Time
+	fetchedAt time.Time
+	flight    *flight
+}
+
+// flight is a call to the token endpoint in progress, callers needing a new
+// token wait for it to be done.
+type flight struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func NewTokenSource(conf TokenSourceConfig) (*TokenSource, error) {
+	if conf.TokenURL == "" {
+		return nil, fmt.Errorf("Client needs token endpoint")
+	}
+	if conf.ClientId == "" {
+		return nil, fmt.Errorf("Client needs clientId")
+	}
+	if conf.OfflineToken == "" && conf.ClientSecret == "" {
+		return nil, fmt.Errorf("Client needs offline token, or client secret")
+	}
+	if conf.RefreshMargin == 0 {
+		conf.RefreshMargin = DefaultRefreshMargin
+	}
+	if conf.MinRefreshInterval == 0 {
+		conf.MinRefreshInterval = DefaultMinRefreshInterval
+	}
+
+	client := conf.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: fetchTimeout}
+	}
+
+	return &TokenSource{
+		conf:   conf,
+		client: client,
+	}, nil
+}
+
+// Token returns a valid access token, fetching a new one if the current one
+// is about to expire.
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	token := ts.token
+	fresh := token != "" && (ts.refreshAt.IsZero() || time.Now().Before(ts.refreshAt))
+	ts.mu.Unlock()
+
+	if fresh {
+		return token, nil
+	}
+	return ts.refresh(ctx)
+}
+
+// Invalidate drops the token after it got rejected, so the next call to Token
+// fetches a new one. Tokens which have been replaced already are ignored, so
+// many requests failing with the same token cause a single refresh.
+func (ts *TokenSource) Invalidate(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token == token {
+		ts.token = ""
+	}
+}
+
+// Run keeps the token fresh in the background until ctx is cancelled, so
+// requests don't have to wait for a refresh.
+func (ts *TokenSource) Run(ctx context.Context) {
+	wait := retryWaitMin
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ts.untilRefresh()):
+		}
+
+		_, err := ts.refresh(ctx)
+		if err == nil {
+			wait = retryWaitMin
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		logrus.Errorf("Error refreshing %s token, retrying in %v: %v", ts.conf.Name, wait, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait *= 2
+		if wait > retryWaitMax {
+			wait = retryWaitMax
+		}
+	}
+}
+
+// untilRefresh returns how long the background refresh waits, at least the
+// minimum interval since the last refresh
+func (ts *TokenSource) untilRefresh() time.Duration {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	wait := time.Duration(0)
+	if ts.token != "" && ts.refreshAt.IsZero() {
+		wait = idleWait
+	} else if ts.token != "" {
+		wait = time.Until(ts.refreshAt)
+	}
+	if minWait := time.Until(ts.fetchedAt.Add(ts.conf.MinRefreshInterval)); wait < minWait {
+		wait = minWait
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// refreshMargin is the configured margin, capped to half the lifetime of the
+// token so short lived tokens aren't due for a refresh as soon as issued
+func (ts *TokenSource) refreshMargin(lifetime time.Duration) time.Duration {
+	if ts.conf.RefreshMargin > lifetime/2 {
+		return lifetime / 2
+	}
+	return ts.conf.RefreshMargin
+}
+
+// refresh joins the call to the token endpoint in progress, or starts one. The
+// call isn't tied to ctx, one caller giving up doesn't fail the others.
+func (ts *TokenSource) refresh(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	f := ts.flight
+	if f == nil {
+		f = &flight{done: make(chan struct{})}
+		ts.flight = f
+		go ts.fetch(f)
+	}
+	ts.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (ts *TokenSource) fetch(f *flight) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	tr, err := ts.request(ctx)
+
+	ts.mu.Lock()
+	if err == nil {
+		now := time.Now()
+		ts.token = tr.AccessToken
+		ts.fetchedAt = now
+		ts.refreshAt = time.Time{}
+		if tr.ExpiresIn > 0 {
+			lifetime := time.Duration(tr.ExpiresIn) * time.Second
+			ts.refreshAt = now.Add(lifetime - ts.refreshMargin(lifetime))
+		}
+		f.token = tr.AccessToken
+		prometheus.TokenRefreshes.WithLabelValues(ts.conf.Name, "success").Inc()
+	} else {
+		f.err = err
+		prometheus.TokenRefreshes.WithLabelValues(ts.conf.Name, "failure").Inc()
+	}
+	ts.flight = nil
+	ts.mu.Unlock()
+
+	close(f.done)
+}
+
+func (ts *TokenSource) request(ctx context.Context) (*tokenResponse, error) {
+	data := url.Values{}
+	if ts.conf.OfflineToken != "" {
+		data.Set("grant_type", "refresh_token")
+		data.Set("client_id", ts.conf.ClientId)
+		data.Set("refresh_token", ts.conf.OfflineToken)
+	}
+	if ts.conf.ClientSecret != "" {
+		data.Set("grant_type", "client_credentials")
+		data.Set("client_id", ts.conf.ClientId)
+		data.Set("client_secret", ts.conf.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.conf.TokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logrus.Errorf("Error closing body after refreshing %s token: %v", ts.conf.Name, err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, body)
+	}
+
+	var tr tokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&tr)
+	if err != nil {
+		return nil, err
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned no access token")
+	}
+	return &tr, nil
+}
@@ -0,0 +1,215 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/image-builder/internal/prometheus"
+)
+
+// tokenServer issues token1, token2, ... expiring after expiresIn seconds
+func tokenServer(t *testing.T, expiresIn int, delay time.Duration) (*httptest.Server, *int32) {
+	var issued int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "refresh_token", r.FormValue("grant_type"))
+		require.Equal(t, "rhsm-api", r.FormValue("client_id"))
+		require.Equal(t, "offlinetoken", r.FormValue("refresh_token"))
+		time.Sleep(delay)
+
+		n := atomic.AddInt32(&issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token%d", n),
+			"expires_in":   expiresIn,
+		})
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &issued
+}
+
+func newTokenSource(t *testing.T, url string, margin time.Duration) *TokenSource {
+	ts, err := NewTokenSource(TokenSourceConfig{
+		TokenURL:      url,
+		ClientId:      "rhsm-api",
+		OfflineToken:  "offlinetoken",
+		Name:          t.Name(),
+		RefreshMargin: margin,
+		// the tokens of the tests are short lived
+		MinRefreshInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return ts
+}
+
+func refreshes(t *testing.T, name, result string) float64 {
+	var m dto.Metric
+	err := prometheus.TokenRefreshes.WithLabelValues(name, result).Write(&m)
+	require.NoError(t, err)
+	return m.GetCounter().GetValue()
+}
+
+func TestNewTokenSourceValidation(t *testing.T) {
+	_, err := NewTokenSource(TokenSourceConfig{ClientId: "rhsm-api", OfflineToken: "offlinetoken"})
+	require.Error(t, err)
+	_, err = NewTokenSource(TokenSourceConfig{TokenURL: "http://localhost", OfflineToken: "offlinetoken"})
+	require.Error(t, err)
+	_, err = NewTokenSource(TokenSourceConfig{TokenURL: "http://localhost", ClientId: "rhsm-api"})
+	require.Error(t, err)
+}
+
+func TestTokenIsCachedUntilExpiry(t *testing.T) {
+	srv, issued := tokenServer(t, 3600, 0)
+	ts := newTokenSource(t, srv.URL, 0)
+
+	token, err := ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+	token, err = ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+	require.Equal(t, int32(1), atomic.LoadInt32(issued))
+	require.Equal(t, float64(1), refreshes(t, t.Name(), "success"))
+}
+
+func TestTokenRefreshedWithinMargin(t *testing.T) {
+	// expires in two seconds, refreshed once less than a second is left
+	srv, issued := tokenServer(t, 2, 0)
+	ts := newTokenSource(t, srv.URL, time.Second)
+
+	token, err := ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+	time.Sleep(1100 * time.Millisecond)
+	token, err = ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token2", token)
+	require.Equal(t, int32(2), atomic.LoadInt32(issued))
+}
+
+func TestRefreshMarginCappedByLifetime(t *testing.T) {
+	// expires in a minute, well within the margin of two minutes
+	srv, issued := tokenServer(t, 60, 0)
+	ts := newTokenSource(t, srv.URL, 2*time.Minute)
+
+	token, err := ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+	// fresh for half its lifetime
+	token, err = ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+	require.Equal(t, int32(1), atomic.LoadInt32(issued))
+	require.InDelta(t, 30*time.Second, ts.untilRefresh(), float64(time.Second))
+}
+
+func TestRunShortLivedTokens(t *testing.T) {
+	// tokens live a second, shorter than the default margin of a minute
+	srv, issued := tokenServer(t, 1, 0)
+	ts, err := NewTokenSource(TokenSourceConfig{
+		TokenURL:           srv.URL,
+		ClientId:           "rhsm-api",
+		OfflineToken:       "offlinetoken",
+		Name:               t.Name(),
+		MinRefreshInterval: 400 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1100*time.Millisecond)
+	defer cancel()
+	ts.Run(ctx)
+
+	// refreshed every 400ms rather than continuously
+	n := atomic.LoadInt32(issued)
+	require.GreaterOrEqual(t, n, int32(2))
+	require.LessOrEqual(t, n, int32(4))
+}
+
+func TestConcurrentRefreshesSingleFlight(t *testing.T) {
+	srv, issued := tokenServer(t, 3600, 50*time.Millisecond)
+	ts := newTokenSource(t, srv.URL, 0)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := ts.Token(context.Background())
+			require.NoError(t, err)
+			tokens[i] = token
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(issued))
+	for _, token := range tokens {
+		require.Equal(t, "token1", token)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	srv, issued := tokenServer(t, 3600, 0)
+	ts := newTokenSource(t, srv.URL, 0)
+
+	token, err := ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+
+	ts.Invalidate("token1")
+	token, err = ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token2", token)
+
+	// a stale token doesn't drop the new one
+	ts.Invalidate("token1")
+	token, err = ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token2", token)
+	require.Equal(t, int32(2), atomic.LoadInt32(issued))
+}
+
+func TestTokenEndpointErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"error": "invalid_grant"}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+	ts := newTokenSource(t, srv.URL, 0)
+
+	_, err := ts.Token(context.Background())
+	require.EqualError(t, err, `token endpoint returned 400: {"error": "invalid_grant"}`)
+	require.Equal(t, float64(1), refreshes(t, t.Name(), "failure"))
+	require.Equal(t, float64(0), refreshes(t, t.Name(), "success"))
+}
+
+func TestRunRefreshesAhead(t *testing.T) {
+	// tokens expire in a second, the margin of 900ms is capped to half of it
+	srv, issued := tokenServer(t, 1, 0)
+	ts := newTokenSource(t, srv.URL, 900*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ts.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(issued) >= 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,219 @@
+// Package ostree checks OSTree options ahead of submitting them to composer,
+// which would otherwise only fail the compose once it tries to resolve them.
+package ostree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/osbuild/image-builder/internal/publichttp"
+)
+
+const (
+	// Deadline of a single call to an OSTree repository
+	fetchTimeout = 30 * time.Second
+
+	// Refs and commit objects hold a checksum and a few bytes of metadata,
+	// summaries list every ref of the repository
+	maxRefSize     = 1024
+	maxSummarySize = 16 * 1024 * 1024
+)
+
+var (
+	RefNotFound    = errors.New("ref not found in the repository")
+	CommitNotFound = errors.New("commit not found in the repository")
+
+	// Same syntax as libostree uses for refs
+	refRegex      = regexp.MustCompile(`^(?:[\w\d][-._\w\d]*/)*[\w\d][-._\w\d]*$`)
+	checksumRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// UnavailableError is returned when the repository can't be reached or fails
+// to serve a file, rather than not having it
+type UnavailableError struct {
+	URL string
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("Unable to reach the ostree repository %s: %v", e.URL, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateRef checks the syntax of a ref, such as rhel/8/x86_64/edge
+func ValidateRef(ref string) error {
+	if !refRegex.MatchString(ref) {
+		return fmt.Errorf("Invalid ostree ref %q", ref)
+	}
+	return nil
+}
+
+// IsChecksum tells whether the value is the checksum of a commit, rather
+// than a ref
+func IsChecksum(value string) bool {
+	return checksumRegex.MatchString(value)
+}
+
+// ValidateRepoURL checks that the url can be the location of a repository
+func ValidateRepoURL(repoURL string) error {
+	u, err := url.Parse(repoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid ostree repository url %q, expected an http or https url", repoURL)
+	}
+	return nil
+}
+
+// Remote looks up refs and commits in repositories served over http, the
+// same way composer does when resolving the parent of a commit.
+type Remote struct {
+	client *http.Client
+}
+
+// NewRemote returns a remote using the client, or when nil a client which
+// only reaches public addresses, as the repository urls come from users
+func NewRemote(client *http.Client) *Remote {
+	if client == nil {
+		client = publichttp.NewClient(fetchTimeout)
+	}
+	return &Remote{
+		client: client,
+	}
+}
+
+// ResolveParent returns the checksum of the parent, which is either a ref or
+// a commit of the repository. RefNotFound and CommitNotFound are returned
+// when the repository doesn't have the parent, an UnavailableError when it
+// couldn't be asked.
+func (r *Remote) ResolveParent(ctx context.Context, repoURL, parent string) (string, error) {
+	if IsChecksum(parent) {
+		return parent, r.checkCommit(ctx, repoURL, parent)
+	}
+	return r.ResolveRef(ctx, repoURL, parent)
+}
+
+// ResolveRef returns the checksum the ref points to. The ref is read from
+// refs/heads, falling back to the summary of the repository.
+func (r *Remote) ResolveRef(ctx context.Context, repoURL, ref string) (string, error) {
+	err := ValidateRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	body, found, err := r.fetch(ctx, repoURL, path.Join("refs/heads", ref), maxRefSize)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		checksum := strings.TrimSpace(string(body))
+		if !IsChecksum(checksum) {
+			return "", fmt.Errorf("Ref %s of %s doesn't hold a checksum", ref, repoURL)
+		}
+		return checksum, nil
+	}
+
+	refs, err := r.summaryRefs(ctx, repoURL)
+	if err != nil {
+		return "", err
+	}
+	checksum, ok := refs[ref]
+	if !ok {
+		return "", RefNotFound
+	}
+	return checksum, nil
+}
+
+// checkCommit checks that the repository has the commit object, or lists it
+// as the head of a ref in its summary
+func (r *Remote) checkCommit(ctx context.Context, repoURL, checksum string) error {
+	_, found, err := r.fetch(ctx, repoURL, fmt.Sprintf("objects/%s/%s.commit", checksum[:2], checksum[2:]), 0)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	refs, err := r.summaryRefs(ctx, repoURL)
+	if errors.Is(err, RefNotFound) {
+		return CommitNotFound
+	}
+	if err != nil {
+		return err
+	}
+	for _, c := range refs {
+		if c == checksum {
+			return nil
+		}
+	}
+	return CommitNotFound
+}
+
+// summaryRefs returns the refs listed in the summary of the repository, a
+// repository without summary has no refs to list
+func (r *Remote) summaryRefs(ctx context.Context, repoURL string) (map[string]string, error) {
+	body, found, err := r.fetch(ctx, repoURL, "summary", maxSummarySize)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, RefNotFound
+	}
+	refs, err := parseSummary(body)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the summary of %s: %w", repoURL, err)
+	}
+	return refs, nil
+}
+
+// fetch gets a file of the repository, found is false when the repository
+// doesn't have it. Only the status is checked when maxSize is 0.
+func (r *Remote) fetch(ctx context.Context, repoURL, file string, maxSize int64) ([]byte, bool, error) {
+	err := ValidateRepoURL(repoURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	method := http.MethodGet
+	if maxSize == 0 {
+		method = http.MethodHead
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(repoURL, "/")+"/"+file, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, false, &UnavailableError{URL: repoURL, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, &UnavailableError{URL: repoURL, Err: fmt.Errorf("fetching %s returned %s", file, resp.Status)}
+	}
+	if maxSize == 0 {
+		return nil, true, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, false, &UnavailableError{URL: repoURL, Err: fmt.Errorf("fetching %s: %w", file, err)}
+	}
+	if int64(len(body)) > maxSize {
+		return nil, false, fmt.Errorf("%s of the ostree repository %s is larger than %d bytes", file, repoURL, maxSize)
+	}
+	return body, true, nil
+}
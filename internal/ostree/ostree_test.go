@@ -0,0 +1,257 @@
+package ostree
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/image-builder/internal/publichttp"
+)
+
+// gvariant is a member of a container, variable sized members which aren't
+// the last of a tuple need a framing offset
+type gvariant struct {
+	data      []byte
+	alignment int
+	variable  bool
+}
+
+// frame appends the offsets with the smallest size fitting the container
+func frame(body []byte, offsets []int) []byte {
+	for size := 1; ; size *= 2 {
+		if offsetSize(len(body)+len(offsets)*size) > size {
+			continue
+		}
+		for _, o := range offsets {
+			for i := 0; i < size; i++ {
+				body = append(body, byte(o>>(8*i)))
+			}
+		}
+		return body
+	}
+}
+
+func pad(body []byte, alignment int) []byte {
+	for len(body)%alignment != 0 {
+		body = append(body, 0)
+	}
+	return body
+}
+
+func encodeTuple(members ...gvariant) []byte {
+	var body []byte
+	var ends []int
+	for i, m := range members {
+		body = pad(body, m.alignment)
+		body = append(body, m.data...)
+		if m.variable && i < len(members)-1 {
+			ends = append([]int{len(body)}, ends...)
+		}
+	}
+	return frame(body, ends)
+}
+
+func encodeArray(items [][]byte, alignment int) []byte {
+	var body []byte
+	var ends []int
+	for _, item := range items {
+		body = pad(body, alignment)
+		body = append(body, item...)
+		ends = append(ends, len(body))
+	}
+	return frame(body, ends)
+}
+
+// encodeSummary builds a summary of type (a(s(taya{sv}))a{sv})
+func encodeSummary(t *testing.T, refs map[string]string) []byte {
+	var items [][]byte
+	for ref, checksum := range refs {
+		csum, err := hex.DecodeString(checksum)
+		require.NoError(t, err)
+		commit := encodeTuple(
+			gvariant{make([]byte, 8), 8, false},
+			gvariant{csum, 1, true},
+			gvariant{nil, 8, true},
+		)
+		items = append(items, encodeTuple(
+			gvariant{append([]byte(ref), 0), 1, true},
+			gvariant{commit, 8, true},
+		))
+	}
+	return encodeTuple(
+		gvariant{encodeArray(items, 8), 8, true},
+		gvariant{nil, 8, true},
+	)
+}
+
+func checksum(n int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// repoServer serves the files of a repository
+func repoServer(t *testing.T, files map[string][]byte) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken/summary" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/repo/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write(data)
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestValidateRef(t *testing.T) {
+	for _, ref := range []string{"rhel/8/x86_64/edge", "fedora/stable/x86_64/iot", "edge", "a-b_c.d/e"} {
+		require.NoError(t, ValidateRef(ref), ref)
+	}
+	for _, ref := range []string{"", "/rhel/8", "rhel/8/", "rhel//8", "-rhel", "rhel/.8", "rhel 8", "remote:rhel/8", "rhel/../8"} {
+		require.Error(t, ValidateRef(ref), ref)
+	}
+}
+
+func TestIsChecksum(t *testing.T) {
+	require.True(t, IsChecksum(checksum(1)))
+	require.False(t, IsChecksum(strings.ToUpper(checksum(0xabc))))
+	require.False(t, IsChecksum(checksum(1)[1:]))
+	require.False(t, IsChecksum("rhel/8/x86_64/edge"))
+}
+
+func TestValidateRepoURL(t *testing.T) {
+	require.NoError(t, ValidateRepoURL("https://ostree.example.com/repo"))
+	require.NoError(t, ValidateRepoURL("http://10.0.0.1:8080"))
+	require.Error(t, ValidateRepoURL("ftp://ostree.example.com/repo"))
+	require.Error(t, ValidateRepoURL("/repo"))
+	require.Error(t, ValidateRepoURL("https://"))
+}
+
+func TestParseSummary(t *testing.T) {
+	refs := map[string]string{}
+	summary, err := parseSummary(encodeSummary(t, refs))
+	require.NoError(t, err)
+	require.Empty(t, summary)
+
+	refs["rhel/8/x86_64/edge"] = checksum(1)
+	summary, err = parseSummary(encodeSummary(t, refs))
+	require.NoError(t, err)
+	require.Equal(t, refs, summary)
+
+	// large enough for offsets of two and four bytes
+	for _, n := range []int{20, 2000} {
+		for i := 0; i < n; i++ {
+			refs[fmt.Sprintf("rhel/8/x86_64/edge-%d", i)] = checksum(i)
+		}
+		summary, err = parseSummary(encodeSummary(t, refs))
+		require.NoError(t, err)
+		require.Equal(t, refs, summary)
+	}
+
+	_, err = parseSummary(nil)
+	require.ErrorIs(t, err, errMalformedSummary)
+	_, err = parseSummary([]byte("not a summary"))
+	require.ErrorIs(t, err, errMalformedSummary)
+	data := encodeSummary(t, map[string]string{"edge": checksum(1)})
+	_, err = parseSummary(data[4:])
+	require.ErrorIs(t, err, errMalformedSummary)
+}
+
+func TestResolveParent(t *testing.T) {
+	files := map[string][]byte{
+		"refs/heads/rhel/8/x86_64/edge": []byte(checksum(1) + "\n"),
+		"refs/heads/broken":             []byte("not a checksum"),
+		fmt.Sprintf("objects/%s/%s.commit", checksum(2)[:2], checksum(2)[2:]): []byte("commit"),
+		"summary": encodeSummary(t, map[string]string{
+			"rhel/8/x86_64/edge": checksum(1),
+			"rhel/9/x86_64/edge": checksum(3),
+		}),
+	}
+	srv := repoServer(t, files)
+	repo := srv.URL + "/repo"
+	remote := NewRemote(srv.Client())
+	ctx := context.Background()
+
+	t.Run("RefHead", func(t *testing.T) {
+		c, err := remote.ResolveParent(ctx, repo+"/", "rhel/8/x86_64/edge")
+		require.NoError(t, err)
+		require.Equal(t, checksum(1), c)
+	})
+
+	t.Run("RefInSummary", func(t *testing.T) {
+		c, err := remote.ResolveParent(ctx, repo, "rhel/9/x86_64/edge")
+		require.NoError(t, err)
+		require.Equal(t, checksum(3), c)
+	})
+
+	t.Run("RefNotFound", func(t *testing.T) {
+		_, err := remote.ResolveParent(ctx, repo, "rhel/10/x86_64/edge")
+		require.ErrorIs(t, err, RefNotFound)
+	})
+
+	t.Run("RefWithoutChecksum", func(t *testing.T) {
+		_, err := remote.ResolveParent(ctx, repo, "broken")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, RefNotFound)
+	})
+
+	t.Run("CommitObject", func(t *testing.T) {
+		c, err := remote.ResolveParent(ctx, repo, checksum(2))
+		require.NoError(t, err)
+		require.Equal(t, checksum(2), c)
+	})
+
+	t.Run("CommitInSummary", func(t *testing.T) {
+		c, err := remote.ResolveParent(ctx, repo, checksum(3))
+		require.NoError(t, err)
+		require.Equal(t, checksum(3), c)
+	})
+
+	t.Run("CommitNotFound", func(t *testing.T) {
+		_, err := remote.ResolveParent(ctx, repo, checksum(4))
+		require.ErrorIs(t, err, CommitNotFound)
+	})
+
+	t.Run("NoSummary", func(t *testing.T) {
+		delete(files, "summary")
+		defer func() {
+			files["summary"] = encodeSummary(t, map[string]string{})
+		}()
+		_, err := remote.ResolveParent(ctx, repo, "rhel/9/x86_64/edge")
+		require.ErrorIs(t, err, RefNotFound)
+		_, err = remote.ResolveParent(ctx, repo, checksum(3))
+		require.ErrorIs(t, err, CommitNotFound)
+	})
+
+	t.Run("RepositoryErrors", func(t *testing.T) {
+		var unavailable *UnavailableError
+		_, err := remote.ResolveParent(ctx, srv.URL+"/broken", "rhel/8/x86_64/edge")
+		require.ErrorAs(t, err, &unavailable)
+		require.NotErrorIs(t, err, RefNotFound)
+		_, err = remote.ResolveParent(ctx, "ftp://ostree.example.com/repo", "rhel/8/x86_64/edge")
+		require.Error(t, err)
+		require.False(t, errors.As(err, &unavailable))
+		_, err = remote.ResolveParent(ctx, repo, "rhel//edge")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, RefNotFound)
+	})
+
+	t.Run("PublicOnly", func(t *testing.T) {
+		// the test server listens on loopback
+		var unavailable *UnavailableError
+		_, err := NewRemote(nil).ResolveParent(ctx, repo, "rhel/8/x86_64/edge")
+		require.ErrorAs(t, err, &unavailable)
+		require.ErrorIs(t, err, publichttp.ForbiddenAddressError)
+	})
+}
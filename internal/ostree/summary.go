@@ -0,0 +1,146 @@
+package ostree
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// The summary of a repository is a GVariant of type (a(s(taya{sv}))a{sv}),
+// the array lists the refs with the size, checksum and metadata of their
+// commit. Only the refs and checksums are read.
+
+var errMalformedSummary = errors.New("malformed summary")
+
+// parseSummary returns the checksums of the refs listed in the summary
+func parseSummary(data []byte) (map[string]string, error) {
+	// (a(s(taya{sv}))a{sv}), the array is the first of two variable sized
+	// members
+	offsets, err := tupleOffsets(data, 1)
+	if err != nil {
+		return nil, err
+	}
+	refList := data[:offsets[0]]
+
+	items, err := arrayItems(refList, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string]string{}
+	for _, item := range items {
+		// (s(taya{sv}))
+		offsets, err := tupleOffsets(item, 1)
+		if err != nil {
+			return nil, err
+		}
+		name, err := gvariantString(item[:offsets[0]])
+		if err != nil {
+			return nil, err
+		}
+		start := align(offsets[0], 8)
+		end := len(item) - offsetSize(len(item))
+		if start > end {
+			return nil, errMalformedSummary
+		}
+		commit := item[start:end]
+
+		// (taya{sv}), the checksum follows the fixed size of the commit
+		offsets, err = tupleOffsets(commit, 1)
+		if err != nil {
+			return nil, err
+		}
+		if offsets[0] < 8 {
+			return nil, errMalformedSummary
+		}
+		checksum := commit[8:offsets[0]]
+		if len(checksum) != 32 {
+			return nil, fmt.Errorf("%w: checksum of %s has %d bytes", errMalformedSummary, name, len(checksum))
+		}
+		refs[name] = hex.EncodeToString(checksum)
+	}
+	return refs, nil
+}
+
+// offsetSize is the size of the framing offsets of a container
+func offsetSize(size int) int {
+	switch {
+	case size == 0:
+		return 0
+	case size <= 0xff:
+		return 1
+	case size <= 0xffff:
+		return 2
+	case size <= 0xffffffff:
+		return 4
+	default:
+		return 8
+	}
+}
+
+func readOffset(data []byte, size int) int {
+	var offset uint64
+	for i := size - 1; i >= 0; i-- {
+		offset = offset<<8 | uint64(data[i])
+	}
+	return int(offset)
+}
+
+func align(offset, alignment int) int {
+	return (offset + alignment - 1) &^ (alignment - 1)
+}
+
+// tupleOffsets reads the framing offsets of the first n variable sized
+// members of a tuple, which are stored in reverse at its end
+func tupleOffsets(data []byte, n int) ([]int, error) {
+	size := offsetSize(len(data))
+	if size == 0 || len(data) < n*size {
+		return nil, errMalformedSummary
+	}
+	var offsets []int
+	for i := 0; i < n; i++ {
+		offset := readOffset(data[len(data)-(i+1)*size:], size)
+		if offset > len(data)-n*size {
+			return nil, errMalformedSummary
+		}
+		offsets = append(offsets, offset)
+	}
+	return offsets, nil
+}
+
+// arrayItems splits an array of variable sized items, the end of each item is
+// stored after the last one
+func arrayItems(data []byte, alignment int) ([][]byte, error) {
+	size := offsetSize(len(data))
+	if size == 0 {
+		return nil, nil
+	}
+	if len(data) < size {
+		return nil, errMalformedSummary
+	}
+	tableStart := readOffset(data[len(data)-size:], size)
+	if tableStart > len(data) || (len(data)-tableStart)%size != 0 {
+		return nil, errMalformedSummary
+	}
+
+	var items [][]byte
+	start := 0
+	for pos := tableStart; pos < len(data); pos += size {
+		end := readOffset(data[pos:], size)
+		start = align(start, alignment)
+		if start > end || end > tableStart {
+			return nil, errMalformedSummary
+		}
+		items = append(items, data[start:end])
+		start = end
+	}
+	return items, nil
+}
+
+// gvariantString reads a string, which is stored with a terminating nul byte
+func gvariantString(data []byte) (string, error) {
+	if len(data) == 0 || data[len(data)-1] != 0 {
+		return "", errMalformedSummary
+	}
+	return string(data[:len(data)-1]), nil
+}
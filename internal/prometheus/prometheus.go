@@ -55,6 +55,15 @@ var (
 	}, []string{"method", "path", "code"})
 )
 
+var (
+	TokenRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "token_refreshes_total",
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Help:      "total number of OAuth token refreshes, by client and whether they succeeded",
+	}, []string{"client", "result"})
+)
+
 func pathLabel(path string) string {
 	r := regexp.MustCompile(":(.*)")
 	segments := strings.Split(path, "/")
@@ -9,28 +9,48 @@ import (
 	"net/http"
 
 	"github.com/redhatinsights/identity"
+
+	"github.com/osbuild/image-builder/internal/oauth"
+	"github.com/osbuild/image-builder/internal/tlsconfig"
 )
 
 type ProvisioningClient struct {
 	url    string
 	client *http.Client
+	tokens *oauth.TokenSource
 }
 
 type ProvisioningClientConfig struct {
 	URL string
+
+	// Optional, requests carry a bearer token from it if set
+	TokenSource *oauth.TokenSource
+
+	// Optional CA and client certificate
+	TLS tlsconfig.Config
 }
 
 func NewClient(conf ProvisioningClientConfig) (*ProvisioningClient, error) {
+	client := &http.Client{}
+	if !conf.TLS.IsZero() {
+		tlsConfig, err := tlsconfig.New(conf.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("Error creating provisioning http client: %v", err)
+		}
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
 	pc := ProvisioningClient{
 		url:    conf.URL,
-		client: &http.Client{},
+		client: client,
+		tokens: conf.TokenSource,
 	}
 
 	return &pc, nil
 }
 
-func (pc *ProvisioningClient) request(method, url string, headers map[string]string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, body)
+func (pc *ProvisioningClient) request(ctx context.Context, method, url string, headers map[string]string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +59,31 @@ func (pc *ProvisioningClient) request(method, url string, headers map[string]str
 		req.Header.Add(k, v)
 	}
 
+	if pc.tokens == nil {
+		return pc.client.Do(req)
+	}
+
+	token, err := pc.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	resp, err := pc.client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || body != nil {
+		return resp, err
+	}
+
+	// the token got rejected, retry once with a fresh one
+	err = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	pc.tokens.Invalidate(token)
+	token, err = pc.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	return pc.client.Do(req)
 }
 
@@ -48,7 +93,7 @@ func (pc *ProvisioningClient) GetUploadInfo(ctx context.Context, sourceID string
 		return nil, fmt.Errorf("Unable to get identity from context")
 	}
 
-	return pc.request("GET", fmt.Sprintf("%s/sources/%s/upload_info", pc.url, sourceID), map[string]string{
+	return pc.request(ctx, "GET", fmt.Sprintf("%s/sources/%s/upload_info", pc.url, sourceID), map[string]string{
 		"x-rh-identity": id,
 	}, nil)
 }
@@ -0,0 +1,46 @@
+// Package publichttp builds the http clients which fetch the urls users pass
+// in their requests, which mustn't reach the network image-builder runs in.
+package publichttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var ForbiddenAddressError = errors.New("address isn't reachable from the public internet")
+
+// NewClient returns a client with the timeout which refuses to connect to
+// loopback, private and link-local addresses
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: publicOnly,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would hide the address the url is fetched from
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// publicOnly refuses connections to addresses which aren't public, it's
+// checked once the host is resolved so redirects and DNS can't bypass it
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%s: %w", host, ForbiddenAddressError)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package publichttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicOnly(t *testing.T) {
+	for _, address := range []string{
+		"127.0.0.1:80",
+		"[::1]:443",
+		"10.0.0.1:80",
+		"172.16.3.4:80",
+		"192.168.1.1:80",
+		"[fd00::1]:80",
+		"169.254.169.254:80",
+		"[fe80::1]:80",
+		"0.0.0.0:80",
+		"224.0.0.1:80",
+	} {
+		require.ErrorIs(t, publicOnly("tcp", address, nil), ForbiddenAddressError, address)
+	}
+	require.NoError(t, publicOnly("tcp", "1.1.1.1:443", nil))
+	require.NoError(t, publicOnly("tcp", "[2606:4700:4700::1111]:443", nil))
+}
+
+func TestNewClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// the test server listens on loopback
+	_, err := NewClient(time.Second).Get(srv.URL)
+	require.ErrorIs(t, err, ForbiddenAddressError)
+}
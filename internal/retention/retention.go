@@ -0,0 +1,66 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/osbuild/image-builder/internal/db"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	// How often the job runs.
+	Interval time.Duration
+
+	// Deleted composes are removed for good once they have been deleted
+	// for longer than this.
+	DeletedRetention time.Duration
+
+	// Composes older than this are removed, deleted or not. Composer
+	// expires the artifacts after a while, so there's no point in keeping
+	// the compose around afterwards. Zero disables the purge.
+	ArtifactLifetime time.Duration
+}
+
+// RunOnce hard-deletes the composes which are past retention, their clones
+// are removed along with them through the foreign key.
+func RunOnce(dbase db.DB, conf Config) error {
+	purged, err := dbase.PurgeDeletedComposes(conf.DeletedRetention)
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		logrus.Infof("Purged %d composes deleted more than %v ago", purged, conf.DeletedRetention)
+	}
+
+	if conf.ArtifactLifetime > 0 {
+		purged, err = dbase.PurgeComposesOlderThan(conf.ArtifactLifetime)
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			logrus.Infof("Purged %d composes older than %v", purged, conf.ArtifactLifetime)
+		}
+	}
+
+	return nil
+}
+
+// Run calls RunOnce every conf.Interval until ctx is cancelled. Errors are
+// logged, the next run will try again.
+func Run(ctx context.Context, dbase db.DB, conf Config) {
+	ticker := time.NewTicker(conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := RunOnce(dbase, conf); err != nil {
+			logrus.Errorf("Error purging composes past retention: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package secrets encrypts the secrets image-builder stores on behalf of its
+// users, such as the activation keys of subscription profiles.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeySize is the size of the keys, which select AES-256
+const KeySize = 32
+
+var DecryptionError = errors.New("Unable to decrypt secret")
+
+// Cipher encrypts secrets with AES-GCM. The nonce is prepended to the
+// ciphertext, and the associated data binds a secret to the record holding
+// it, so it can't be decrypted when copied into another record.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("Encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{
+		aead: aead,
+	}, nil
+}
+
+// NewCipherFromBase64 reads the key from its base64 encoding, as it is
+// passed in the configuration
+func NewCipherFromBase64(key string) (*Cipher, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("Encryption key isn't valid base64: %w", err)
+	}
+	return NewCipher(raw)
+}
+
+func (c *Cipher) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func (c *Cipher) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, DecryptionError
+	}
+	nonce := ciphertext[:c.aead.NonceSize()]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext[c.aead.NonceSize():], associatedData)
+	if err != nil {
+		return nil, DecryptionError
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCipher(t *testing.T) {
+	_, err := NewCipher(make([]byte, 16))
+	require.Error(t, err)
+	_, err = NewCipher(make([]byte, KeySize))
+	require.NoError(t, err)
+
+	_, err = NewCipherFromBase64("not base64!")
+	require.Error(t, err)
+	_, err = NewCipherFromBase64(base64.StdEncoding.EncodeToString(make([]byte, 8)))
+	require.Error(t, err)
+	_, err = NewCipherFromBase64(base64.StdEncoding.EncodeToString(make([]byte, KeySize)))
+	require.NoError(t, err)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	c, err := NewCipher(bytes.Repeat([]byte{1}, KeySize))
+	require.NoError(t, err)
+
+	secret := []byte("my-activation-key")
+	ad := []byte("000000/profile")
+	ciphertext, err := c.Encrypt(secret, ad)
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), string(secret))
+
+	// nonces are random, encrypting twice gives different ciphertexts
+	other, err := c.Encrypt(secret, ad)
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext, other)
+
+	plaintext, err := c.Decrypt(ciphertext, ad)
+	require.NoError(t, err)
+	require.Equal(t, secret, plaintext)
+
+	_, err = c.Decrypt(ciphertext, []byte("000001/profile"))
+	require.ErrorIs(t, err, DecryptionError)
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = c.Decrypt(tampered, ad)
+	require.ErrorIs(t, err, DecryptionError)
+
+	_, err = c.Decrypt(ciphertext[:4], ad)
+	require.ErrorIs(t, err, DecryptionError)
+
+	wrongKey, err := NewCipher(bytes.Repeat([]byte{2}, KeySize))
+	require.NoError(t, err)
+	_, err = wrongKey.Decrypt(ciphertext, ad)
+	require.ErrorIs(t, err, DecryptionError)
+}
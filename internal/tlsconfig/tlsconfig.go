@@ -0,0 +1,172 @@
+// Package tlsconfig builds the TLS configuration of the clients of the services
+// image-builder talks to, including client certificates for mutual TLS.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	// Path of a PEM bundle of the CAs trusted instead of the system ones
+	CA string
+	// Paths of the PEM client certificate and key presented to the server
+	Cert string
+	Key  string
+
+	// Check the client certificate files for changes this often, and load
+	// the new certificate if they changed. Zero disables reloading.
+	ReloadInterval time.Duration
+	// Defaults to tls.VersionTLS12
+	MinVersion uint16
+}
+
+// IsZero reports whether nothing is configured, so the default transport can
+// be used.
+func (c Config) IsZero() bool {
+	return c == Config{}
+}
+
+// New returns the TLS configuration for conf.
+func New(conf Config) (*tls.Config, error) {
+	if (conf.Cert == "") != (conf.Key == "") {
+		return nil, fmt.Errorf("Client certificate and key need to be set together")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: conf.MinVersion,
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	if conf.CA != "" {
+		caCert, err := os.ReadFile(filepath.Clean(conf.CA))
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No certificates found in %s", conf.CA)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if conf.Cert != "" {
+		cr := &certReloader{
+			certFile: filepath.Clean(conf.Cert),
+			keyFile:  filepath.Clean(conf.Key),
+			interval: conf.ReloadInterval,
+		}
+		err := cr.load()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = cr.getClientCertificate
+	}
+
+	return tlsConfig, nil
+}
+
+// ParseVersion parses a TLS version like "1.2", an empty string results in
+// the default.
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "":
+		return 0, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("Unsupported TLS version %q, expected 1.2 or 1.3", version)
+}
+
+// certReloader hands out the client certificate, reloading it when the files
+// changed. The files are checked on handshakes at most once per interval, if
+// loading them fails the previous certificate keeps being used.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu   sync.Mutex
+	cert *tls.Certificate
+	// modification time of the files when the certificate was loaded
+	loadedAt  time.Time
+	checkedAt time.Time
+}
+
+func (cr *certReloader) load() error {
+	modTime, err := cr.modTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("Error loading client certificate: %v", err)
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.cert = &cert
+	cr.loadedAt = modTime
+	cr.checkedAt = time.Now()
+	return nil
+}
+
+// modTime is the most recent modification of the certificate and key
+func (cr *certReloader) modTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{cr.certFile, cr.keyFile} {
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (cr *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cr.mu.Lock()
+	due := cr.interval > 0 && time.Since(cr.checkedAt) >= cr.interval
+	if due {
+		cr.checkedAt = time.Now()
+	}
+	cert := cr.cert
+	loaded := cr.loadedAt
+	cr.mu.Unlock()
+
+	if !due {
+		return cert, nil
+	}
+
+	modTime, err := cr.modTime()
+	if err != nil {
+		logrus.Errorf("Error checking client certificate %s for changes: %v", cr.certFile, err)
+		return cert, nil
+	}
+	if modTime.Equal(loaded) {
+		return cert, nil
+	}
+	err = cr.load()
+	if err != nil {
+		logrus.Errorf("Error reloading client certificate %s, still using the previous one: %v", cr.certFile, err)
+		return cert, nil
+	}
+	logrus.Infof("Reloaded client certificate %s", cr.certFile)
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return cr.cert, nil
+}
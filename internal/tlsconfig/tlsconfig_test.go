@@ -0,0 +1,200 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key signed by the CA
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+// mtlsServer requires a client certificate signed by the CA and responds with
+// its common name
+func mtlsServer(t *testing.T, ca *testCA) *httptest.Server {
+	certPEM, keyPEM := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		require.NoError(t, err)
+	}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, tlsConfig *tls.Config, url string) (string, error) {
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var body [64]byte
+	n, _ := resp.Body.Read(body[:])
+	return string(body[:n]), nil
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	srv := mtlsServer(t, ca)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ca.pem"), ca.pem)
+	certPEM, keyPEM := ca.issue(t, "image-builder", x509.ExtKeyUsageClientAuth)
+	writeFile(t, filepath.Join(dir, "cert.pem"), certPEM)
+	writeFile(t, filepath.Join(dir, "key.pem"), keyPEM)
+
+	// without a client certificate the server refuses the handshake
+	tlsConfig, err := New(Config{CA: filepath.Join(dir, "ca.pem")})
+	require.NoError(t, err)
+	_, err = get(t, tlsConfig, srv.URL)
+	require.Error(t, err)
+
+	tlsConfig, err = New(Config{
+		CA:   filepath.Join(dir, "ca.pem"),
+		Cert: filepath.Join(dir, "cert.pem"),
+		Key:  filepath.Join(dir, "key.pem"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	cn, err := get(t, tlsConfig, srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "image-builder", cn)
+}
+
+func TestCertificateReload(t *testing.T) {
+	ca := newTestCA(t)
+	srv := mtlsServer(t, ca)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeFile(t, filepath.Join(dir, "ca.pem"), ca.pem)
+	certPEM, keyPEM := ca.issue(t, "first", x509.ExtKeyUsageClientAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+
+	tlsConfig, err := New(Config{
+		CA:             filepath.Join(dir, "ca.pem"),
+		Cert:           certFile,
+		Key:            keyFile,
+		ReloadInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+	cn, err := get(t, tlsConfig, srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "first", cn)
+
+	// a broken key pair doesn't replace the working certificate
+	writeFile(t, keyFile, []byte("garbage"))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	time.Sleep(2 * time.Millisecond)
+	cn, err = get(t, tlsConfig, srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "first", cn)
+
+	certPEM, keyPEM = ca.issue(t, "second", x509.ExtKeyUsageClientAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	time.Sleep(2 * time.Millisecond)
+	cn, err = get(t, tlsConfig, srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "second", cn)
+}
+
+func TestConfigErrors(t *testing.T) {
+	_, err := New(Config{Cert: "cert.pem"})
+	require.Error(t, err)
+	_, err = New(Config{Cert: "missing.pem", Key: "missing.pem"})
+	require.Error(t, err)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ca.pem"), []byte("no certificates"))
+	_, err = New(Config{CA: filepath.Join(dir, "ca.pem")})
+	require.Error(t, err)
+
+	v, err := ParseVersion("1.3")
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), v)
+	_, err = ParseVersion("1.1")
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ package tutils
 import (
 	"encoding/base64"
 	"fmt"
+	"strings"
 )
 
 var completeIdHeader string = `{
@@ -75,3 +76,8 @@ func GetCompleteBase64Header(orgId string) string {
 func GetBase64HeaderWithoutEntitlements(orgId string) string {
 	return getBase64Header(idHeaderWithoutEntitlements, orgId)
 }
+
+// returns a base64 encoded string of the idHeader for a user who isn't an org admin
+func GetBase64HeaderNonAdmin(orgId string) string {
+	return getBase64Header(strings.Replace(completeIdHeader, `"is_org_admin": true`, `"is_org_admin": false`, 1), orgId)
+}
@@ -14,6 +14,9 @@ import (
 var AuthString0 = GetCompleteBase64Header("000000")
 var AuthString0WithoutEntitlements = GetBase64HeaderWithoutEntitlements("000000")
 
+// org_id 000000, not an org admin
+var AuthString0NonAdmin = GetBase64HeaderNonAdmin("000000")
+
 // org_id 000001
 var AuthString1 = GetCompleteBase64Header("000001")
 
@@ -72,3 +75,47 @@ func PostResponseBody(t *testing.T, url string, compose interface{}) (int, strin
 
 	return response.StatusCode, string(body)
 }
+
+func DeleteResponseBody(t *testing.T, url string, auth *string) (int, string) {
+	client := &http.Client{}
+	request, err := http.NewRequest("DELETE", url, nil)
+	require.NoError(t, err)
+	if auth != nil {
+		request.Header.Add("x-rh-identity", *auth)
+	}
+
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	if err != nil {
+		/* #nosec G307 */
+		defer response.Body.Close()
+	}
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	return response.StatusCode, string(body)
+}
+
+func PutResponseBody(t *testing.T, url string, payload interface{}) (int, string) {
+	buf, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	client := &http.Client{}
+	request, err := http.NewRequest("PUT", url, bytes.NewReader(buf))
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("x-rh-identity", AuthString0)
+
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	if err != nil {
+		/* #nosec G307 */
+		defer response.Body.Close()
+	}
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	return response.StatusCode, string(body)
+}
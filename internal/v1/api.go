@@ -7,11 +7,13 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/deepmap/oapi-codegen/pkg/runtime"
 	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
@@ -19,6 +21,13 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// Defines values for CustomizationsPartitioningMode.
+const (
+	AutoLvm CustomizationsPartitioningMode = "auto-lvm"
+	Lvm     CustomizationsPartitioningMode = "lvm"
+	Raw     CustomizationsPartitioningMode = "raw"
+)
+
 // Defines values for Distributions.
 const (
 	Centos8      Distributions = "centos-8"
@@ -101,8 +110,26 @@ type AWSEC2Clone struct {
 	ShareWithSources  *[]string `json:"share_with_sources,omitempty"`
 }
 
+// AWSEC2CloneBatch defines model for AWSEC2CloneBatch.
+type AWSEC2CloneBatch struct {
+	// Regions as described in
+	// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/using-regions-availability-zones.html#concepts-regions
+	Regions []string `json:"regions"`
+
+	// An array of AWS account IDs as described in
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/console_account-alias.html
+	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+	ShareWithSources  *[]string `json:"share_with_sources,omitempty"`
+}
+
 // AWSS3UploadRequestOptions defines model for AWSS3UploadRequestOptions.
-type AWSS3UploadRequestOptions = map[string]interface{}
+type AWSS3UploadRequestOptions struct {
+	// If false (the default), a long, obfuscated URL is returned, which might expire
+	// sooner than the images uploaded to other targets.
+	// If true, a short URL is returned, which expires along with the images uploaded
+	// to other targets. Anyone with the URL can download the image.
+	Public *bool `json:"public,omitempty"`
+}
 
 // AWSS3UploadStatus defines model for AWSS3UploadStatus.
 type AWSS3UploadStatus struct {
@@ -111,8 +138,14 @@ type AWSS3UploadStatus struct {
 
 // AWSUploadRequestOptions defines model for AWSUploadRequestOptions.
 type AWSUploadRequestOptions struct {
+	// Region the image is uploaded to, defaults to the region image-builder uploads to.
+	// Only the regions the service allows can be chosen.
+	Region            *string   `json:"region,omitempty"`
 	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
 	ShareWithSources  *[]string `json:"share_with_sources,omitempty"`
+
+	// Name of the EBS snapshot the image is registered from, defaults to a random one.
+	SnapshotName *string `json:"snapshot_name,omitempty"`
 }
 
 // AWSUploadStatus defines model for AWSUploadStatus.
@@ -133,6 +166,36 @@ type ArchitectureItem struct {
 // Architectures defines model for Architectures.
 type Architectures = []ArchitectureItem
 
+// AuditResponse defines model for AuditResponse.
+type AuditResponse struct {
+	Data  []AuditResponseItem `json:"data"`
+	Links struct {
+		First string `json:"first"`
+		Last  string `json:"last"`
+	} `json:"links"`
+	Meta struct {
+		Count int `json:"count"`
+	} `json:"meta"`
+}
+
+// AuditResponseItem defines model for AuditResponseItem.
+type AuditResponseItem struct {
+	AccountNumber string `json:"account_number"`
+
+	// One of 'compose', 'clone', 'delete', 'restore', 'create_subscription_profile',
+	// 'update_subscription_profile' or 'delete_subscription_profile'.
+	Action    string             `json:"action"`
+	CreatedAt string             `json:"created_at"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// Hex encoded SHA-256 hash of the request body, empty for calls without a body.
+	RequestHash string `json:"request_hash"`
+
+	// Id of the compose, clone or subscription profile the action was performed on
+	TargetId openapi_types.UUID `json:"target_id"`
+	Username string             `json:"username"`
+}
+
 // AzureUploadRequestOptions defines model for AzureUploadRequestOptions.
 type AzureUploadRequestOptions struct {
 	// Name of the created image.
@@ -140,6 +203,12 @@ type AzureUploadRequestOptions struct {
 	// The total length is limited to 60 characters.
 	ImageName *string `json:"image_name,omitempty"`
 
+	// Location of the resource group, where the image is uploaded and registered. This
+	// link explains how to list the locations:
+	// https://docs.microsoft.com/en-us/cli/azure/account?view=azure-cli-latest#az_account_list_locations
+	// Defaults to the location of the resource group.
+	Location *string `json:"location,omitempty"`
+
 	// Name of the resource group where the image should be uploaded.
 	ResourceGroup string `json:"resource_group"`
 
@@ -163,6 +232,44 @@ type AzureUploadStatus struct {
 	ImageName string `json:"image_name"`
 }
 
+// CloneBatchFailure defines model for CloneBatchFailure.
+type CloneBatchFailure struct {
+	Error  HTTPError `json:"error"`
+	Region string    `json:"region"`
+}
+
+// CloneBatchItem defines model for CloneBatchItem.
+type CloneBatchItem struct {
+	Id     openapi_types.UUID `json:"id"`
+	Region string             `json:"region"`
+}
+
+// CloneBatchResponse defines model for CloneBatchResponse.
+type CloneBatchResponse struct {
+	Clones []CloneBatchItem   `json:"clones"`
+	Failed *CloneBatchFailure `json:"failed,omitempty"`
+	Id     openapi_types.UUID `json:"id"`
+
+	// regions which weren't attempted after the failure, in the order requested
+	SkippedRegions *[]string `json:"skipped_regions,omitempty"`
+}
+
+// CloneBatchStatus defines model for CloneBatchStatus.
+type CloneBatchStatus struct {
+	Clones []CloneBatchStatusItem `json:"clones"`
+
+	// 'failure' if any clone failed, 'success' once all clones succeeded, otherwise
+	// 'running' if any clone is running and 'pending' if none is yet.
+	Status UploadStatusStatus `json:"status"`
+}
+
+// CloneBatchStatusItem defines model for CloneBatchStatusItem.
+type CloneBatchStatusItem struct {
+	Id           openapi_types.UUID `json:"id"`
+	Region       string             `json:"region"`
+	UploadStatus UploadStatus       `json:"upload_status"`
+}
+
 // CloneRequest defines model for CloneRequest.
 type CloneRequest interface{}
 
@@ -177,6 +284,12 @@ type ClonesResponse struct {
 	Links struct {
 		First string `json:"first"`
 		Last  string `json:"last"`
+
+		// the following page of clones, absent on the last page
+		Next *string `json:"next,omitempty"`
+
+		// the preceding page of clones, absent on the first page
+		Prev *string `json:"prev,omitempty"`
 	} `json:"links"`
 	Meta struct {
 		Count int `json:"count"`
@@ -208,6 +321,11 @@ type ComposeRequest struct {
 
 	// Array of exactly one image request. Having more image requests in one compose is currently not supported.
 	ImageRequests []ImageRequest `json:"image_requests"`
+
+	// Arbitrary key/value pairs to tag the compose with. Keys are at most 63 characters,
+	// consisting of alphanumerics, '-', '_', '.' and '/', and start and end with an
+	// alphanumeric character.
+	Labels *Labels `json:"labels,omitempty"`
 }
 
 // ComposeResponse defines model for ComposeResponse.
@@ -223,9 +341,17 @@ type ComposeStatus struct {
 
 // ComposeStatusError defines model for ComposeStatusError.
 type ComposeStatusError struct {
+	// Stable code of the error, see HTTPError
+	Code    *string      `json:"code,omitempty"`
 	Details *interface{} `json:"details,omitempty"`
 	Id      int          `json:"id"`
-	Reason  string       `json:"reason"`
+
+	// User facing description of the error
+	Message *string `json:"message,omitempty"`
+	Reason  string  `json:"reason"`
+
+	// What can be done about the error
+	Remediation *string `json:"remediation,omitempty"`
 }
 
 // ComposesResponse defines model for ComposesResponse.
@@ -234,6 +360,12 @@ type ComposesResponse struct {
 	Links struct {
 		First string `json:"first"`
 		Last  string `json:"last"`
+
+		// the following page of composes, absent on the last page
+		Next *string `json:"next,omitempty"`
+
+		// the preceding page of composes, absent on the first page
+		Prev *string `json:"prev,omitempty"`
 	} `json:"links"`
 	Meta struct {
 		Count int `json:"count"`
@@ -253,13 +385,21 @@ type ComposesResponseItem struct {
 // be specified. If more of them are specified, the order of precedence is
 // the same as listed above. Id is required.
 type CustomRepository struct {
-	Baseurl      *[]string `json:"baseurl,omitempty"`
-	CheckGpg     *bool     `json:"check_gpg,omitempty"`
-	CheckRepoGpg *bool     `json:"check_repo_gpg,omitempty"`
-	Enabled      *bool     `json:"enabled,omitempty"`
-	Filename     *string   `json:"filename,omitempty"`
+	Baseurl *[]string `json:"baseurl,omitempty"`
 
-	// GPG key used to sign packages in this repository. Can be a gpg key or a URL
+	// Enables gpg verification of the packages, requires a gpgkey.
+	// Defaults to true when a gpgkey is set.
+	CheckGpg *bool `json:"check_gpg,omitempty"`
+
+	// Enables gpg verification of the repository metadata, requires a
+	// gpgkey
+	CheckRepoGpg *bool   `json:"check_repo_gpg,omitempty"`
+	Enabled      *bool   `json:"enabled,omitempty"`
+	Filename     *string `json:"filename,omitempty"`
+
+	// GPG keys used to sign packages in this repository. Each is an
+	// armored public key, or the http or https URL to fetch it from. Keys
+	// given as URLs are fetched when the compose is requested.
 	Gpgkey     *[]string `json:"gpgkey,omitempty"`
 	Id         string    `json:"id"`
 	Metalink   *string   `json:"metalink,omitempty"`
@@ -271,17 +411,46 @@ type CustomRepository struct {
 
 // Customizations defines model for Customizations.
 type Customizations struct {
-	CustomRepositories  *[]CustomRepository `json:"custom_repositories,omitempty"`
-	Filesystem          *[]Filesystem       `json:"filesystem,omitempty"`
-	Openscap            *OpenSCAP           `json:"openscap,omitempty"`
-	Packages            *[]string           `json:"packages,omitempty"`
-	PayloadRepositories *[]Repository       `json:"payload_repositories,omitempty"`
-	Subscription        *Subscription       `json:"subscription,omitempty"`
+	CustomRepositories *[]CustomRepository `json:"custom_repositories,omitempty"`
+	Filesystem         *[]Filesystem       `json:"filesystem,omitempty"`
+
+	// Enable FIPS mode, the image only uses the cryptographic modules
+	// validated for FIPS 140. Not every distribution and image type
+	// supports it.
+	Fips     *bool                  `json:"fips,omitempty"`
+	Firewall *FirewallCustomization `json:"firewall,omitempty"`
+
+	// groups to create, in addition to the ones of the distribution
+	Groups *[]Group `json:"groups,omitempty"`
+
+	// Hostname of the image, a host name or a fully qualified domain name
+	Hostname *string   `json:"hostname,omitempty"`
+	Kernel   *Kernel   `json:"kernel,omitempty"`
+	Locale   *Locale   `json:"locale,omitempty"`
+	Openscap *OpenSCAP `json:"openscap,omitempty"`
+	Packages *[]string `json:"packages,omitempty"`
+
+	// Select how the disk image is partitioned. 'auto-lvm' uses raw partitions unless
+	// there are one or more mountpoints besides '/', in which case it uses LVM. 'lvm'
+	// always uses LVM, 'raw' always uses raw partitions.
+	PartitioningMode    *CustomizationsPartitioningMode `json:"partitioning_mode,omitempty"`
+	PayloadRepositories *[]Repository                   `json:"payload_repositories,omitempty"`
+	Subscription        *Subscription                   `json:"subscription,omitempty"`
+
+	// Id of a subscription profile of the organization to register the image with, instead
+	// of the subscription customization. The activation key isn't stored with the compose.
+	SubscriptionProfileId *openapi_types.UUID `json:"subscription_profile_id,omitempty"`
+	Timezone              *Timezone           `json:"timezone,omitempty"`
 
 	// list of users that a customer can add, also specifying their respective groups and SSH keys
 	Users *[]User `json:"users,omitempty"`
 }
 
+// Select how the disk image is partitioned. 'auto-lvm' uses raw partitions unless
+// there are one or more mountpoints besides '/', in which case it uses LVM. 'lvm'
+// always uses LVM, 'raw' always uses raw partitions.
+type CustomizationsPartitioningMode string
+
 // DistributionItem defines model for DistributionItem.
 type DistributionItem struct {
 	Description string `json:"description"`
@@ -296,12 +465,43 @@ type DistributionsResponse = []DistributionItem
 
 // Filesystem defines model for Filesystem.
 type Filesystem struct {
-	MinSize    uint64 `json:"min_size"`
+	// Minimal size of the filesystem, an integer number of bytes or a
+	// string with a unit, such as "512 MiB" or "20 GB".
+	MinSize FilesystemSize `json:"min_size"`
+
+	// Absolute path of the mountpoint. Allowed are '/', '/boot' and the
+	// paths in '/var', '/opt', '/srv', '/usr', '/app', '/data', '/home'
+	// and '/tmp', except for the ones in '/var/run' and '/var/lock'.
 	Mountpoint string `json:"mountpoint"`
 }
 
+// FirewallCustomization defines model for FirewallCustomization.
+type FirewallCustomization struct {
+	// Ports or port ranges to open, with their protocol
+	Ports *[]string `json:"ports,omitempty"`
+
+	// Firewalld services to allow or deny
+	Services *FirewallServices `json:"services,omitempty"`
+}
+
+// Firewalld services to allow or deny
+type FirewallServices struct {
+	Disabled *[]string `json:"disabled,omitempty"`
+	Enabled  *[]string `json:"enabled,omitempty"`
+}
+
 // GCPUploadRequestOptions defines model for GCPUploadRequestOptions.
 type GCPUploadRequestOptions struct {
+	// Name of the imported Compute Engine image, it must be unique within the project.
+	// Must begin with a lowercase letter, end with a lowercase letter or number, and may
+	// contain only lowercase letters, numbers and hyphens. The total length is limited to
+	// 63 characters. Defaults to a random name.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// Region the image is imported to and shared from, defaults to the region
+	// image-builder imports to. Only the regions the service allows can be chosen.
+	Region *string `json:"region,omitempty"`
+
 	// List of valid Google accounts to share the imported Compute Node image with.
 	// Each string must contain a specifier of the account type. Valid formats are:
 	//   - 'user:{emailid}': An email address that represents a specific
@@ -323,10 +523,22 @@ type GCPUploadStatus struct {
 	ProjectId string `json:"project_id"`
 }
 
+// Group defines model for Group.
+type Group struct {
+	Gid  *int   `json:"gid,omitempty"`
+	Name string `json:"name"`
+}
+
 // HTTPError defines model for HTTPError.
 type HTTPError struct {
-	Detail string `json:"detail"`
-	Title  string `json:"title"`
+	// Stable code of the error, set for errors of the image building service. Unlike the detail
+	// it doesn't change between releases.
+	Code   *string `json:"code,omitempty"`
+	Detail string  `json:"detail"`
+
+	// What can be done about the error
+	Remediation *string `json:"remediation,omitempty"`
+	Title       string  `json:"title"`
 }
 
 // HTTPErrorList defines model for HTTPErrorList.
@@ -359,12 +571,42 @@ type ImageStatusStatus string
 // ImageTypes defines model for ImageTypes.
 type ImageTypes string
 
+// Kernel defines model for Kernel.
+type Kernel struct {
+	// Arguments to append to the kernel command line
+	Append *string `json:"append,omitempty"`
+
+	// Name of the kernel package to install instead of the default one
+	Name *string `json:"name,omitempty"`
+}
+
+// Arbitrary key/value pairs to tag the compose with. Keys are at most 63 characters,
+// consisting of alphanumerics, '-', '_', '.' and '/', and start and end with an
+// alphanumeric character.
+type Labels struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// Locale defines model for Locale.
+type Locale struct {
+	// Keyboard layout of the console, as listed by localectl list-keymaps
+	Keyboard *string `json:"keyboard,omitempty"`
+
+	// Languages to install, the first one is the default language
+	Languages *[]string `json:"languages,omitempty"`
+}
+
 // OSTree defines model for OSTree.
 type OSTree struct {
 	// A URL which, if set, is used for fetching content. Implies that `url` is set as well,
 	// which will be used for metadata only.
 	Contenturl *string `json:"contenturl,omitempty"`
 
+	// Build the commit on top of the last successful commit of the ref, see
+	// /ostree/commits. The parent is set to that commit and the url to the one of its
+	// compose, unless set. Only for edge commits, requires the ref and excludes the parent.
+	Lineage *bool `json:"lineage,omitempty"`
+
 	// Can be either a commit (example: 02604b2da6e954bd34b8b82a835e5a77d2b60ffa), or a branch-like reference (example: rhel/8/x86_64/edge)
 	Parent *string `json:"parent,omitempty"`
 	Ref    *string `json:"ref,omitempty"`
@@ -376,11 +618,95 @@ type OSTree struct {
 	Url  *string `json:"url,omitempty"`
 }
 
+// OSTreeCommitsResponse defines model for OSTreeCommitsResponse.
+type OSTreeCommitsResponse struct {
+	Data  []OSTreeCommitsResponseItem `json:"data"`
+	Links struct {
+		First string `json:"first"`
+		Last  string `json:"last"`
+	} `json:"links"`
+	Meta struct {
+		Count int `json:"count"`
+	} `json:"meta"`
+}
+
+// OSTreeCommitsResponseItem defines model for OSTreeCommitsResponseItem.
+type OSTreeCommitsResponseItem struct {
+	// checksum of the commit, the ostree_commit of the compose metadata
+	Commit    *string            `json:"commit,omitempty"`
+	ComposeId openapi_types.UUID `json:"compose_id"`
+	CreatedAt string             `json:"created_at"`
+
+	// parent of the commit
+	Parent *string `json:"parent,omitempty"`
+
+	// compose of the parent, set when the commit was built on top of the lineage
+	ParentComposeId *openapi_types.UUID `json:"parent_compose_id,omitempty"`
+	Ref             string              `json:"ref"`
+
+	// last polled status of the compose
+	Status *string `json:"status,omitempty"`
+
+	// repository the parent was pulled from
+	Url *string `json:"url,omitempty"`
+}
+
+// OSTreeRefsResponse defines model for OSTreeRefsResponse.
+type OSTreeRefsResponse struct {
+	Data  []OSTreeRefsResponseItem `json:"data"`
+	Links struct {
+		First string `json:"first"`
+		Last  string `json:"last"`
+	} `json:"links"`
+	Meta struct {
+		Count int `json:"count"`
+	} `json:"meta"`
+}
+
+// OSTreeRefsResponseItem defines model for OSTreeRefsResponseItem.
+type OSTreeRefsResponseItem struct {
+	// number of commits in the lineage of the ref
+	Commits      int    `json:"commits"`
+	LastCommitAt string `json:"last_commit_at"`
+	Ref          string `json:"ref"`
+}
+
 // OpenSCAP defines model for OpenSCAP.
 type OpenSCAP struct {
+	// id of one of the profiles of the distribution, see /oscap/{distribution}/profiles
 	ProfileId string `json:"profile_id"`
+
+	// rules to select or unselect in addition to the ones of the profile
+	Tailoring *OpenSCAPTailoring `json:"tailoring,omitempty"`
+}
+
+// OpenSCAPRule defines model for OpenSCAPRule.
+type OpenSCAPRule = string
+
+// rules to select or unselect in addition to the ones of the profile
+type OpenSCAPTailoring struct {
+	Selected   *[]OpenSCAPRule `json:"selected,omitempty"`
+	Unselected *[]OpenSCAPRule `json:"unselected,omitempty"`
+}
+
+// packages and services the remediations of the profile need
+type OscapCustomizations struct {
+	Packages []string `json:"packages"`
+	Services Services `json:"services"`
+}
+
+// OscapProfile defines model for OscapProfile.
+type OscapProfile struct {
+	// packages and services the remediations of the profile need
+	Customizations OscapCustomizations `json:"customizations"`
+	Description    string              `json:"description"`
+	Name           string              `json:"name"`
+	ProfileId      string              `json:"profile_id"`
 }
 
+// OscapProfiles defines model for OscapProfiles.
+type OscapProfiles = []OscapProfile
+
 // Package defines model for Package.
 type Package struct {
 	Name    string `json:"name"`
@@ -405,6 +731,12 @@ type PackagesResponse struct {
 	Links struct {
 		First string `json:"first"`
 		Last  string `json:"last"`
+
+		// the following page of packages, absent on the last page
+		Next *string `json:"next,omitempty"`
+
+		// the preceding page of packages, absent on the first page
+		Prev *string `json:"prev,omitempty"`
 	} `json:"links"`
 	Meta struct {
 		Count int `json:"count"`
@@ -418,16 +750,30 @@ type Readiness struct {
 
 // Repository defines model for Repository.
 type Repository struct {
-	Baseurl  *string `json:"baseurl,omitempty"`
-	CheckGpg *bool   `json:"check_gpg,omitempty"`
+	Baseurl *string `json:"baseurl,omitempty"`
 
-	// Enables gpg verification of the repository metadata
-	CheckRepoGpg *bool   `json:"check_repo_gpg,omitempty"`
-	Gpgkey       *string `json:"gpgkey,omitempty"`
-	IgnoreSsl    *bool   `json:"ignore_ssl,omitempty"`
-	Metalink     *string `json:"metalink,omitempty"`
-	Mirrorlist   *string `json:"mirrorlist,omitempty"`
-	Rhsm         bool    `json:"rhsm"`
+	// Enables gpg verification of the packages, requires a gpgkey.
+	// Defaults to true when a gpgkey is set.
+	CheckGpg *bool `json:"check_gpg,omitempty"`
+
+	// Enables gpg verification of the repository metadata, requires a
+	// gpgkey
+	CheckRepoGpg *bool `json:"check_repo_gpg,omitempty"`
+
+	// Armored GPG public key used to sign packages in this repository,
+	// or the http or https URL to fetch it from. Keys given as URLs are
+	// fetched when the compose is requested.
+	Gpgkey     *string `json:"gpgkey,omitempty"`
+	IgnoreSsl  *bool   `json:"ignore_ssl,omitempty"`
+	Metalink   *string `json:"metalink,omitempty"`
+	Mirrorlist *string `json:"mirrorlist,omitempty"`
+	Rhsm       bool    `json:"rhsm"`
+}
+
+// Services defines model for Services.
+type Services struct {
+	Disabled []string `json:"disabled"`
+	Enabled  []string `json:"enabled"`
 }
 
 // Subscription defines model for Subscription.
@@ -442,6 +788,53 @@ type Subscription struct {
 	ServerUrl string `json:"server-url"`
 }
 
+// SubscriptionProfile defines model for SubscriptionProfile.
+type SubscriptionProfile struct {
+	BaseUrl      string             `json:"base-url"`
+	CreatedAt    string             `json:"created_at"`
+	Id           openapi_types.UUID `json:"id"`
+	Insights     bool               `json:"insights"`
+	Name         string             `json:"name"`
+	Organization int                `json:"organization"`
+	Rhc          bool               `json:"rhc"`
+	ServerUrl    string             `json:"server-url"`
+	UpdatedAt    string             `json:"updated_at"`
+}
+
+// SubscriptionProfileRequest defines model for SubscriptionProfileRequest.
+type SubscriptionProfileRequest struct {
+	// Required when creating a profile, when updating a profile the stored key is kept if
+	// it isn't set.
+	ActivationKey *string `json:"activation-key,omitempty"`
+	BaseUrl       string  `json:"base-url"`
+	Insights      bool    `json:"insights"`
+	Name          string  `json:"name"`
+	Organization  int     `json:"organization"`
+	Rhc           *bool   `json:"rhc,omitempty"`
+	ServerUrl     string  `json:"server-url"`
+}
+
+// SubscriptionProfilesResponse defines model for SubscriptionProfilesResponse.
+type SubscriptionProfilesResponse struct {
+	Data  []SubscriptionProfile `json:"data"`
+	Links struct {
+		First string `json:"first"`
+		Last  string `json:"last"`
+	} `json:"links"`
+	Meta struct {
+		Count int `json:"count"`
+	} `json:"meta"`
+}
+
+// Timezone defines model for Timezone.
+type Timezone struct {
+	// Host names or IP addresses of NTP servers to use instead of the default ones
+	Ntpservers *[]string `json:"ntpservers,omitempty"`
+
+	// Name of the timezone, as in the tz database
+	Timezone *string `json:"timezone,omitempty"`
+}
+
 // UploadRequest defines model for UploadRequest.
 type UploadRequest struct {
 	Options interface{} `json:"options"`
@@ -463,8 +856,32 @@ type UploadTypes string
 
 // User defines model for User.
 type User struct {
-	Name   string `json:"name"`
-	SshKey string `json:"ssh_key"`
+	// Id of the primary group of the user
+	Gid *int `json:"gid,omitempty"`
+
+	// Groups the user is a member of, besides their own one. Defaults to wheel, an empty
+	// list adds the user to no other groups.
+	Groups *[]string `json:"groups,omitempty"`
+
+	// Absolute path of the home directory, defaults to /home/<name>
+	Home *string `json:"home,omitempty"`
+	Name string  `json:"name"`
+
+	// Hash of the password of the user, as generated by crypt(3) with the SHA-512 ($6$),
+	// SHA-256 ($5$), yescrypt ($y$) or bcrypt ($2b$) method. Plain text passwords are
+	// rejected.
+	Password *string `json:"password,omitempty"`
+
+	// Absolute path of the login shell
+	Shell *string `json:"shell,omitempty"`
+
+	// SSH public key of the user, in the format of an authorized_keys line. Kept for
+	// compatibility, use ssh_keys instead.
+	SshKey *string `json:"ssh_key,omitempty"`
+
+	// SSH public keys of the user, each in the format of an authorized_keys line
+	SshKeys *[]string `json:"ssh_keys,omitempty"`
+	Uid     *int      `json:"uid,omitempty"`
 }
 
 // Version defines model for Version.
@@ -472,6 +889,15 @@ type Version struct {
 	Version string `json:"version"`
 }
 
+// GetAuditParams defines parameters for GetAudit.
+type GetAuditParams struct {
+	// max amount of audit entries, default 100
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// audit entries page offset, default 0
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
 // ComposeImageJSONBody defines parameters for ComposeImage.
 type ComposeImageJSONBody = ComposeRequest
 
@@ -482,11 +908,63 @@ type GetComposesParams struct {
 
 	// composes page offset, default 0
 	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// opaque position in the list of composes, as found in the next and prev links of a
+	// previous response. Takes precedence over offset.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// only return composes created at or after this time. When neither since nor until
+	// are given, only the composes of the last 14 days are returned.
+	Since *time.Time `form:"since,omitempty" json:"since,omitempty"`
+
+	// only return composes created before this time
+	Until *time.Time `form:"until,omitempty" json:"until,omitempty"`
+
+	// only return composes of this distribution
+	Distribution *Distributions `form:"distribution,omitempty" json:"distribution,omitempty"`
+
+	// only return composes of this image type, aliases are matched as well
+	ImageType *ImageTypes `form:"image_type,omitempty" json:"image_type,omitempty"`
+
+	// only return composes of this architecture
+	Architecture *GetComposesParamsArchitecture `form:"architecture,omitempty" json:"architecture,omitempty"`
+
+	// only return composes with this upload target
+	UploadType *UploadTypes `form:"upload_type,omitempty" json:"upload_type,omitempty"`
+
+	// only return composes whose image name contains this string, case insensitive
+	ImageName *string `form:"image_name,omitempty" json:"image_name,omitempty"`
+
+	// only return composes with this status. This is the last polled status, the
+	// status as last retrieved from the compose status endpoint, it isn't refreshed
+	// from composer. Composes whose status was never polled have the unknown status,
+	// and so do the composes created before the status was stored, unless polled since.
+	Status *GetComposesParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// order of the returned composes, default most recent first
+	Sort *GetComposesParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// only return composes whose labels match all of the comma separated requirements.
+	// A requirement is either 'key=value', 'key!=value', 'key' for composes having the
+	// label, or '!key' for composes not having it.
+	LabelSelector *string `form:"label_selector,omitempty" json:"label_selector,omitempty"`
 }
 
+// GetComposesParamsArchitecture defines parameters for GetComposes.
+type GetComposesParamsArchitecture string
+
+// GetComposesParamsStatus defines parameters for GetComposes.
+type GetComposesParamsStatus string
+
+// GetComposesParamsSort defines parameters for GetComposes.
+type GetComposesParamsSort string
+
 // CloneComposeJSONBody defines parameters for CloneCompose.
 type CloneComposeJSONBody = CloneRequest
 
+// CloneComposeBatchJSONBody defines parameters for CloneComposeBatch.
+type CloneComposeBatchJSONBody = AWSEC2CloneBatch
+
 // GetComposeClonesParams defines parameters for GetComposeClones.
 type GetComposeClonesParams struct {
 	// max amount of clones, default 100
@@ -494,6 +972,31 @@ type GetComposeClonesParams struct {
 
 	// clones page offset, default 0
 	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// opaque position in the list of clones, as found in the next and prev links of a
+	// previous response. Takes precedence over offset.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetOSTreeCommitsParams defines parameters for GetOSTreeCommits.
+type GetOSTreeCommitsParams struct {
+	// ref of the commits
+	Ref string `form:"ref" json:"ref"`
+
+	// max amount of commits, default 100
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// commits page offset, default 0
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// GetOSTreeRefsParams defines parameters for GetOSTreeRefs.
+type GetOSTreeRefsParams struct {
+	// max amount of refs, default 100
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// refs page offset, default 0
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
 }
 
 // GetPackagesParams defines parameters for GetPackages.
@@ -512,22 +1015,109 @@ type GetPackagesParams struct {
 
 	// packages page offset, default 0
 	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// opaque position in the list of packages, as found in the next and prev links of a
+	// previous response. Takes precedence over offset.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
 }
 
 // GetPackagesParamsArchitecture defines parameters for GetPackages.
 type GetPackagesParamsArchitecture string
 
+// GetSubscriptionProfilesParams defines parameters for GetSubscriptionProfiles.
+type GetSubscriptionProfilesParams struct {
+	// max amount of subscription profiles, default 100
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// subscription profiles page offset, default 0
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// CreateSubscriptionProfileJSONBody defines parameters for CreateSubscriptionProfile.
+type CreateSubscriptionProfileJSONBody = SubscriptionProfileRequest
+
+// UpdateSubscriptionProfileJSONBody defines parameters for UpdateSubscriptionProfile.
+type UpdateSubscriptionProfileJSONBody = SubscriptionProfileRequest
+
 // ComposeImageJSONRequestBody defines body for ComposeImage for application/json ContentType.
 type ComposeImageJSONRequestBody = ComposeImageJSONBody
 
 // CloneComposeJSONRequestBody defines body for CloneCompose for application/json ContentType.
 type CloneComposeJSONRequestBody = CloneComposeJSONBody
 
+// CloneComposeBatchJSONRequestBody defines body for CloneComposeBatch for application/json ContentType.
+type CloneComposeBatchJSONRequestBody = CloneComposeBatchJSONBody
+
+// CreateSubscriptionProfileJSONRequestBody defines body for CreateSubscriptionProfile for application/json ContentType.
+type CreateSubscriptionProfileJSONRequestBody = CreateSubscriptionProfileJSONBody
+
+// UpdateSubscriptionProfileJSONRequestBody defines body for UpdateSubscriptionProfile for application/json ContentType.
+type UpdateSubscriptionProfileJSONRequestBody = UpdateSubscriptionProfileJSONBody
+
+// Getter for additional properties for Labels. Returns the specified
+// element and whether it was found
+func (a Labels) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Labels
+func (a *Labels) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Labels to handle AdditionalProperties
+func (a *Labels) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Labels to handle AdditionalProperties
+func (a Labels) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// get the architectures and their image types available for a given distribution
 	// (GET /architectures/{distribution})
 	GetArchitectures(ctx echo.Context, distribution string) error
+	// get the audit log of mutating calls made by the organization
+	// (GET /audit)
+	GetAudit(ctx echo.Context, params GetAuditParams) error
+	// get status of a batch of clones
+	// (GET /clone-batches/{id})
+	GetCloneBatchStatus(ctx echo.Context, id openapi_types.UUID) error
 	// get status of a compose clone
 	// (GET /clones/{id})
 	GetCloneStatus(ctx echo.Context, id openapi_types.UUID) error
@@ -546,24 +1136,54 @@ type ServerInterface interface {
 	// clone a compose
 	// (POST /composes/{composeId}/clone)
 	CloneCompose(ctx echo.Context, composeId openapi_types.UUID) error
+	// clone a compose to several regions
+	// (POST /composes/{composeId}/clone-batch)
+	CloneComposeBatch(ctx echo.Context, composeId openapi_types.UUID) error
 	// get clones of a compose
 	// (GET /composes/{composeId}/clones)
 	GetComposeClones(ctx echo.Context, composeId openapi_types.UUID, params GetComposeClonesParams) error
 	// get metadata of an image compose
 	// (GET /composes/{composeId}/metadata)
 	GetComposeMetadata(ctx echo.Context, composeId openapi_types.UUID) error
+	// restore a deleted compose
+	// (POST /composes/{composeId}/restore)
+	RestoreCompose(ctx echo.Context, composeId openapi_types.UUID) error
 	// get the available distributions
 	// (GET /distributions)
 	GetDistributions(ctx echo.Context) error
 	// get the openapi json specification
 	// (GET /openapi.json)
 	GetOpenapiJson(ctx echo.Context) error
+	// get the OpenSCAP profiles available for a given distribution
+	// (GET /oscap/{distribution}/profiles)
+	GetOscapProfiles(ctx echo.Context, distribution string) error
+	// get the commit history of a ref
+	// (GET /ostree/commits)
+	GetOSTreeCommits(ctx echo.Context, params GetOSTreeCommitsParams) error
+	// get the refs of the edge commits built by the organization
+	// (GET /ostree/refs)
+	GetOSTreeRefs(ctx echo.Context, params GetOSTreeRefsParams) error
 
 	// (GET /packages)
 	GetPackages(ctx echo.Context, params GetPackagesParams) error
 	// return the readiness
 	// (GET /ready)
 	GetReadiness(ctx echo.Context) error
+	// get the subscription profiles of the organization
+	// (GET /subscriptions)
+	GetSubscriptionProfiles(ctx echo.Context, params GetSubscriptionProfilesParams) error
+	// create a subscription profile
+	// (POST /subscriptions)
+	CreateSubscriptionProfile(ctx echo.Context) error
+	// delete a subscription profile
+	// (DELETE /subscriptions/{id})
+	DeleteSubscriptionProfile(ctx echo.Context, id openapi_types.UUID) error
+	// get a subscription profile
+	// (GET /subscriptions/{id})
+	GetSubscriptionProfile(ctx echo.Context, id openapi_types.UUID) error
+	// update a subscription profile
+	// (PUT /subscriptions/{id})
+	UpdateSubscriptionProfile(ctx echo.Context, id openapi_types.UUID) error
 	// get the service version
 	// (GET /version)
 	GetVersion(ctx echo.Context) error
@@ -590,6 +1210,47 @@ func (w *ServerInterfaceWrapper) GetArchitectures(ctx echo.Context) error {
 	return err
 }
 
+// GetAudit converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAudit(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuditParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetAudit(ctx, params)
+	return err
+}
+
+// GetCloneBatchStatus converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCloneBatchStatus(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetCloneBatchStatus(ctx, id)
+	return err
+}
+
 // GetCloneStatus converts echo context to params.
 func (w *ServerInterfaceWrapper) GetCloneStatus(ctx echo.Context) error {
 	var err error
@@ -635,6 +1296,83 @@ func (w *ServerInterfaceWrapper) GetComposes(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "since" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "since", ctx.QueryParams(), &params.Since)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter since: %s", err))
+	}
+
+	// ------------- Optional query parameter "until" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "until", ctx.QueryParams(), &params.Until)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter until: %s", err))
+	}
+
+	// ------------- Optional query parameter "distribution" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "distribution", ctx.QueryParams(), &params.Distribution)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter distribution: %s", err))
+	}
+
+	// ------------- Optional query parameter "image_type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "image_type", ctx.QueryParams(), &params.ImageType)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter image_type: %s", err))
+	}
+
+	// ------------- Optional query parameter "architecture" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "architecture", ctx.QueryParams(), &params.Architecture)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter architecture: %s", err))
+	}
+
+	// ------------- Optional query parameter "upload_type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "upload_type", ctx.QueryParams(), &params.UploadType)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter upload_type: %s", err))
+	}
+
+	// ------------- Optional query parameter "image_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "image_name", ctx.QueryParams(), &params.ImageName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter image_name: %s", err))
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
+	// ------------- Optional query parameter "label_selector" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "label_selector", ctx.QueryParams(), &params.LabelSelector)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter label_selector: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
 	err = w.Handler.GetComposes(ctx, params)
 	return err
@@ -688,6 +1426,22 @@ func (w *ServerInterfaceWrapper) CloneCompose(ctx echo.Context) error {
 	return err
 }
 
+// CloneComposeBatch converts echo context to params.
+func (w *ServerInterfaceWrapper) CloneComposeBatch(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "composeId" -------------
+	var composeId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "composeId", runtime.ParamLocationPath, ctx.Param("composeId"), &composeId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter composeId: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CloneComposeBatch(ctx, composeId)
+	return err
+}
+
 // GetComposeClones converts echo context to params.
 func (w *ServerInterfaceWrapper) GetComposeClones(ctx echo.Context) error {
 	var err error
@@ -715,6 +1469,13 @@ func (w *ServerInterfaceWrapper) GetComposeClones(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
 	err = w.Handler.GetComposeClones(ctx, composeId, params)
 	return err
@@ -736,6 +1497,22 @@ func (w *ServerInterfaceWrapper) GetComposeMetadata(ctx echo.Context) error {
 	return err
 }
 
+// RestoreCompose converts echo context to params.
+func (w *ServerInterfaceWrapper) RestoreCompose(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "composeId" -------------
+	var composeId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "composeId", runtime.ParamLocationPath, ctx.Param("composeId"), &composeId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter composeId: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RestoreCompose(ctx, composeId)
+	return err
+}
+
 // GetDistributions converts echo context to params.
 func (w *ServerInterfaceWrapper) GetDistributions(ctx echo.Context) error {
 	var err error
@@ -754,6 +1531,79 @@ func (w *ServerInterfaceWrapper) GetOpenapiJson(ctx echo.Context) error {
 	return err
 }
 
+// GetOscapProfiles converts echo context to params.
+func (w *ServerInterfaceWrapper) GetOscapProfiles(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "distribution" -------------
+	var distribution string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "distribution", runtime.ParamLocationPath, ctx.Param("distribution"), &distribution)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter distribution: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetOscapProfiles(ctx, distribution)
+	return err
+}
+
+// GetOSTreeCommits converts echo context to params.
+func (w *ServerInterfaceWrapper) GetOSTreeCommits(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOSTreeCommitsParams
+	// ------------- Required query parameter "ref" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "ref", ctx.QueryParams(), &params.Ref)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter ref: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetOSTreeCommits(ctx, params)
+	return err
+}
+
+// GetOSTreeRefs converts echo context to params.
+func (w *ServerInterfaceWrapper) GetOSTreeRefs(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOSTreeRefsParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetOSTreeRefs(ctx, params)
+	return err
+}
+
 // GetPackages converts echo context to params.
 func (w *ServerInterfaceWrapper) GetPackages(ctx echo.Context) error {
 	var err error
@@ -795,6 +1645,13 @@ func (w *ServerInterfaceWrapper) GetPackages(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
 	err = w.Handler.GetPackages(ctx, params)
 	return err
@@ -809,6 +1666,88 @@ func (w *ServerInterfaceWrapper) GetReadiness(ctx echo.Context) error {
 	return err
 }
 
+// GetSubscriptionProfiles converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSubscriptionProfiles(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSubscriptionProfilesParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetSubscriptionProfiles(ctx, params)
+	return err
+}
+
+// CreateSubscriptionProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateSubscriptionProfile(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateSubscriptionProfile(ctx)
+	return err
+}
+
+// DeleteSubscriptionProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteSubscriptionProfile(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteSubscriptionProfile(ctx, id)
+	return err
+}
+
+// GetSubscriptionProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSubscriptionProfile(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetSubscriptionProfile(ctx, id)
+	return err
+}
+
+// UpdateSubscriptionProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateSubscriptionProfile(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.UpdateSubscriptionProfile(ctx, id)
+	return err
+}
+
 // GetVersion converts echo context to params.
 func (w *ServerInterfaceWrapper) GetVersion(ctx echo.Context) error {
 	var err error
@@ -847,18 +1786,30 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	}
 
 	router.GET(baseURL+"/architectures/:distribution", wrapper.GetArchitectures)
+	router.GET(baseURL+"/audit", wrapper.GetAudit)
+	router.GET(baseURL+"/clone-batches/:id", wrapper.GetCloneBatchStatus)
 	router.GET(baseURL+"/clones/:id", wrapper.GetCloneStatus)
 	router.POST(baseURL+"/compose", wrapper.ComposeImage)
 	router.GET(baseURL+"/composes", wrapper.GetComposes)
 	router.DELETE(baseURL+"/composes/:composeId", wrapper.DeleteCompose)
 	router.GET(baseURL+"/composes/:composeId", wrapper.GetComposeStatus)
 	router.POST(baseURL+"/composes/:composeId/clone", wrapper.CloneCompose)
+	router.POST(baseURL+"/composes/:composeId/clone-batch", wrapper.CloneComposeBatch)
 	router.GET(baseURL+"/composes/:composeId/clones", wrapper.GetComposeClones)
 	router.GET(baseURL+"/composes/:composeId/metadata", wrapper.GetComposeMetadata)
+	router.POST(baseURL+"/composes/:composeId/restore", wrapper.RestoreCompose)
 	router.GET(baseURL+"/distributions", wrapper.GetDistributions)
 	router.GET(baseURL+"/openapi.json", wrapper.GetOpenapiJson)
+	router.GET(baseURL+"/oscap/:distribution/profiles", wrapper.GetOscapProfiles)
+	router.GET(baseURL+"/ostree/commits", wrapper.GetOSTreeCommits)
+	router.GET(baseURL+"/ostree/refs", wrapper.GetOSTreeRefs)
 	router.GET(baseURL+"/packages", wrapper.GetPackages)
 	router.GET(baseURL+"/ready", wrapper.GetReadiness)
+	router.GET(baseURL+"/subscriptions", wrapper.GetSubscriptionProfiles)
+	router.POST(baseURL+"/subscriptions", wrapper.CreateSubscriptionProfile)
+	router.DELETE(baseURL+"/subscriptions/:id", wrapper.DeleteSubscriptionProfile)
+	router.GET(baseURL+"/subscriptions/:id", wrapper.GetSubscriptionProfile)
+	router.PUT(baseURL+"/subscriptions/:id", wrapper.UpdateSubscriptionProfile)
 	router.GET(baseURL+"/version", wrapper.GetVersion)
 
 }
@@ -866,116 +1817,216 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+x8a3PiOrboX1Fxpqr3vuFh3iRVu+YAIQkB8gDyHPrkCFvYAltyJJlH9s1/vyXZBhtM",
-	"oGe6Z+aeOv0hbbC0tLTW0nqLP1M6dVxKEBE8dfZniusWcqB6rD8NWs1C06YEyY8uoy5iAiP1kiETUyKf",
-	"DMR1hl2hPqbqwH8DIAf+mzEyACYjYgnh8rNczqA6z8IFz0IHflCS1amT85fK2VAgLnIPHLFLDxso53FM",
-	"zIwPkWfgHGIbjrGNxSrzQQniWUs49n/olOjIFTwcOCKpdEqsXJQ6S3HBMDFTn+kUtyBDbwssrDeo69QL",
-	"NryFPgGQMbgCdALqTwMQjATtc/5jO2rXe7vb0Snh1Ebh+hloY+jvQaGMltBxbZQ6+1sqXyiWypVq7VTL",
-	"F1Lf0ykskKPQdaEQiElU/+tvWub0+5/5wudfkrbrwGXbn5TXtPV7tbktanDqMd3n6jYGsaV3lojBTKc8",
-	"gt89FCwqmIc+P9Mpht49zJAhQQYy8309k46nSBcSVP1pMCg+uDaFRh+9e4iLW8WS6MKJowcCCo/vyqfH",
-	"7ASctxCSg/Zgsw+X+Cp7ZOoYRv44Nf95TNtPkH3khg6OoSK/yGh6rahVT4vVarl8WjZK4yQ53SiSzWTk",
-	"ZRaIi0x+d8IWB+W66S8Fi+kWFkgXHlO7TECd6VZ8+WWt8lYpJSGLHWiiN/m1mrqm8mbuu04XhaSp2weQ",
-	"IZdyLCgL0IjroQbkCESHgAllQFgImHiOCDCwhDz2hFK1xAAwss9sKiIAf2FokjpL/Uduo+dzgZLP9cMF",
-	"VrsYbhNaUilOgK09HKJ+nGJfobXDswTy1T88ho47pD7OBDpol8430EFS10vK6gxBIVW7HJ8dkZ7HBRgj",
-	"ExMgjxyAwEZS+QLKAPGcMWJpgIgRf5kOXslBHjEQ4zplKK145MAV0CkREBNAib0KpvBwDk9HpvA0cBHD",
-	"1OBpCctauRYiPDsiQwsBQQW0gY2IKSyAObCxgyXqgoKKBnQLMqhLyNm4XUl1MfGWbbm/lLIQXQUhdVbR",
-	"0ikHk/BjPh2xM7/9199g5qOeeZXm5i+//9/Y583j22iUzXz/P5Evvv/l9+QD7+uuN5NRz/2aJeFYoMaC",
-	"hYUYUi8UjwC3qGcbYIyApyQBGdsbHlJPh6QfgLlUKyb5Bj5G2NhFp30eIhOgIiwowALbtlqX+1SXiNpz",
-	"HzeBCCRCcZx74zUs6UNkR+ScAkIFcBmdYwMBGAx/w4Zkc3SC/GphIRKMxcQEEKwx3d6pr/qT9hYHuW+H",
-	"MVSPIvTTDm7xldIA2pzKSdyT0GjipiWZDJ8mmOi2p17v3WUJlY3auKBn4LhQypRK+WLmVNPLmUq+UNQq",
-	"qKadomTtG673FYMDxh2xeTC01KkjM4CWrg0x4cCiixERFEwwMQCWu1EwlKICd5QJaJ9t+YwO1hnldCKU",
-	"y4hIxuM5KMfnoC7wHGUMzJAu9XNu4hEDOogIaPOdtxmLLjKCZuTSGX8XCexZ0+ArxmwL4I+xp6xX0aQ8",
-	"rmTyenGSKRlQy8BKoZDRxlpFKxRPjapRPWjTtxREol3ZaP99Hklc629QdFYZHCjAr9GIAEhCQcVFgemR",
-	"K1CCbieps78dMG2RmOrz+wYMdynhCWGWL7CxU46kP5lBtdNxJl8wihlYKlcypUKlUi6XSpqmaal0akKZ",
-	"A0XqLOV52Di8UyO1RoXvx8WAAh5tw+PA9llxeYISeDfBzCfqZuM56OKcYklm7GHbQCw3z/sLc8T/qgzg",
-	"H3lt5GlaoUInE47EH1qSJrDhzwCd1w5S1d9EsGCSBDnIp2d87yqKiHjumAhkIrYD3h+3C3drmFokJHTa",
-	"5+Eus5M948AheoMiMZLwZfOAqPnY+GckQe42r9PR5RSGPgN6SMBQ8uLoUS4YQm86dRwsEvX6bxbk1u+h",
-	"epfMFSAYnoCoC/UZNJO88Tv/DbAxD9WgVKk3rcd+/VhfO4Cx3k6Sw72rZHwaRNQMNAwssYL2XYQYE2hz",
-	"lN5mn8cFdfAHXPvFXx7Y+OjPdCoaYxyafR4ZyzeRUoyM0TPXWykv9DzyPuaQFsra3vBrV6cH0KTnGAcT",
-	"TX1sgwkELykJFGaA0BLqwl4BSkJfIJiUBVdwLkXAoWzrFQfKu0cgUCDSPdc9xhCRkKQd5Z7rUiZCZ/Uo",
-	"6VH7C8Ugnt1Rnvvmw48mZWJc3qHN96+E8muj9ffZIB/210adr98eJFkAKKaGvj4G8ROX7BQECGyA7qDe",
-	"YoyyBBOKBMS2fFzrz201L4FC7p+XgyRbD44g8NMs+Ba4/7Xh/3Y2PIlDv8iKx1XvzzPyyupE8lA7ynjz",
-	"DuiUTLDpMWWiVELMN3GxRFl2ROoC2AhyodRwYPy/jSFHHrO/pcE3B8vTKY25+oQElKT9BjZ0A47HxYjI",
-	"CMlFOp5gGfO1J7669yE6ALLI67RahTIDMTnAZUhHBiK61P8jotIH0EEAcuVEIAPAMZ2jLGgb0kCEBPMt",
-	"Qpx/AeJbmd4wjtQNkmXIsKAfQ+qUCERETmr1HLOQXcvVcn4+MycBUZ6jPBfLEG9Yz/AxiUvdQvrszXTN",
-	"iCCMKbURJJvXkiP7xyACxzYykl9OsI32ypnpmjOUICWXd5dghlbrfAzHJgGhQ+eH4orMoSxlQRMSGQFD",
-	"YLqmmkoZgOCh340XYjLyX6N12b4Bd5d34O6h0W03Qaf1Ahrd22ZHvR6REXHu2zeNy7o+0GmjVT/vTmov",
-	"VzP0cV2Bht17WVTh5WXbvoa2qF1PC8tco9A5sdqTtre8FO7jtIpGpNs3zx+qlSkclt3H87Jz0bsuujNE",
-	"UD+nD5339/vZzeqeW88Fev+8aH08DMb55k2vOWlemrPn2n1hRD5eZ6ytN9mFdl9YsM7Yhp5hPZzgR0jq",
-	"59zJ115a73xcrj8Uq4Z4YL3i/YvxZJ72T57x3eSx1h+RTmM61Irzx8at0Rvwl+JpFzZJpe3mb+durd2i",
-	"uTZqPb7k353m7V0ddrTx9VXRm5ilpodm/GQ4GJHF/dMQNbtL77Vbue0909u7zmLeu58sx2b++bw29161",
-	"jpjm9JurwhJ62tLhde/06tpFs/ntXX9pj8jqXUxXrxNGHzG6WLmLV3N+vxCE9Go5c9DyctePQ/ailQtO",
-	"62FYberjammmX10MLya9mU1ml7kR0SYPpXoflrXSVXE51WZijIrzjn73TO9uvU7jkV8N5pr2cPlSX90h",
-	"b3VSq+oPuZeW1avOioPHznREKqj9aq5w71Zb2PmXy/N+R/fsxYyf1k88e2bm6XBc4sUP53V+p1Uv6XD5",
-	"VCpMYaf8NDi5sV4RGpFaRXumj9ZYz3fcwcl08kqnnLXEa+1u/PB68jK/qPVdZjzV2fRqfD0rXLv9Tn05",
-	"tJb8vs4b1mV+RLSutyw8wV5DMwvt8p3eM65z+vuUajVdZ9PGs4eXTwyXsXfae3Zr78PcZPBx43CjbZJa",
-	"7v21MyK4du/ZE69a9d6tp9xCFMaCYGH2+fvUWva86ctD6XVcsmbiomZ1HnLPz9VS4d3qljuLer9+X2+M",
-	"iDi/uHx96s91p2V2znv5zqBee3UeZ+PitdUd9vLd58YKPuUtndj18Hv96noOncep0SzPR0R39BN8f33b",
-	"aPQazXq9dIFbLXRVcZh1cVX1Hvl9t9craC9l/dUiy5faRd1RZ6h5uahdNBez9og0Fu3Li3t63azzZqPx",
-	"0qwvWs0rs9W8KNXrTXN2v5l9cvNSz1UbL65prwb115cra7rqWCOSO5lUPu4mj/PxVUFrvRdn7ertReNG",
-	"I93nk8ZD3vHmg5P3oTcoPnVZo+gULz1buJ1+67rTFU65dT4ieXb58Vynw/zKPX1p17r1c6PXbN6upvUp",
-	"p08PterLg9c8yY3JlA1Rv9Dt3zYnq7tmtfJ0Wivj28cRccqDkzG/P19Um4Uus416r9Q79+jqNT/A4hK+",
-	"ljr33UdxMmzBfAnzl8Flc/pBq3cvtcfi9e2srI2I+f5k1go3ubFTaH0MqsNa8al1Ps7b82mpbc+XZvu9",
-	"g8x8/uP5Zemwl8Hr9XVzMv+YnNg3g4q3NK9GZLrMXWsr+7XQxeNLVrms11e3pw9PrP46WAx6WkufDmuL",
-	"VpMsZ4Nzb/XuPC0e5zeNZ6/VfqzdouLLiPTwQ35yfVPjRvXc5RfLcu/k2SA9cj84uWLT4V3nvOg8Mbtu",
-	"kNbQMl4ea9PXmftkna94MXd6im5HxJpprEtW2vRmMYPeJIcfard65Xnem027/d61WX44feysrr2nJ/Gx",
-	"eCbT3k35qX/ReO+U+Ct1er0RmYjx8Cp/Ul6N+0+5enHeGMNl/6kgqg8fN1P9A80Gry0Muzen3dyVft1s",
-	"9/P3F7VKrXBu1O3WxakxIrOCeY9fBvd1CK+16+v6x9W8P+tfd7tmp/By/4Kvbh5XBVG8Xl1MOINOeTFo",
-	"Pt1OrDvUXnUbw9frEZkz98a+G6MJH56Wq8NJoXHT9syPV9YsPy7PB53Zq9m38o+X80H7njRXH7P7VaX1",
-	"UHi/c/FT+VTqKOuu/fzKOlTvFDvdwWkOf1zfD/u2mPbqf4zIH3eTYXVElHVp3Zx/ZXp+oNq7HXNEmgUC",
-	"HyjuVIc+hu8v8ewEGZRBl1HppWYpM3PhvL9Ky/qH/z5TLPhuNmS69ce6lnrIzdg4ZbtIrHGQr7M6IoJy",
-	"tf5fGZKeHvqjluGCIehEVobyb6Xkf6Pwa0CObgdH4LLX/XAZpgyLVXLgxrn9NkcMT1ZJnk1CwJsUXO8k",
-	"bZKSOm/b1ePjIrptZztBQKT3xVc8iCSOAnuxmRLPTBRqu/CpiwjXoXsI6K2LyKBZv9tOzEVcM5dyYTLE",
-	"3+2vz0CsEyapF8aFK5tC4+8j6tfkjJZTDkEaRMd+plMeRywhO6WSkHQC1Gu/GgmDQAgxoEMCoBGWePzw",
-	"ZIWJKcMTzABD8iuB50FJlasCz2BwJV1gfmw26oH7An84gRnNCyaHp3tThH1kgCsoQIsIxFyGOQKqfA1+",
-	"61+1ur+DWrb01dndAJJhUKZWOhjsEz99GEXo+4Et+SJJPEfVrdQ6MtpVDxmCTUvYq/UXpfVTef1UWT9V",
-	"109rEKfrh21Yp9r6Kb9+KsjwWulGhUXwKIGEirkaea5Fnk8jG91QMrbRaFLpKBnZ4XzC6biIKZu4XDiY",
-	"vHH8EedlXiuU0qllxqSZAJaHiaiU1DGnHhEuxWQ7NTSH7CDvI5PTm6WT2H/ZvPuHesPih7kbHOY5tLEB",
-	"Lik1bRQ2HXIVyEooQTHazxqDJnVcTyBwQ40w9yxXyY5IC+oW8HeoMhjrbhO4TlSwMCESdjbKDWbBo1rf",
-	"t4scQIbORgSADPgmtczZn8iB2MbG57czUCdAfZJahiEeaCCGXIa4FIDNWroEAbY2lQUXlIGAO2nwDdpY",
-	"R/8ZfM7q1PmWDVbmiM2xjur+vB/EwV86ALFvbWeVocJCLANd9z+h63KXiqwZTArnRFFSKvNHqRHsX83N",
-	"+nhtkcBwMOGJNDCoAzE5+9P/Xy44tBC4BAMPCwT8b8FvLsMOZKvfdxe3bX9ByXDfXijuQxHM3aaIqXBV",
-	"KADKwLcdnABoT/wiRjzx9ZVwYu7PkJIcdkuRlQ8tpPJ2/6sSux3ZSKVTW1JxLAtT6ZTPvF1iS5Xvkzn6",
-	"5U9tfE1SBV/qlp/X1KCyiRL+23YrAeQ6IgYkIjNmEBuZolYs54sHNWUEXPpQj8TVcHj3ZSUkmbpY2Ohw",
-	"+cMflg4hfY+u1w3CiPiaSIUxR1uwDfaHmiMDwBKFWKHux+q10RbOXUvRvHuINXmGelyxIA38OMtvBfUD",
-	"H5Wf3lQet6qOodOyjs+CWYmewKbr86ii21C1h0pnX1XoD7r6g6EcJT1eN/DDjyrVxcxwYsPqmpqxLeys",
-	"kyS60ephsiAdWT+L1gNlQLAGGXKAe7qOuPS9JxDbPrYuIoakfTqlqln+o4+1/8yQiblAikHfox1YG2g7",
-	"XAx2fVz1NKaHdvSZ//Va3odhQ3S4J7iQGKhWNilrhoky674L9QkTLqBtI+mZmbor/0pWrLWYX8qLjppz",
-	"10IKXPCUoXMoF/E7wKUHHF9n81UMjGUkingghAmlPFVQCQow23ddHvpdsLCwbqUBngCORFpaO1WEmFAG",
-	"JkjolnTGAihZ0HZcG6PATfhvj9n/LSdwJADkYIFsOz0iCmC8w1QCc4LOEdU6nE2+4+JCedoTlIdf7EBY",
-	"2koZLioigd8CuTkDWqGilcYFA1bQabk0NoqlcW1cK8BasYzKsFo1CuOKNpnA39N+nWTMINGtjI1nCDA0",
-	"QUyVujbwJPE3lSfJhd+3WgV3RyQ3D012g7kjplnc2aXCORKIOZggDhYWCkjhe96x7lcHEmgiBn7TITFs",
-	"5GLyO8AGIgKLVbRaBwQdEajOW0J9iRLuqYhcCtME61DIdaNchRzoNkZEbI2xEBmRteys+S51eyhIUfZH",
-	"amd7b73s6Ld1fmVH4l1GJ9hGO/7CUteNyRtlZpZzM0wCBvi8hZN0zI/xIMIFkjRv0Ci1i9jerBz3HOn/",
-	"HnYZggg/HP99s9r+LrPwesjOqsile958USVX2crkTWDTMcr7XhEYegV7nNGEF3PEOD6mkSSwigF1wmkb",
-	"dNPh7Y8AxwjdflazScj0X9BfEmYP9/SX+J+iXVDZbDb7j3SdfL1g/ugV///pRUlApo+kqyI9kYSro5FX",
-	"h1rBw6HJa0TbRg53TfyDTROH6wY/3BphoAn0bLEOCOL2qqXaJLjqUFB1BWkgpIFa345Zt8aERmKPXdi0",
-	"Tez69iahDL1xbicj/b+loUTP4kB1Rw1LktnBVkFgy9joAs8VjzMBv2IxPkc6Q0K9imDqQs4XlCW2bUnx",
-	"zSSeg91jkNj2RTg2ra1brYJ5KEnKKDMhCUpXsQkFraQVC6V0UrOjpR8+CH6OFdpgYkMTCCrdJ8As3b95",
-	"5UdC/hUtlUxO+z65XwKB9gKuOEDBWWoHG9rKOO3bEkdsjtguBaP+YlYyO0LIg5o8Rqf0NtNji0Y4GGFG",
-	"kmDFo+HdZvlNmhqS1XE3VRLz3J/pg/P23OQ+NHNfZv3ginuvgqobNsekLfzZQd4i2TsKCbif9vuyBRHS",
-	"H31JKB5+H0/yI2dsJxp/gMThjO9/RzKDeYQEGYu9buzfy6Z1T/Y2v9b82ZOl8NMPYa4CLniWFxMxVFXP",
-	"vVHJRjd4HLF84k1Mbr3tqHXOrQzjENTr9XqjePMBm/ljK5UhvCSRfNw4/3F8j44KwoHfPz+VIZjQ3Yh6",
-	"EBRYgsKDLTWtn/RX/vD6ToTyGHUUxAk+yVJ1F+oWAoWslgoi17VbsVgsslC9VrY8mMtz3XazdTNoZQpZ",
-	"Tf1sRiRd7GeiQj88LP1E4pmzVD6rhS0I0MWps1Qxq2UltV0oLEWcXDRryHN/Rp30TznARP5lPxf5Xcht",
-	"I3WWukQiftFdJWKgg4Qq4P9tm2qxy/sTygJzJSiwKZ0BzwXBL53YKJby5TsJFL+ojInyAoQVBnFn27c6",
-	"Nnz1DZ1/oJJk4Lu6oK1COkWRgqZF0mDKfLiuHTiiuWlwUWED79h7/fIAf27beQjC5oY9BFA5EL+TAXJO",
-	"dby5sA/EWi2s8wGSXX6xcw+QyMzIkhOV4tr9oQUFPKerm3O5P7ERlYj4RnxlpDYC1HilkLZkRl3BG4Rq",
-	"60uJaRsSloIEAtiCArl0IvfXPffJPP/p10l/pdBsJaJ3ZCZKlATuxzgR3MjyOeIz0/9KqUnKEzi5vsUV",
-	"JKbjXAxy/OEvKgTqrkGN1U/b/86FpB0KBJeK5B4DeVZ3uwPMd0Xhc4db+Z+PbZAVSmJYQFELcskdJpAh",
-	"tXLpJ8pMvA6YgIP6uY8Aj4BpAHPgQFuKukQoJkhxIYgKDv/KKIT3cw6dbgcuAVQdKOqUB7PSIAiKQF4d",
-	"QnXO3z3EVpuDrjJMqejZXgdS6uqjA5fYkc6O/wmT4FM6IRu0R/I5cKVM+dmrDU77MPLHJaMURUFLQOFX",
-	"qpGdy3Ffmp81d3cVilQjto30MBHjMjTH1OPb8rT5xR6bmqb6xTDVghEXn9yfwVPbtyYGspFASaUL+T3f",
-	"KDG/7SJcU5UVuJB/g64euoDM4ODdowL6sW5cPH2AAVVSyYTfCsI7W9Twcd2gpBJNB6whCVSUvl5436kZ",
-	"bO5Y/lqR+MK0BNQ9xrhsb+zzOIu+JkOCFV9Lxj/ZmO+TT9/z2W8q/Z8U2MhDFtwSe7U+S37nj7qLBxf8",
-	"W8T32u1SUCYaEzNJctUyG8E9nsrSLobO2L8RuX+R2xD9eZKvnQbJEoIWa9r8E72F2I+f7HHuMDHjvkLc",
-	"NCv3L6KDvpZevtdl7yPhMSLFdx2D2LavZH25DsryiKEQlSBQCNZIENWNNvPPxg+Laxi/BCjQyb+V6KYP",
-	"ODIK6X+5G+OT7n+GExP/hZ4vTFYg7Lsmay1JR50ZJ1IWTzw16+YEdRSOt+7revsPnYj1al8Fv/9KPf5r",
-	"PZXNb8fsZ7yzGbPN+k0DUZK/ImXA2L7asC+yid+B+IU7T76DcGTqKL6dPZmhL0bngkxhNsR5Hzlu/XHX",
-	"PEi2/QPE2K6+72yUBXZKNRwZVPccCTd5cwH+QC6z7soPy04Cmnxd0f+u9hu9aLVvr2Hjxw8lOSOpzfWl",
-	"/Alle9Tv0cnLH/hFol3DEGum/TEEt3pLv9Ayx3fZ7iK4RiREbj9CHAUdOscneg8Y73Dxf7X5XhPhf4QB",
-	"3+ma+lKTrY/jpxqWYwj6EcK+s7lpuvmFe9gskqidNi+jGsnXWkHXSnRILlKPSvQwQl0W3uXZtKftbP8x",
-	"0rn2izYfLpHIt20Uk5Xy7qh1n4GvR/1SWGJvmSrUfvE+q6U+v3/+vwAAAP//q5TSPdRfAAA=",
+	"H4sIAAAAAAAC/+x9CVfjOLbwX9HLq3eoemQPgcA5dXpC2MIOYSnoMHyKrSQitmwkOSH08N+/o8WO1yRU",
+	"Fd3V82bOnK5gy9LV1dXV3fVHznBs1yGIcJbb+iPHjCGyofzZvO3stqotyyFI/OlSx0WUYyRfUjTADhG/",
+	"TMQMil0u/8w1gXoDIAPqTQ+ZAJMuGXLusq1SyXQMVoQTVoQ2fHVI0XDskhqqZEGOGC9dM0T3PWyikscw",
+	"GRRUj6wAxxBbsIctzKeFV4cgVhxy2/pvwyEGcjnzG3ZJLp/jUxfltnKMU0wGubd8jg0hRY8TzIeP0DAc",
+	"T084Bj4BkFI4BU4fNG87QLcE7R32vhm1myfJ6RgOYY6F/PEL0MJQzUGCjF6g7Voot/V7rlKtrdXXNxqb",
+	"5Uo195DPYY5sCa4LOUdUgPrP38uFzYc/KtW3T2nTteFLW31UKZeD93JyMWwwx6OGWtU4BJGhE0NE+szn",
+	"PIKfPaQH5dRDb2/5HEXPHqbIFF1qmnkIvnR6T8jgoqsQrW1DbgyzCC5lyS7Vi7+c4EK481gBQcYLFfHY",
+	"KxiIcAqtQmU+OmcrVivnczYm/votgen/kPevQt4si747tWvXcqB5iZ49xPiZXBOWJHTX61nYUGvXh57F",
+	"c1t9aDGUj61luw/kc/CZDxHQbb/kAQSWQwZ54PT6HjMgRya4vjwGmAGKuEcJMvNgMsTGENh4MOQAvbiY",
+	"oi5hjkMQBXwICRA9YhsOEAOeBBqZgDvA4UPRAtIB4qzYJe0+EIgQY7KhQ3nWQGoIBqCADIhVSRuhSxJD",
+	"gCaZOgTNPhEDGJAA05kQ8dWsn2KY6/ccx0KQ5N7e5q9Fh0PupayBR60Ukoitt2iUsdbLrXTWGao42mxq",
+	"AM+QBLiT9xebiTURrfSRKxsXeh62TET1F6JNsUvOiDUNNWXyN0N0jA0EoGU5Eybx2kPAGDoMkWJ0y0bZ",
+	"2PKn6zJ7/v0b70/a3/kcI9BlQ4c/Emij5EKdQhsJViqQubvdAX7z6NIJlDOOKDJBnzp2dPkgoJCYjg0c",
+	"guI4t6cFv8ec5HjHiAz4MLdVrdflAeH/XcmnkGo2XWZRPbRxBIviQaFsNGrljc3axka9vlk313ppqz+j",
+	"5AjFTJA+BOdvJDFufq50QI0h5sjgHpULlAI6NYbR4V8a64/ra2nAyoV5FI/lpwGBzL59NpxJNe3T+DFD",
+	"keswzB2qwYiSxzZkCISbgL5DJW0M8BgRYGLRc8/jUl4mJoCheRZzIdr9RFE/t5X779JMWC9pSb106Q8w",
+	"TUIYR7TAUhQBsTkswn4UY/PASqxZCvqanon5JWKuQ1iKjmFCDpcfL9xX1oAWJqMUwu9jyniUBErQxaUI",
+	"Qy2NKyUoBvnNwjbmXyvlrlcuV9edfp8h/rWcRi8W/OF+K+WF+0eBr0dLW0EbcZicteTSIc6ICUcDRBPd",
+	"q3bJfmPN5CA+ivNq8R7ii5yxe9WJ8Ug8u4doKrOGBk89K8+I5MArkiIYWsmDFUNoEeKHiSzE5S+KGHeo",
+	"eksR5OiReb2gm0eXOn1soZV8l6x4rpn5HjjU7zW9QZyDa6jSSEPBYT5CnjpfbErCdKgtGuQ8D5vpjFeK",
+	"GI9DyIZJ5BygF4CI4Qi5oXPQLFTr60C09M8s/TXoOeY0D5Dt8qlkUQa0LCaFLsfjAMr3xXStWklqj9hM",
+	"jt42/XE0GvJALo3AYhh7QGNPNlXrDCaQARdRMX9kAkcMvRAXHkPUP6dnSyCeLtxBssMYFYY6DMgvPN8Y",
+	"8iMrKun+1aNoOUFQMeTFMoYeIRB4TzyxeGiAiZKQIbAQ54gKDKtZ5AEiZvRlXr8SjTxiIsoMhwoZnpjA",
+	"hlNgOIRDTIAjREb1CfO/YfnQJywvlgg7JsuLvoZTd4iIUAuuhghwh0MLWFJAEUKQ5G1Ki1gvA2MIKTRE",
+	"z/Edc4yJ99IW84uKPOvluMQzUxU///N3WHhtFu6Fxvjpy78if89+Pna7xcLD/4YePHz6ksq1HQOmc5tj",
+	"/Wa2gZT0CQbU8Vyh7iCKMgR3gd+ZKFgEV0PMukSwS6EfWRATBobORGDIwkzJkD4kbCumjtvYoA5z+lxq",
+	"44gUPFYyLFyCguhKmpJ/G2M0+SofFQwLF5TC/t/w1RfRH8VAj8EgXbITUyysufONr50Q95AnKDu2dmv5",
+	"iGIPC69iZVY/pXM0NcSjHGL+foiCk8A+GzqeZQqdxl+EOMRXjmdAcqm72ZcjpsCkIUplcjs+MBoUPoQc",
+	"TLBlyXGZInkBqDVWsHFEIOGSHCJcsL0jNsOOA4jDBUscYxMBqJs/YjPONsWjyRAR3RaTAYAaiEecmKnS",
+	"h9LmFu0ya4YRUJdC9G0CtuhIeQAt5oiPmCd6c1InLdBkKpxgYlieiebNcg3VzUavahRgr7pWWFur1Aqb",
+	"ZaNeWK9Ua+V11ChvonS53h9v3gLrhVti8nJvg8TWlgaOPiYmwGI2sg95SoBzh3JoLbPJ/Q3O8RgVTEyR",
+	"IST/Ut8jJrQR4dBiibeFoTMpcKcghi6oWaQsT4CDeQsTJ8D3LU/d2ED9em+9UDFq/cKaCcsFuF6tFsq9",
+	"8nq5Wts0N8yNhWd1jEGkaiyzozdL140euRGdG+vTZz4YoQ7SQJiZsvcgtjyaot4gSh26SK05uLo635UN",
+	"M9Rs39ZcXQJxAyXDqIEfIlCmC+bYjA5WqdaQMOIUUGOzV6hUzVoBrtXXC2vV9fV6fW2tXC6XlxHV5k2k",
+	"spy05psLIrPI1iWl5Lm89hpDTIoq2YfYQuby/fhkEMj2Px+rbIRdF5mPma4S/UJbZSeIIrLCgTiZbVcI",
+	"Z7DPkTJQ9BW0eZ9NOVSYFLXAi8yoNX95F8dc64SclF6n6LJmbeLvXlTVYdbSsmC4KPpWNFZWAO4DSKZa",
+	"nVGUkAcrzDMMxNgKcIiyqqoGDMgXyBSNpH17ghnqkhXqEYLJINYdZkC/kNx1xUXE9FsR3WCKuOatxLMF",
+	"7vTQOUWXgs7yOf1hLp/T/YUYlV6SfO6lMHAK+mGYY6r/JtZIo2buOv3SrCSfU6f042yR55FM5BCZx4fi",
+	"/QaI0bqfGMgh6Kyf2/p9gSEr5HZ/C3WTxdk+BrHJqQagsJ9ksYt29lEmO217YB9gtZvfdSW1b4JeeJKz",
+	"SJbrCDeM2PauECudvuYeeQB7DBEOtEtIQCeb5PJLQ1hahihKarzYbAyPMod+RdNDt/3k4JOr9mubpE7N",
+	"pWicPjWXIgOZi6cmF/UvmRtuP7llg9xY/Yu/ubk1ZVclQfmZtsfc1h8ZjFG9ThjGWmrhThCHPr+Igucw",
+	"ThF6NBzbxjxVI/ssTG5ffMVMEAUHunkaYUJjJDzNya7O1RtlcFEKjKDS092by+ay/hfdRzCdNDEnqR4o",
+	"HIQOB2iaWEAFrfMQMrTnP7Z8HuOOjV9hYE6cy2ajrd/yubDfadHXO6G2bOY9i6AxzClPptJ4txN6H3Nd",
+	"ljNdckltTPcmbD7Rbirl7G404aWFv/ixL+gFGtyaAilOiY980bYIDuBYkIDt0NgrBqRRNLBmCznM8ChF",
+	"RPQkNGDmua5DuW9mWop65Px8MojGtbw/BsiCPWQtHPJYtYpv2ghVJHD5MI+I54sm3ydpqL7nq+/LyXAS",
+	"xb4IF2Zb87dNdIemq/+BOOx3mgB911fy44eBmWLr73DYswSBmYGJU+rqecAQAjNTQPhwbG8Xzputo+b+",
+	"buFyt3N2fH3VPjtN44Im4hBL6ghYfPwkEkcXY3CQApqI8gJ9aIi9EXoTATP9kIBM8YiUVzYycYal/VaY",
+	"UXVEiil2HewJX9ScsdLPIDl6aFV+mvAa6+4/4mtSfNX9/wwB9s8RSjMA/i6x9P+CqJm2Bz5I2IxKCD9P",
+	"FpXCUSiEJiUYz38nPKN9PPCocolJR7n8PBLjU+ySJgcWEkTukICPr/QgQx61RAiCjQUHEzKn/AtxKFC7",
+	"AmZ4A7bHeJcIE7yLDNzHwqnQ7iupRPVoA0hDr/MhM53T18SNhB1KeBnFOyYcZ5BJWVfY+nrOGBVB21Qh",
+	"agphSnCJrp8GPBZf5zsqDJMUKTKHUDkphPMYEV4SwkSJDpHVKDVKKhSrJDpyWMlhpYiRcLb0FC8Tc2UM",
+	"kTF6HLiD5FrtEnF+MjBwB2CMKO7jqPvSVwTy/oQZgKLxCE2LMecn9ZDyrvkNBJqYb3aLh5n6UAlC+D7Q",
+	"6IzMbK1JhKHsEgVFxuhI9h4+00MvRWxF5sZR3Sbh3T/fByM0ZYELk+EBCRCozMKYhcAugl1oSFc/JF0C",
+	"qSBVE6hgZtGTjBEQMxWUI36Lf5kM6BVeMcTFx1xGSBbBEZqyLlFRclA2YpLcZTOk/Z6hyBKfhqVxuhiP",
+	"Ni+I/23v7rdPwfn+OTi/3j5ut8DR7h3YPj5rHcnXXdIl9kX7dHu/aXQMZ3u3uXPcb9wdjNDr4To0rZO7",
+	"yQbc329bh9DijcOn6ktpu3q0Omz3297LPndvnjZQlxxfDnauN9af4FXdvdmp23snhzV3hAi6LBlX9vPz",
+	"xeh0esGG36rOxbfJ7ut1p1dpnZ60+q39wehb46LaJa/3I9o2WnSvfFGd0KOeBT1zeL2KbyBp7jC70rjb",
+	"fWa9evO6tmHya3pSu7gzbwebl6vf8Hn/pnHZJUfbT1fl2vhm+8w86bC72uYxbJH1tls5G7uN9q5TaqPd",
+	"m7vKs906O2/Co3Lv8KDm9QdrLQ+N2OpVp0smF7dXqHX84t0fr5+dfHPOzo8m45OL/ktvUPm20xh79+Uj",
+	"/lQyTg+qL9Arv9is6W0eHLpoND47v3yxumT6zJ+m933q3GC0N3Un94PxxYQTctIoDTq7Xunw5oreletV",
+	"e/f6aqNl9DbWRsbB3tVe/2RkkdF+qUvK/eu15iWsl9cOai9P5RHvodr4yDj/5pyfeUfbN+ygMy6Xr/fv",
+	"mtNz5E1XGxvGdelud3iyMap1bo6eumQdte8HU3xyVp5Ylbv9ncsjw7MmI7bZXPWs0aDiXPXWWO3Vvh+f",
+	"lzf2nauX27XqEzyq33ZWT4f3CHVJY738zbkZ9ozKkdtZferfO0+M7vL7xnnv+n71brzXuHSpedukTwe9",
+	"w1H10L08ar5cDV/YRZNtD/crXVI+9l6qt/Bkuzyotuvnxol5WDKen5xywzDo0/Y3D7/cUlzH3ubJN7fx",
+	"fFXqd15PbWa2B6RRer4/6hLcuPCsvrex4T0Pb0sTXu1xgvngkj0/DV9OvKe767X73tpwxPcaw6Pr0rdv",
+	"G2vV5+Fx/WjSvGxeNLe7hO/s7d/fXo4Ne3dwtHNSOeo0G/f2zahXOxweX51Ujr9tT+FtZWgQq+k/Nw4O",
+	"x9C+eTJb9XGXGLaxii8Oz7a3T7ZbzebaHt7dRQfrNh3uHWx4N+zi+OSkWr6rG/dD8nLX2Gvacg+19ieN",
+	"vdZk1O6S7Ul7f+/COWw1WWt7+67VnOy2Dga7rb21ZrM1GF3Mvl49vWuWNrbv3IE17TTv7w6GT9OjYZeU",
+	"Vvvrr+f9m3HvoFrefa6N2htne9unZXL8bXX7umJ7487q85XXqd0e0+2aXdv3LO4eXe4eHh1zu7670yUV",
+	"uv/6relcVabu5l27cdzcMU9arbPpU/OJObfXjY27a6+1WuqRJ3qFLqvHl2et/vS8tbF+u9mo47ObLrHr",
+	"ndUeu9iZbLSqx9QymydrJzueM72vdDDfh/drRxfHN3z1ahdW1jC76+y3nl6djfO7xk3t8GxUL3fJ4Pl2",
+	"0Kielnp2dfe1s3HVqN3u7vQq1vhprW2NXwbt5yM0qFRev9292PSuc3942OqPX/ur1mln3XsZHHTJ00vp",
+	"sDy17qvHuLdP1/ebzenZ5vUtbd53Jp2T8q7xdNWY7LbIy6iz402f7dvJzfh0+5u3275pnKHaXZec4OtK",
+	"//C0wcyNHZftvdRPVr+Z5IRcdFYP6NPV+dFOzb6lVtMku1dD8+6m8XQ/cm+HO1NWK21uorMuGY7K9JhM",
+	"y0+nkxH0+iV83Tgz1r+NT0ZPx5cnh4P69ebN0fTQu73lr5Nv5OnktH57ubf9fLTG7h375KRL+rx3dVBZ",
+	"rU97l7elZm283YMvl7dVvnH9evpkvKJR534Xw+PTzePSgXHYal9WLvYa643qjtm0dvc2zS4ZVQcX+K5z",
+	"0YTwsHx42Hw9GF+OLg+PjwdH1buLO3xwejOt8trhdK/PKLTrk07r9qw/PEft6fH21f1hl4ype2qd91Cf",
+	"XW3WN6761e3Ttjd4vaet+s3LTudodD+4HFZu9sed9gVpTV9HF9P13evq87mLb+ubgkcNz9vf7umRYxzV",
+	"jo47myX8enhxdWnxp5Pm1y75et6/2ugSebrsnu7MO3re4zSN6e6zZr4cGVX9fDlNyZys2EemQ6FLHSHp",
+	"Fx06KPnf/SbO8q/qfaFWVWqLiBj/GoTSLxLVZoJtEogABvG6KPzFDpPj/0aRkJbR10aBcYqgHRoZiv+u",
+	"r6knEj4RU3/WWQKWTInHpdihmE/TDSCMWY9SPpumCVMptqo0u1jCPptmv32MJw8sZ3eIKyxpoQnYQmzK",
+	"tDa2VLd7s0+iRshqI61/l2VJuGCvfd4BtmOifChGSsaSegwxHcY6dbkzoNAdYkO09SzEumQMLWzK+Fah",
+	"Wsl+KmvlIjh1OEBjRKfJhAnVu4CvS7T1lQGcKaT3MUUTaFmLsaHaRRZSSssi5Chl7uq5EGSVfinjJnyL",
+	"vh9MKUMBtLgfN7Yus0YqPHFx7uPQYTw9lvhAv/GhkOgT2XviE6DeUABB37OsKXj2oCV1S2A6NsQE6Fjo",
+	"2b4WCWSIFvUDoQDGjf21ROSnDsv9PPtdePijnF+vvIXefvntc7dbfEfzL/+bGkw6QpSghct9pFrpyF8L",
+	"LTSgq1Zv+ZzjIsIM6C764sxFpNNqnse9USElxXUYH1DEnq1ls4QrwrGZXH4XUi7pDpPBo51uZ0YWMriK",
+	"NlbEOJoFLAffC5vDCvS4U7DG9oravhROZg0Y8IiFmLIvUCQ1NB3fLw0VtjAvuQ4mnIEeYthEDKyUVuTm",
+	"UCFIBmRIaH2y7+ObkyJYEWN1CbQmcMqC5yKFA05WQPhxFJRoOAyFk1w+Z40FPfozSEa/SGRNZdTGd/Hi",
+	"+Vw4HCq5qKdOuG3sWz+5ZE6OBUzPp9C73KEDSDQTU8HIKgg9xJ9FgoBYFsYRNLtEfxjpNOJ0FBGuKldj",
+	"rLpVBhIRTSYTbcxZxq7W0NX6LDTzcWyjV11wYh7Crvx2Ou8jhSdLn67TB/K1CsuGehaIStcCNP1YV2VG",
+	"mwpjMB8iTAFF4pEIowWat4vjptM5EDNly3Js4TRZyLDT3MNhr2u6VTXTAXuJTHAAOdglHFGXYoaAzKkA",
+	"ny8Pdo+/gEZxbZ64NOtIWO8KjbWFNmp9KoQBelgwJcX7/L0qx8nl9Y8CEVnp1jSXD0GgftWDX+vBr43g",
+	"V9DFZvAj3tdmOfhVCX5Vc/mcEkcLjdlP0YkvC2+EfjdCvzdTWUpkomFv01Ikk1j5FM6yF5Hv3hE4YGPy",
+	"yPBryolwggm2oQXEW59vzMRIkRwEtJAcZA/1QW/KhURDhXlSzd/PNPII5nkR9jgU5rturl6pghO83c2J",
+	"1t1ctQz2t7u5eHi4eIy3c9LvjW1BHeV8oiLEKvjt8++jo5P9qwf82/a/tr/89ikWxjhDT0fMVey/4CBK",
+	"iQnoMcfyuDAO8yAxbvZBETSFX0sYzClSp9dKqec4fEVyBD5EXSK+lCbRldIYUtnCcaVtv8ToWP7rMfUc",
+	"uq78V9h35Y+hYyNx3olIzxK3xVv0YiCXB7nCUmz0Oy9Rj6iR1Z+WY4wSWYfiTTxcohrNwSkt3NYhnOVn",
+	"hJO2s9Ol5fdRppTcU+JzxGNBM+I9oJAI2zN3gJC78sEZg6k48rhjOFbU8FutbnHDzeVzNSEqFWrVjfWN",
+	"Lc90F5QcydffPhdmv7/8tvWZG+6/PNP9FzO4+y/TMNwvi6qSpClOuuACW1b/6PjtUw+JRKtFKI/i1v/c",
+	"9OtASMzKWhAC4yYi04QvyMQscDSE8Gw4xsjFPBOtqdl4xdXCw/9+DxYRSYOBseGfMn7aSuy3zn9uoie2",
+	"VSAREC5WwZx2yQCTQGHDXDoIZc6RDAOSW0GH6vu2ndT0UGeCqBS6/VzQcHpo7GU4i1Qnh3ZJNDs09sUs",
+	"T1R+4GeEgvkJoV2yXgtnhIKdtPoYAmkpBTL8ZJ3Ycj98/r2g0wxn2qJSFT/NjyJfXIolWBzuqCy+IUyv",
+	"7TGrt9Il0dosqgvRqAh+QmkWQWOy2EbtuwufHWtpWRphwL7jDCzkl4eSk5G9pBPnqYhXmikSxS6RrkAt",
+	"EkhK9ckGBh5r6tO6HkRacYrgRo6v9ATp89vqEgAKYEWI8Vt/IBtiC5tvK1ugSYD8S4jxFDEt4lPkUsQE",
+	"N52NZYguQGxSRbDnUKCxmAcr0MIG+kfIlLFS1CPrtWiq794Jgxo6WM70se1pQeaFFKDr/gO6LnMdXhzo",
+	"j/xvwiBJneS92NDz91N3BVwxFJg2JiwVB8oCtPWH+lcMKPb0Puh4mCPfPvTZpdiGdPolObhlqQHFgiuF",
+	"TK4+5PrbOEYGElYJgiy6kIAJiHAIGXQZjYCYR5yYqS/UflVcj0xVbz6Wk6XcEN1K0EYun4tRxbJLmNNG",
+	"xK0ksnP5nEZz+OFPLVGWxgoe5h1oPy99UooSov/HeMIKZAYiJiS80KMQm4VauVav1BYKqKHu8ouyMff9",
+	"XPLoLAYxUCrlckUe/0r7WFcGtkAbqYTtbSGfQRINcokryWPp8UGfQ4/SilmrvH1aOM/MSc3iQX9CeKnS",
+	"N+SfLGIeltH1MkxOE3MRXBMLj9RZoIJKuwSLjYykAcgYCjEd9BCfIESA9u4kCj20twtnnavL3eXCVv+M",
+	"ANJ8jmNuocWF31SzALSH8Foca+dXSqrv8obFSNLv3JxJ3bEAIRJJ/j7tK1x3KonJ1vl1pDJVzHugvIOq",
+	"fpVy10lVeRYaHwuL9+0+gVdRf5VqTJmVqloqyvtK1rQShnmZQrLQLN+5okjZEF1tBl4qNjwi7KdW2Qqw",
+	"GZlCYpy0bR0OV/++nPGUAPRIVutySaP+vg+gVr9967HOJ51t6FlvH5hyGcquDC14aE5wIiCQVRJy+Rwy",
+	"B6gQJAbJvzBhHFoWouIklsaBgViK4NiS/0ZajZk7RBTNfhWcMczl/bJ1wogYHWf2KNLN0Ewl8aPAR/We",
+	"HeuKhUrLcBl4NtIiu2rkayLKFybTpMRetTCJOvOIw2z+te9QI5ZrUy2vNaIn2T+7XdLt0gz1ebF6q0HR",
+	"njABoEaU74LwG2ptCjgxWFUHBcqjR6x6/LkQVJhJdQumafDHQeZM+iL8kSi/mHJQRReihzmFdCocBqUx",
+	"tDxhYcRUKYZwEIlelFqTDHqUrBNyYDuMg4hanJe6N8OMi7NYOH0sdwiJZyOKDZYHKwVhT3wU/yn6FsIV",
+	"pbgzDqkqODPT9kWMZqiD2UCxc1rsrDGmDhFUJecLB4oRDDB/ZEOY28qZ6xvltfVyb623BpGB6puVugH7",
+	"dWO9YVaq/fp6GW72UQ1JVwKCdm5L7kRtaQljuVZNW5rAJ/uO/TFC054DacoOOdJvgAWnQiAISpTJ2sX5",
+	"UIR0bwqUP9jglnxWGKGpDV0WIUWZ/jO/2JE2OBUyKh5ZkAy89PzEY/9VaI/kQzkJuhxAeKv4vcVKNJDH",
+	"607x+mpP+jhM9Lizq/+aYzN7+KOar719fvy9Wbh/+KP6pr3yzcK92l+Fh9Uvv33+h2y6+uW3Bba09bXg",
+	"9RxTmj6SUyRaGViuA9Fje01GEUuPcl5USWCI5wHWoctCrJVhw2Lb6F6KoG27FkZaS/5/HrX+n47vFus/",
+	"QZaV7xJdJSNcykl05odnSxNYRlU8wVxT06i2xaHq730bqxQTx/WpUKbG6IO071lBI/WWor7MBOuSkpJv",
+	"Suq9trC5kCLC/XlIrg/9tFjfWQE8aoXiUUTPmLMu0Zworz36MtJdWabElMV21R2FY+c1TLJv9CKr/zDA",
+	"A0iyonDU2xQ5UwnrCMsS0NCH/LMm4y1Qrq6X13pVE66jzfpaz6yt9Rq9RhU2anVUhxsbZrW3Xu734Ze8",
+	"CmPpUUiMYUEqKxT1EZX5ELP+xDk9S08Qk/wSU1KSLdKNh/2k63SJz4bMTmJhB3FEbUyQKNOCNCqUVS7i",
+	"j7chgcId99mAxLSQi8kXgE1EOObTcEqHNLBCSVHJkP2WQ5gn3eFip8mcBMSiJA8ZMCwsCCvaZohIlwQb",
+	"K9gUghb8XZax/pnFtTOYQUsR3k9K3Evt86Oy96Lb9DeK+l8FbfxPda/xP9U9RR//U90TFKJiHH9+lt/P",
+	"AeFvX4U2e9lTIEqvOyBTfJhnh6qa2pjrxKtwyYJY2dNgc0RYy7LMLG3tdcePS+bORTPwwv72aqVQ3ixU",
+	"164q1a21ylZ57T69fkI6w1bPo+jI/v4xCnUMtxpVQaKW+ELZpcK5PgK5ojSsqvgQOzv1mbtUzaDvYthZ",
+	"daFUQqtjWUjK2txjMQrILasnp0k3ocywGWokFlzPsrTjaeHuDCFfzT+RCql2yCXq/1xOG+7wg9ksRf2P",
+	"yJNe2Pu/CXNMrFQGZ0zZAbOYIN3ErxunN2VIgs2lWdAFWjTz/G4u9V2bOlEiUW4MPc0EXBJXfijvOwNc",
+	"5gRxYmnvCCUL68ap0eKqFkTJEWHHpT/Cb95K/ncRfvNiGGb/0aGDImMDPzhAi2hBbKmBWXp1b2w58o8l",
+	"A5yvgg9S3DU+AtLo3+/g0rNivpS58FPPQo/ajvQIsYkefZObGTUNBb10u6F+ut1oT753JkNTT87yfSE3",
+	"YgzlTFcB2LICt/69IGFAoy8RkaO+RubyXDmM6EQ8eZI3e+SDh0gV/AV1J9No4uKHTkaWNq4ghkmymcAx",
+	"FEcgIAiZCSxmxOQLgpIR5GYsuGlhjtaykV6RCK/IdvEhCvX1kIWpc00cGZlGy1aKSsN63LD5xzKhw612",
+	"B2TGITfANiLG0IZ0JI0Lx2iMLFAFBdCR6SQZruMQ9/wZvC2bO+XTIprzcVQuWovlnX3hr9JISdcXSy5u",
+	"ZoYb82wRhrHYkakn6rd/mI2WXZzNv2knMSpynYw3c6p2SN9w+iTwwDbrWa8I9H2VGVsx5cUYUZZOwzG0",
+	"yLcBGfifzcDN+xfpaBhDePtZsrO/6B8gLPucJUNSVn+F5YpisVj8Efl5/oCVd4z4nupDs1of3199KICc",
+	"IZmOOsZ2EtivKo1glrXq+3513myaVeVj6hdlTfld9Yv+hDn/m1RASgHmEgk3OWKpl/2FXi3SQvym6WOE",
+	"ixUtrtXzg6V6Fmda/30K8sy7TvNjC/Rkldlp6jo5otzOrFbO0kV38l3yzqo6IFFUR1vxl6uqk1h8PCAO",
+	"RY+MWenFh/5TlSDV57OgsIBslrb5w7keS6VmjEzPdt+nu6TnV0glyPyB8v5+t/kZqKkzjKXNxm+K81NO",
+	"C3o/Ra/LRAZF0k8fXgsXMjZxaKopWHC6QirLTHLMVPonTOQYRjVHeSluymYIZ+JGPqiW18q16lqaaYwO",
+	"l7gLWCWcQAv0LRFa4ggGAujQSKT7+vl8yrctc191TjXS/K+tJxSLA8makioBkMRg2FdZFOQcQuTCQz+C",
+	"p3x80SODhlYwtBiLCCtTYf5RcvhRV8uy1RDfQ3VJ/dyljukFN9rFO/8BKg2aair9KHrJ59Q1jd+L6LQa",
+	"kVrdi9HeQlJTc4+sfAS6h3TS+85A3QT7i6crqUmpo1yCpC530vaNvHohwYu8UMxBpe5r2WqEXA5wX8Z2",
+	"69R+xNOuKP61ee4C6o/XFJ97q/JP5eB/DWv9TipfkqH+LPNHStcfYQoJY/IjPIdL9P+39x1ehSp2vIOT",
+	"Ee4qomPpZYpkvqdM/W6f+zltyhtyenUO9Ke+pJMdNcyiYZDlous4VpFwVwj5uXyuslktlovVYmXpojvr",
+	"STIMFy3JDnr2W8kQU+0e5a9AILIHYzECuzKls7SNqIXJchHM0ZyEBDU4s5RkSKbLXUOUmtP8ll/4Xaf2",
+	"XV9mZVEvHDHzot23hwBTy+Qc6OyRdGuwj8CHTNxn5WyEUL/0DVCRHt+B8iW/iOf3vQPF/hcP35FSknkP",
+	"2U9YpuAqhvh6BeuTkSuikkD8jBE4YUVWS4VQVvPJyiHMuvpap8TqG2r1Q30n9Y/mHWZVo5PZjiwYSdZk",
+	"BjbSIRr5oBaXKp3hTOS1JtG0+8kQIUuWfZG3got7ihkXfDjULXcAcdSFdmp6LJE9K7uZF2K+VELkEhXv",
+	"bLRkZRfRFAQ3o0bz9WUxlpI4nmuGOH7kr5jNXDYR069Ehce18ub6ogoracKo39X34SWQspNnaOiad79V",
+	"mPzkKTRABFGocx1kVcbPtS+z0l3iynhRuefzp/VPX/Jd4l8h//lT/dOXPJiKAacuB58/TT99EUd1z/+7",
+	"2vv0BdiIDx2zCM4tKI479MIDSLThkaInGVkQ1yo+rX+ijkdM9lWg9RODFv+kL1lPqW6ALGvJxbecASZA",
+	"fRFZ1R4mpZ4a4N1rytjwMVUZE9XCQjbdCPL1+a/UJfEKEgA9PnQofkXmo6y8bmGxK490LSAVow857mEL",
+	"82ledAT02MyXgOKIZGxYoAyCZrPZ3K6dvsJWZc4U2KI5sOgkkCz4vuRMooxBAIbMar1e2ZTAtRRw1v1O",
+	"u3J6tVsXz9qndP9ol57c4dWTk+uJdwAvm4f25bHTfr3sV593quZO/bW8ffVSWn+REP1j6bz5GT+pp4W8",
+	"/Iy08GWzuG9mXunowbK0u9pv+PD2JpXjvpOyjroAhS7MYAmLXyi9O7jjSkr4BtIanGJXuaYLjSEC1aLQ",
+	"VaQ6GhjwJ5NJEcrX0mquv2Wl43Zr97SzWxDS9ZDbVii7OtcOq0l+4E7I0b6VqxTLfnVN6OLcVq5WLBc1",
+	"lxxK5JTCTkcWi4OTxzJSV266SF3X0TbFwYh4M/yd7JFCG3Gph/wex1q4Vxmrosym3AGW44yA5wI4htiS",
+	"WfUw1nFaIT2swpb40Dc3bcVv3Zqtq7IKKMknjQYeRGOlbEuMVMvlUJ6UzhG1tBOr9KQvYZr1N1fci8xF",
+	"klUUMRD41RUzEODnG2EKIGOOgeUpMyvXq+S3IFBFLJcqBpPRSejL0JB9meajPFoRRIrOSypsa0YLcVMZ",
+	"96iuthNkP8lbIoVQYCGO5PAUSasYMKBlMWBDE4mzMlTCRL1CdIVFCm3mu0TmcFIknErKBa8zqsLNgCwD",
+	"IkCH3KGq0E+QrIOAnII4thRjT1KznOICKrbhC4CynJtcMNklIpxixAL5B1TKZZ9Anz1EpzMKlWaLXJgU",
+	"A0uWspj5fLES5YppLDEOWgQYP46hL/P3fMCywFLt0uEqR0sIJuD40L0jphSYwebvnfDsBcNbK9d+GhzR",
+	"ihQpcMxI1y+MA8kc2szasD6FignZHld25ehuiRehVdtT7rVCT9wbLTg4Nt8y9+osqUKKHPLL4PJP0YHK",
+	"cw4/EeItBJOhY6G0jZO4W3zBHpopdGpwOUY6R8fmXD7+0y9q/khiTqAphY5CCNHZLymkMltBqJsGlwCH",
+	"qGFpMoDq28yVfc+iKvh139wBA8T//Rc2VmQjY1GXWk4/WER+ohdTPVKFPRnPTveSx7rKXlZuJDYLt5EC",
+	"6ayIbEh1FJVmIqWnZXEEKFxUPeXJ6uaUFn+5u9NsXe3uyL9QNwdcCxpo6FgmorGRGKBSIlDKsHgBXZx2",
+	"7OrqKm1dL0R/vu2Y05+37eJ3jybWR1/mKeagRSNHzF3jNUmobwlaqvx8aLPPPH+9h5CpahTIVOdd+U8+",
+	"7zQcetHEwWdDS2xEZMbIPEKiEbJm8/SLlt/mXULZ7ObJv1Ye8+H480SxBAiOC0VBUxnhJrZ3kEjGYpiC",
+	"DPSFkchvIYKDJW8QIbNA+qnEB7BLxAPseAz4W6AIruBIzHJ2S6IzlhyhH/i40yaqQlhz89Sy5HyEyK9Y",
+	"SwC8viXEBFDmAMG+Cs/BTDqJiuBWOOmJLoTAsICPyFwhLmqtQYqUzpNXVVhDhM0ihSQqa8CEuqaMz9yy",
+	"JyfHyaWfaNVytVYobxTKlatyeUv+/z58gpmQo4KAPZf/QYz0UN+haIaMDGAlKuYBWyl/ILASyZglbnFJ",
+	"ATTWZDleFrt0/Z1QzXRlcbsBhgwpErChCvPUFU4yAI6UK1sO3HDptXfCGq+VlgJQrEloxZcvJLckVNry",
+	"jRlQFcsAhzQkDsZpULZ5H6piHqzloBoG55C6o0cX09X1PNQc8/ouFcIQYZjjMZq7vn7mTwD0grv1341A",
+	"JSEK0U5QJAulXoTz5POqgL76Q5ZegtJmwilGY53aHolI1i0RMWVp+jwIgpMo6lPEhsjsEvmV/oIWQSuK",
+	"SN2FSKAnSHB9DdEQjpXw6JEREU4xDaIqzs8cYDpRVptgWZG+VTBVUFDHn7ZgsnN4sK+3JKn8h4v15XN6",
+	"YsvtDxoRkbVknJRTEkaurJk5NENCCEXOPQoYQiUik29CT6B8MCPn5AP5zfczA0ktliwMp3inKGMcrnYB",
+	"AUNCyBNEoMVtWXtPXAEdfiDoXx/mKyM0/SqrwYlCbSM0/a/IXyvqVmkfhCEc6ytpukQCIgsbrfxXsqUw",
+	"3+jWeI4AIzt5VAm9Ds04PzmC9leRLp8PlX77r6+zsLm8X/ot5Y6JP9U6Eb/Sf661zcdVijorlFhL4ETn",
+	"ewQCY0xfYMGlHJYzGCApekpffkQ9KP2hf7WVLUNZk9MqPYnnbKZC52NFAS0LMC7+qwukOxMo9N9nz+Gw",
+	"CJraTB3sSl3tVrhVdSSn6VEV5Tmg0EDARRQ7Zl7Jm6ovzIW9gwGKbGesC5wNHOG1ldCpr3X/fkU0cWxb",
+	"FEFzGkBAtTF9rbwmBXPTQQyYfg/ClKA+9WsEoxfMNJlGNSiFk1ZQNiWNdmKh70dKk1z78zRJH99i0ykV",
+	"xKFxlMSozPco+LgUMC+ycRF95hsBNrK0zcDc9dFbbY7BKHJAzzcZxSf2tpydLkBDim0u2HF/sokua98r",
+	"e2a2AUxaKUNbX7uG4oIUAitwwlZCIn2yrrI0vGGS6iKSw8x20/JYlldZahPrL4TuDzK3iYkuZ2xThoZJ",
+	"gJs/0cqmgJxjY1NkELWxRU1alqyDPtt386lX+WaWp+E5RMsd5blx+uGbTvLyKgYBs7zeVUS4uMqcwYSa",
+	"M4HTIgi7aXHMxRO4EISe6/i3j82cNML/1CXiSJODq1GL4uKIUGaEaipkapaffZuQ7hmHU3ndJHD6XRLA",
+	"sKXno5ZFRdpVyxsyRZNyLbzpkfUZKIZCpu/b1u9YUG4UUSROSMg5sl0RGwXafIXJSDxxJon6pkRdiBtO",
+	"7lazmMAA7kXcYFs7sP7DErJCandb1Zn7K23D6ZUL7gDSdZkjLIL9+TxCwvtuRpHPVcsbfz0gUgBljo0S",
+	"rCLYsrP9Ld1Pem+pXTWf4anKS2NEoeX3u4gHsoXxIyF3vmWFGchsP0cmFxKn50h0LZ943rc/fa+8BsHp",
+	"/1J7Nb/ACaJ5+V/sAlGo+3UdIP6J90u6Pz48GIEt42YMBRZElZBgXywlBdmhIkipPMBvoDb28vpaUF3p",
+	"Xfs7GG1ekMJfeQx/rO4ZIG3OwtuzNvGlD7CXqoFm0oC2pWRLwZeqQUQOVpwfMt8YoG4VEBIqVJJs2B6j",
+	"HXyY6+byusAhlHYSFzKGTDBFqcYSPfT36nf+1P4GdPSXm3yIE2DOt3mFrtyMLOfE58wxItToBjBut1PU",
+	"Z8bvx86KMIg6Bz9w36VfZL1kNLAZd2GmxQ7OaV3Swd9FH+YsdJypdodMx0//ADLiCbApEr+vjArnr2PI",
+	"q3YyJqfhB2KY4CJKP9+awwELkmof1HznFnSdh4BI1cEfjmiPLkxqRdngxva/Szx7FEVzKThcfjdhVsis",
+	"ONol/jciR86v3xotGRm5No0Bj2llxu6SDBLy66iCWedLhr5Hi/4vFQMfvuND11dXY8hbR1Ji2a9mJdn9",
+	"yvhMBacsXRBfGlymMjhBuVwdYqjP/RZdoj+V7k9khnyQitGq0lXSLzarEc+U2qVm4bkOyQifj1wKsGjr",
+	"UNSPlrpni68KSZOiVY3p5TdGfmHomLqW5S+PHJOI/7eI4U+/dyQ7plDOPFTiPH07670wxExWkdPWi35k",
+	"x1LUX267iob+kClbV1/pLWhWrkSMkQX12rvE/06JMpj6JdvzgKmLdHvCNd6fu4NE5fj3BTwK8P9qkpUo",
+	"/Dei18jVDXMPOUll6US6gK4ysznCFbSzJBW/Ru67hJSQaBIcv32HZqzQ0uLHD4XfRW5DfR+AsWi2bAB/",
+	"KLotAMQHLhsgVeb1Z55Is6Kef+3+DpDwy9ryZpj6v2fNSxTMnsuxAvbyJpuVZKzFPF4zq8T7gXOYDZKq",
+	"LM5eRu0AMs5MMdtwk2i5qKWkgPAXiVtD0vNSQ8e6IIRilwhBPpZPI0VyFR0ZDRxP4DmtCNj7RIHUOfzV",
+	"vCMdsf8WwsLcsm1zN2EqUjLkiLmUGRUf8hmW1Y5vVxUINVN7DOLxMmidJ0i7SzADiMhCJSoBgyKhzbbi",
+	"kX6zCyPDt4fwIXW8wVCHLYcgepzdIBHV+8PFyZDdQ6bpOzGjgKU67KUvP2XBPijvbE6dyre3t7iI8JGe",
+	"7LQ5Z+hfaXSh7MObf25yWSR3eijzj1NpNtDCZJSL5BYxt7Vc9ozPU46KIGt2UaRpFrry4ajnwGsd0iQx",
+	"FyeCjEvp90Mle9KCN7PI9Re06qcuTxDVmRXCmUVwWaLI8gj5dbbOr7oIKlo7C+4lU/hTP/9VUr5dL1Xm",
+	"k4nL83Zw8kQJivjKmr/JKr5RQr2WJYv/PmfNfzZM1ob5JY4+ouoCft8BqMpnzz0AQ9WxUrWkQA7Vha/8",
+	"9ikqzE3w6sNIzB8iVbyOg5ghUCdaBSWiFbdThblSCxHL+p5z3otyWw9v/38AZKi/UAveAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
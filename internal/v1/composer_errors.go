@@ -0,0 +1,324 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+
+	"github.com/osbuild/image-builder/internal/common"
+	"github.com/osbuild/image-builder/internal/composer"
+)
+
+// errorCode is a stable image-builder error code with a user facing message
+// and a remediation hint. Composer's errors are translated into these, so
+// users don't have to interpret composer's ids and reasons, which change
+// between composer releases.
+//
+// An errorCode passed as message of an echo.HTTPError is returned with its
+// code and remediation by HTTPErrorHandler.
+type errorCode struct {
+	Code        string
+	Message     string
+	Remediation string
+	// Status of the response when composer returns the error to a request
+	Status int
+}
+
+// String is the message, so echo.HTTPError and the logs print the message
+func (ec errorCode) String() string {
+	return ec.Message
+}
+
+func (ec errorCode) httpError(internal error) *echo.HTTPError {
+	httpError := echo.NewHTTPError(ec.Status, ec)
+	if internal != nil {
+		_ = httpError.SetInternal(internal)
+	}
+	return httpError
+}
+
+var (
+	errServiceUnavailable = errorCode{
+		Code:        "IB-SERVICE-UNAVAILABLE",
+		Message:     "The image building service is unavailable, please try again later",
+		Remediation: "Try again in a few minutes.",
+		Status:      http.StatusServiceUnavailable,
+	}
+	errComposerNotFound = errorCode{
+		Code:        "IB-NOT-FOUND",
+		Message:     "The image building service doesn't know this compose or clone anymore, composes are removed once their images expire",
+		Remediation: "Build the image again.",
+		Status:      http.StatusNotFound,
+	}
+	errComposer = errorCode{
+		Code:        "IB-COMPOSER-ERROR",
+		Message:     "The image building service returned an error",
+		Remediation: "Try again later, contact support if the problem persists.",
+		Status:      http.StatusInternalServerError,
+	}
+	errBuildFailed = errorCode{
+		Code:        "IB-BUILD-FAILED",
+		Message:     "Building the image failed",
+		Remediation: "Try building the image again, contact support if it keeps failing.",
+	}
+)
+
+var (
+	errInvalidRequest = errorCode{
+		Code:        "IB-INVALID-REQUEST",
+		Message:     "The image building service rejected the request",
+		Remediation: "Check the request against the error details.",
+		Status:      http.StatusBadRequest,
+	}
+	errUnsupportedDistribution = errorCode{
+		Code:        "IB-UNSUPPORTED-DISTRIBUTION",
+		Message:     "The distribution isn't supported by the image building service",
+		Remediation: "Choose one of the distributions listed by GET /distributions.",
+		Status:      http.StatusBadRequest,
+	}
+	errUnsupportedArchitecture = errorCode{
+		Code:        "IB-UNSUPPORTED-ARCHITECTURE",
+		Message:     "The architecture isn't supported for this distribution",
+		Remediation: "Choose one of the architectures listed by GET /architectures/{distribution}.",
+		Status:      http.StatusBadRequest,
+	}
+	errUnsupportedImageType = errorCode{
+		Code:        "IB-UNSUPPORTED-IMAGE-TYPE",
+		Message:     "The image type isn't supported for this distribution and architecture",
+		Remediation: "Choose one of the image types listed by GET /architectures/{distribution}.",
+		Status:      http.StatusBadRequest,
+	}
+	errInvalidRepository = errorCode{
+		Code:        "IB-INVALID-REPOSITORY",
+		Message:     "A custom or payload repository is invalid",
+		Remediation: "Check that each repository has a baseurl, mirrorlist or metalink, and a gpg key when it checks signatures.",
+		Status:      http.StatusBadRequest,
+	}
+	errInvalidOSTree = errorCode{
+		Code:        "IB-INVALID-OSTREE-OPTIONS",
+		Message:     "The OSTree options are invalid",
+		Remediation: "Check the ref, url and parent of the OSTree options.",
+		Status:      http.StatusBadRequest,
+	}
+	errInvalidCustomization = errorCode{
+		Code:        "IB-INVALID-CUSTOMIZATION",
+		Message:     "The customizations can't be applied to this image",
+		Remediation: "Check the customizations against the error details, not every image type supports every customization.",
+		Status:      http.StatusBadRequest,
+	}
+	errInvalidUploadTarget = errorCode{
+		Code:        "IB-INVALID-UPLOAD-TARGET",
+		Message:     "The upload target is invalid for this image type",
+		Remediation: "Check the upload request of the image type.",
+		Status:      http.StatusBadRequest,
+	}
+	errCloneUnsupported = errorCode{
+		Code:        "IB-CLONE-UNSUPPORTED",
+		Message:     "The image of this compose can't be cloned",
+		Remediation: "Only images uploaded to a cloud which supports cloning can be cloned.",
+		Status:      http.StatusBadRequest,
+	}
+)
+
+// Errors composer returns to requests, by their id in composer's catalogue
+// (GET /api/image-builder-composer/v2/errors). The errors caused by
+// image-builder's own requests, rather than the user's, stay errComposer.
+// Other 4xx errors are passed on with composer's status and reason.
+var composerServiceErrors = map[string]errorCode{
+	"3":  errComposer, // unsupported media type
+	"4":  errUnsupportedDistribution,
+	"5":  errUnsupportedArchitecture,
+	"6":  errUnsupportedImageType,
+	"7":  errInvalidRepository,
+	"8":  requestError(errPackageResolution), // dnf error
+	"9":  errInvalidOSTree,                   // invalid ref
+	"10": requestError(errOSTreeResolution),  // ostree ref resolution error
+	"11": errInvalidCustomization,            // failed to make the manifest
+	"14": errComposerNotFound,                // invalid compose id
+	"15": errComposerNotFound,                // compose not found
+	"16": errComposer,                        // invalid error id
+	"17": errComposer,                        // error not found
+	"18": errComposer,                        // invalid page parameter
+	"19": errComposer,                        // invalid size parameter
+	"20": errComposer,                        // body decoding error
+	"21": errComposerNotFound,                // resource not found
+	"22": errComposer,                        // method not allowed
+	"23": errComposer,                        // not acceptable
+	"24": errInvalidRepository,               // payload repository without baseurl
+	"25": errInvalidRequest,                  // invalid number of image builds
+	"26": errComposer,                        // invalid job type
+	"27": errInvalidOSTree,                   // invalid OSTree parameters
+	"28": errComposer,                        // tenant not found
+	"29": errInvalidRepository,               // gpg check without a gpg key
+	"30": errInvalidRequest,                  // request validation failed
+	"31": {
+		Code:        "IB-COMPOSE-NOT-READY",
+		Message:     "The compose is still running or failed, only successful composes can be cloned",
+		Remediation: "Wait for the compose to finish successfully before cloning it.",
+		Status:      http.StatusBadRequest,
+	},
+	"32": errCloneUnsupported,     // unsupported image
+	"33": errComposerNotFound,     // invalid image from compose id
+	"34": errComposerNotFound,     // image not found
+	"35": errInvalidCustomization, // invalid customization
+	"36": errInvalidUploadTarget,  // local save not enabled
+	"37": errInvalidCustomization, // invalid partitioning mode
+	"38": errInvalidUploadTarget,  // invalid upload target
+}
+
+// requestError returns the error of a compose as the error of a request
+func requestError(ec errorCode) errorCode {
+	ec.Status = http.StatusBadRequest
+	return ec
+}
+
+// jobError is an error of a compose, reported in its status
+type jobError struct {
+	errorCode
+	// The error only reports that a job the compose depends on failed, the
+	// details hold the error of that job
+	Dependency bool
+}
+
+var (
+	errPackageResolution = errorCode{
+		Code:        "IB-PACKAGE-RESOLUTION",
+		Message:     "The packages of the image couldn't be resolved",
+		Remediation: "Check that the packages and custom repositories exist for the distribution and architecture.",
+	}
+	errOSTreeResolution = errorCode{
+		Code:        "IB-OSTREE-RESOLUTION",
+		Message:     "The OSTree commit of the image couldn't be resolved",
+		Remediation: "Check that the OSTree repository url is reachable and contains the ref and parent ref.",
+	}
+	errUploadFailed = errorCode{
+		Code:        "IB-UPLOAD-FAILED",
+		Message:     "The image was built, but uploading it to the target failed",
+		Remediation: "Check the upload target settings and that image-builder has access to the target account, then try again.",
+	}
+	errBuildInterrupted = errorCode{
+		Code:        "IB-BUILD-INTERRUPTED",
+		Message:     "The build of the image was interrupted",
+		Remediation: "Try building the image again.",
+	}
+	errContainerResolution = errorCode{
+		Code:        "IB-CONTAINER-RESOLUTION",
+		Message:     "The containers embedded in the image couldn't be resolved",
+		Remediation: "Check that the container images exist and are accessible.",
+	}
+	errRemoteFileResolution = errorCode{
+		Code:        "IB-REMOTE-FILE-RESOLUTION",
+		Message:     "A remote file of the image couldn't be fetched",
+		Remediation: "Check that the urls of the remote files are reachable.",
+	}
+)
+
+// Errors of compose jobs, by their id in composer's worker error catalogue.
+// Ids without an entry are reported as errBuildFailed.
+var composerJobErrors = map[int]jobError{
+	2:  {errUploadFailed, false}, // invalid target config
+	3:  {errUploadFailed, false}, // sharing the target failed
+	5:  {errBuildFailed, true},   // depsolve dependency failure
+	9:  {errBuildFailed, true},   // manifest dependency failure
+	10: {errBuildFailed, false},  // osbuild failed
+	11: {errUploadFailed, false}, // uploading the image failed
+	12: {errUploadFailed, false}, // importing the image failed
+	20: {errPackageResolution, false},
+	21: {errPackageResolution, false},
+	22: {errPackageResolution, false},
+	23: {errPackageResolution, false},
+	24: {errPackageResolution, false},
+	25: {errPackageResolution, false}, // repository error
+	26: {errBuildFailed, true},        // job dependency failure
+	27: {errBuildInterrupted, false},  // worker stopped sending heartbeats
+	28: {errUploadFailed, true},       // target errors are in the details
+	30: {errContainerResolution, false},
+	31: {errContainerResolution, true},
+	32: {errOSTreeResolution, false},
+	33: {errOSTreeResolution, false},
+	34: {errOSTreeResolution, false},
+	35: {errOSTreeResolution, true},
+	36: {errRemoteFileResolution, false},
+}
+
+// composerError translates an error of the composer client into the response
+// to the user. Error responses of composer are translated by the catalogue,
+// unknown 4xx ones keep composer's status and reason, and the others become a
+// 500 with msg, keeping composer's response for the logs. Other errors, such
+// as composer being unavailable, are left to HTTPErrorHandler.
+func composerError(err error, msg string) error {
+	var apiErr *composer.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	if ec, ok := composerServiceErrors[apiErr.Err.Id]; ok {
+		if ec.Code == errComposer.Code {
+			ec.Message = msg
+		}
+		return ec.httpError(apiErr)
+	}
+	if apiErr.NotFound() {
+		return errComposerNotFound.httpError(apiErr)
+	}
+	if apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 {
+		ec := errInvalidRequest
+		ec.Status = apiErr.StatusCode
+		if apiErr.Err.Reason != "" {
+			ec.Message = apiErr.Err.Reason
+		}
+		return ec.httpError(apiErr)
+	}
+	ec := errComposer
+	ec.Message = msg
+	return ec.httpError(apiErr)
+}
+
+// parseComposeStatusError translates the error of a compose, errors reporting
+// a failed dependency are replaced by the error of the dependency.
+func parseComposeStatusError(composeErr *composer.ComposeStatusError) *ComposeStatusError {
+	if composeErr == nil {
+		return nil
+	}
+
+	je, ok := composerJobErrors[composeErr.Id]
+	if !ok {
+		je = jobError{errBuildFailed, false}
+	}
+
+	// Default top-level error
+	fbErr := &ComposeStatusError{
+		Id:          composeErr.Id,
+		Reason:      composeErr.Reason,
+		Details:     composeErr.Details,
+		Code:        common.StringToPtr(je.Code),
+		Message:     common.StringToPtr(je.Message),
+		Remediation: common.StringToPtr(je.Remediation),
+	}
+
+	if !je.Dependency || composeErr.Details == nil {
+		return fbErr
+	}
+
+	intfs, ok := (*composeErr.Details).([]interface{})
+	if !ok || len(intfs) == 0 {
+		return fbErr
+	}
+
+	// Try to remarshal the details as another composer.ComposeStatusError
+	jsonDetails, err := json.Marshal(intfs[0])
+	if err != nil {
+		logrus.Errorf("Error processing compose status error details: %v", err)
+		return fbErr
+	}
+	var newErr composer.ComposeStatusError
+	err = json.Unmarshal(jsonDetails, &newErr)
+	if err != nil {
+		logrus.Errorf("Error processing compose status error details: %v", err)
+		return fbErr
+	}
+
+	return parseComposeStatusError(&newErr)
+}
@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// the timezones are validated against the tz database of the Go release,
+	// not the one of the host image-builder runs on
+	_ "time/tzdata"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/common"
+	"github.com/osbuild/image-builder/internal/composer"
+	"github.com/osbuild/image-builder/internal/distribution"
+)
+
+var hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// validateOSCustomizations checks the settings of the operating system beyond
+// the formats checked by the request validation.
+func validateOSCustomizations(cust *Customizations) error {
+	if cust.Timezone != nil {
+		if cust.Timezone.Timezone != nil {
+			err := validateTimezone(*cust.Timezone.Timezone)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+		if cust.Timezone.Ntpservers != nil {
+			for _, server := range *cust.Timezone.Ntpservers {
+				if net.ParseIP(server) == nil && (len(server) > 253 || !hostnameRegex.MatchString(server)) {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("NTP server %q is neither a host name nor an IP address", server))
+				}
+			}
+		}
+	}
+
+	if cust.Firewall != nil && cust.Firewall.Ports != nil {
+		for _, port := range *cust.Firewall.Ports {
+			err := validateFirewallPort(port)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+	}
+
+	if cust.Firewall != nil && cust.Firewall.Services != nil &&
+		cust.Firewall.Services.Enabled != nil && cust.Firewall.Services.Disabled != nil {
+		disabled := map[string]bool{}
+		for _, s := range *cust.Firewall.Services.Disabled {
+			disabled[s] = true
+		}
+		for _, s := range *cust.Firewall.Services.Enabled {
+			if disabled[s] {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Firewall service %s can't be both enabled and disabled", s))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateDistroCustomizations checks that the distribution supports the
+// customizations only some of the image types can apply.
+func validateDistroCustomizations(cust *Customizations, d *distribution.DistributionFile, it ImageTypes) error {
+	if cust == nil {
+		return nil
+	}
+
+	if cust.Fips != nil && *cust.Fips && !supportsImageType(d.SupportsFips, it) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("FIPS mode isn't supported for %s images of %s", it, d.Distribution.Name))
+	}
+
+	return nil
+}
+
+func supportsImageType(supports func(string) bool, it ImageTypes) bool {
+	for _, alias := range imageTypeAliases(it) {
+		if supports(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTimezone checks that tz is the name of a timezone in the tz
+// database, time.LoadLocation also accepts "Local" and "".
+func validateTimezone(tz string) error {
+	if tz == "" || tz == "Local" {
+		return fmt.Errorf("Unknown timezone %q", tz)
+	}
+	_, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("Unknown timezone %q", tz)
+	}
+	return nil
+}
+
+// validateFirewallPort checks the range of a port, or ports, in the format
+// port:protocol or first-last:protocol. The format is checked by the request
+// validation.
+func validateFirewallPort(port string) error {
+	ports, _, found := strings.Cut(port, ":")
+	if !found {
+		return fmt.Errorf("Firewall port %q lacks a protocol", port)
+	}
+	first, last, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		last = first
+	}
+	firstNum, err := strconv.Atoi(first)
+	if err != nil {
+		return fmt.Errorf("Invalid firewall port %q", port)
+	}
+	lastNum, err := strconv.Atoi(last)
+	if err != nil {
+		return fmt.Errorf("Invalid firewall port %q", port)
+	}
+	if firstNum < 1 || lastNum > 65535 || firstNum > lastNum {
+		return fmt.Errorf("Firewall port %q is out of the range 1-65535", port)
+	}
+	return nil
+}
+
+func buildOSCustomizations(cust *Customizations, res *composer.Customizations) {
+	if cust.Kernel != nil {
+		res.Kernel = &composer.Kernel{
+			Name:   cust.Kernel.Name,
+			Append: cust.Kernel.Append,
+		}
+	}
+
+	if cust.Timezone != nil {
+		res.Timezone = &composer.Timezone{
+			Timezone:   cust.Timezone.Timezone,
+			Ntpservers: cust.Timezone.Ntpservers,
+		}
+	}
+
+	if cust.Locale != nil {
+		res.Locale = &composer.Locale{
+			Languages: cust.Locale.Languages,
+			Keyboard:  cust.Locale.Keyboard,
+		}
+	}
+
+	res.Hostname = cust.Hostname
+
+	if cust.Fips != nil && *cust.Fips {
+		res.Fips = &composer.FIPS{
+			Enabled: common.BoolToPtr(true),
+		}
+	}
+
+	if cust.Firewall != nil {
+		res.Firewall = &composer.FirewallCustomization{
+			Ports: cust.Firewall.Ports,
+		}
+		if cust.Firewall.Services != nil {
+			res.Firewall.Services = &composer.FirewallServices{
+				Enabled:  cust.Firewall.Services.Enabled,
+				Disabled: cust.Firewall.Services.Disabled,
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/composer"
+)
+
+const (
+	// 1 TiB, the most the builders have disk space for
+	FSDefaultMaxSize = 1099511627776
+)
+
+// FilesystemSize is a size in bytes, it is sent either as an integer or as a
+// string with a unit, such as "20 GiB"
+type FilesystemSize uint64
+
+type mountpointPolicy struct {
+	deny  bool
+	exact bool
+}
+
+type fsMaxSize struct {
+	target string
+	size   uint64
+}
+
+var (
+	filesystemSizeRegex = regexp.MustCompile(`^([0-9]+) ?([kKMGT]i?B|B)?$`)
+
+	filesystemSizeUnits = map[string]uint64{
+		"":    1,
+		"B":   1,
+		"kB":  1000,
+		"KB":  1000,
+		"kiB": 1024,
+		"KiB": 1024,
+		"MB":  1000 * 1000,
+		"MiB": 1024 * 1024,
+		"GB":  1000 * 1000 * 1000,
+		"GiB": 1024 * 1024 * 1024,
+		"TB":  1000 * 1000 * 1000 * 1000,
+		"TiB": 1024 * 1024 * 1024 * 1024,
+	}
+
+	// The policy of the longest matching path applies, paths matching none
+	// are denied
+	mountpointPolicies = map[string]mountpointPolicy{
+		"/":         {exact: true},
+		"/boot":     {exact: true},
+		"/var":      {},
+		"/opt":      {},
+		"/srv":      {},
+		"/usr":      {},
+		"/app":      {},
+		"/data":     {},
+		"/home":     {},
+		"/tmp":      {},
+		"/var/run":  {deny: true},
+		"/var/lock": {deny: true},
+	}
+
+	// Clouds limit the size of the images they import
+	fsMaxSizes = map[ImageTypes]fsMaxSize{
+		ImageTypesAmi:   {"AWS", FSMaxSize},
+		ImageTypesAws:   {"AWS", FSMaxSize},
+		ImageTypesAzure: {"Azure", FSMaxSize},
+		ImageTypesVhd:   {"Azure", FSMaxSize},
+	}
+
+	// OSTree commits have no partitions and the OSTree installers partition
+	// the disk in the kickstart
+	fsUnsupportedImageTypes = map[ImageTypes]bool{
+		ImageTypesEdgeCommit:        true,
+		ImageTypesRhelEdgeCommit:    true,
+		ImageTypesEdgeInstaller:     true,
+		ImageTypesRhelEdgeInstaller: true,
+	}
+)
+
+func (s *FilesystemSize) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		err := json.Unmarshal(data, &str)
+		if err != nil {
+			return err
+		}
+		size, err := parseFilesystemSize(str)
+		if err != nil {
+			return err
+		}
+		*s = FilesystemSize(size)
+		return nil
+	}
+
+	var size uint64
+	err := json.Unmarshal(data, &size)
+	if err != nil {
+		return fmt.Errorf("Invalid filesystem size %s, expected a number of bytes or a size with a unit", data)
+	}
+	*s = FilesystemSize(size)
+	return nil
+}
+
+// parseFilesystemSize parses sizes like "1073741824", "512 MiB" or "20GB"
+func parseFilesystemSize(str string) (uint64, error) {
+	m := filesystemSizeRegex.FindStringSubmatch(str)
+	if m == nil {
+		return 0, fmt.Errorf("Invalid filesystem size %q, expected a number of bytes or a size with a unit", str)
+	}
+	num, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Filesystem size %q is too large", str)
+	}
+	unit := filesystemSizeUnits[m[2]]
+	if num > math.MaxUint64/unit {
+		return 0, fmt.Errorf("Filesystem size %q is too large", str)
+	}
+	return num * unit, nil
+}
+
+func validateMountpoint(mountpoint string) error {
+	if !strings.HasPrefix(mountpoint, "/") || path.Clean(mountpoint) != mountpoint {
+		return fmt.Errorf("Mountpoint %s isn't a clean absolute path", mountpoint)
+	}
+
+	prefix := mountpoint
+	for {
+		policy, ok := mountpointPolicies[prefix]
+		if ok {
+			if policy.deny || (policy.exact && prefix != mountpoint) {
+				return fmt.Errorf("Mountpoint %s isn't allowed", mountpoint)
+			}
+			return nil
+		}
+		if prefix == "/" {
+			return fmt.Errorf("Mountpoint %s isn't allowed", mountpoint)
+		}
+		prefix = path.Dir(prefix)
+	}
+}
+
+// validateFilesystems checks the filesystem and partitioning customizations
+// against the mountpoint policies and the size limits of the image type.
+func validateFilesystems(cust *Customizations, it ImageTypes) error {
+	if cust.Filesystem == nil && cust.PartitioningMode == nil {
+		return nil
+	}
+
+	if fsUnsupportedImageTypes[it] {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Filesystem and partitioning customizations don't apply to %s images", it))
+	}
+
+	if cust.Filesystem == nil {
+		return nil
+	}
+
+	mountpoints := map[string]bool{}
+	var totalSize uint64
+	for _, fs := range *cust.Filesystem {
+		err := validateMountpoint(fs.Mountpoint)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if mountpoints[fs.Mountpoint] {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Mountpoint %s is listed more than once", fs.Mountpoint))
+		}
+		mountpoints[fs.Mountpoint] = true
+
+		if totalSize+uint64(fs.MinSize) < totalSize {
+			return echo.NewHTTPError(http.StatusBadRequest, "Total image size is too large")
+		}
+		totalSize += uint64(fs.MinSize)
+	}
+
+	if limit, ok := fsMaxSizes[it]; ok {
+		if totalSize > limit.size {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Total %s image size cannot exceed %d bytes", limit.target, limit.size))
+		}
+	} else if totalSize > FSDefaultMaxSize {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Total image size cannot exceed %d bytes", FSDefaultMaxSize))
+	}
+
+	return nil
+}
+
+func buildFilesystems(filesystems []Filesystem) []composer.Filesystem {
+	var res []composer.Filesystem
+	for _, fs := range filesystems {
+		cfs := composer.Filesystem{
+			Mountpoint: fs.Mountpoint,
+			MinSize:    uint64(fs.MinSize),
+		}
+		res = append(res, cfs)
+	}
+	return res
+}
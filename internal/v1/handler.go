@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/uuid"
 	"github.com/osbuild/image-builder/internal/common"
 	"github.com/osbuild/image-builder/internal/composer"
@@ -21,8 +24,6 @@ import (
 )
 
 const (
-	ComposeRunningOrFailedError = "IMAGE-BUILDER-COMPOSER-31"
-
 	// 64 GiB
 	FSMaxSize = 68719476736
 )
@@ -33,18 +34,13 @@ func (h *Handlers) GetVersion(ctx echo.Context) error {
 }
 
 func (h *Handlers) GetReadiness(ctx echo.Context) error {
-	resp, err := h.server.cClient.OpenAPI()
+	err := h.server.composers.Default().OpenAPI(ctx.Request().Context())
 	if err != nil {
-		return err
-	}
-	defer closeBody(resp.Body)
-
-	if resp.StatusCode != 200 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
+		var apiErr *composer.APIError
+		if errors.As(err, &apiErr) {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to contact osbuild-composer: %s", apiErr.Body))
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to contact osbuild-composer: %s", body))
+		return err
 	}
 
 	ready := map[string]string{
@@ -148,6 +144,10 @@ func (h *Handlers) GetPackages(ctx echo.Context, params GetPackagesParams) error
 				Summary: p.Summary,
 			})
 	}
+	// a stable order, so cursors point at the same place across requests
+	sort.SliceStable(packages, func(i, j int) bool {
+		return packageLess(packages[i], packages[j])
+	})
 
 	limit := 100
 	if params.Limit != nil {
@@ -170,9 +170,49 @@ func (h *Handlers) GetPackages(ctx echo.Context, params GetPackagesParams) error
 		upto = len(packages)
 	}
 
-	lastOffset := len(packages) - 1
-	if lastOffset < 0 {
-		lastOffset = 0
+	if params.Cursor != nil {
+		c, err := decodeCursor(*params.Cursor, "packages", "")
+		if err != nil {
+			return err
+		}
+		at := Package{Name: c.Name, Summary: c.Summary}
+		if c.Before {
+			upto = sort.Search(len(packages), func(i int) bool {
+				return !packageLess(packages[i], at)
+			})
+			offset = upto - limit
+			if offset < 0 {
+				offset = 0
+			}
+		} else {
+			offset = sort.Search(len(packages), func(i int) bool {
+				return packageLess(at, packages[i])
+			})
+			upto = offset + limit
+			if upto > len(packages) {
+				upto = len(packages)
+			}
+		}
+	}
+
+	var next, prev *string
+	if upto > offset {
+		packageCursor := func(p Package, before bool) cursor {
+			return cursor{
+				List:    "packages",
+				Before:  before,
+				Name:    p.Name,
+				Summary: p.Summary,
+			}
+		}
+		prefix := fmt.Sprintf("%v/v%v/packages?search=%v&distribution=%v&architecture=%v&",
+			RoutePrefix(), h.server.spec.Info.Version, params.Search, params.Distribution, params.Architecture)
+		if upto < len(packages) {
+			next = cursorLink(prefix, limit, packageCursor(packages[upto-1], false), "")
+		}
+		if offset > 0 {
+			prev = cursorLink(prefix, limit, packageCursor(packages[offset], true), "")
+		}
 	}
 
 	return ctx.JSON(http.StatusOK, PackagesResponse{
@@ -182,56 +222,52 @@ func (h *Handlers) GetPackages(ctx echo.Context, params GetPackagesParams) error
 			len(packages),
 		},
 		Links: struct {
-			First string `json:"first"`
-			Last  string `json:"last"`
+			First string  `json:"first"`
+			Last  string  `json:"last"`
+			Next  *string `json:"next,omitempty"`
+			Prev  *string `json:"prev,omitempty"`
 		}{
 			fmt.Sprintf("%v/v%v/packages?search=%v&distribution=%v&architecture=%v&offset=0&limit=%v",
 				RoutePrefix(), h.server.spec.Info.Version, params.Search, params.Distribution, params.Architecture, limit),
 			fmt.Sprintf("%v/v%v/packages?search=%v&distribution=%v&architecture=%v&offset=%v&limit=%v",
-				RoutePrefix(), h.server.spec.Info.Version, params.Search, params.Distribution, params.Architecture, lastOffset, limit),
+				RoutePrefix(), h.server.spec.Info.Version, params.Search, params.Distribution, params.Architecture, lastPageOffset(len(packages), limit), limit),
+			next,
+			prev,
 		},
 		Data: packages[offset:upto],
 	})
 }
 
+// packageLess orders packages by name, and by summary among packages of the
+// same name from different repositories.
+func packageLess(a, b Package) bool {
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.Summary < b.Summary
+}
+
 func (h *Handlers) GetComposeStatus(ctx echo.Context, composeId uuid.UUID) error {
 	composeEntry, err := h.getComposeByIdAndOrgId(ctx, composeId)
 	if err != nil {
 		return err
 	}
 
-	resp, err := h.server.cClient.ComposeStatus(composeId)
+	cClient, err := h.composerClient(composeEntry.Backend)
 	if err != nil {
 		return err
 	}
-	defer closeBody(resp.Body)
-
-	if resp.StatusCode == http.StatusNotFound {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		// Composes can get deleted in composer, usually when the image is expired
-		return echo.NewHTTPError(http.StatusNotFound, string(body))
-	} else if resp.StatusCode != http.StatusOK {
-		httpError := echo.NewHTTPError(http.StatusInternalServerError, "Failed querying compose status")
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			ctx.Logger().Errorf("Unable to parse composer's compose response: %v", err)
-		} else {
-			_ = httpError.SetInternal(fmt.Errorf("%s", body))
-		}
-		return httpError
+	cloudStat, err := cClient.ComposeStatus(ctx.Request().Context(), composeId)
+	if err != nil {
+		return composerError(err, "Failed querying compose status")
 	}
 
-	var composeRequest ComposeRequest
-	err = json.Unmarshal(composeEntry.Request, &composeRequest)
+	rawCR, err := h.server.composeRedactor.redact(composeEntry.Request)
 	if err != nil {
 		return err
 	}
-
-	var cloudStat composer.ComposeStatus
-	err = json.NewDecoder(resp.Body).Decode(&cloudStat)
+	var composeRequest ComposeRequest
+	err = json.Unmarshal(rawCR, &composeRequest)
 	if err != nil {
 		return err
 	}
@@ -256,54 +292,12 @@ func (h *Handlers) GetComposeStatus(ctx echo.Context, composeId uuid.UUID) error
 		status.ImageStatus.Error = parseComposeStatusError(cloudStat.ImageStatus.Error)
 	}
 
-	return ctx.JSON(http.StatusOK, status)
-}
-
-func parseComposeStatusError(composeErr *composer.ComposeStatusError) *ComposeStatusError {
-	if composeErr == nil {
-		return nil
+	err = h.server.db.UpdateComposeStatus(composeId, string(cloudStat.ImageStatus.Status))
+	if err != nil {
+		ctx.Logger().Errorf("Error storing status of compose %v: %v", composeId, err)
 	}
 
-	// Default top-level error
-	fbErr := &ComposeStatusError{
-		Id:      composeErr.Id,
-		Reason:  composeErr.Reason,
-		Details: composeErr.Details,
-	}
-
-	switch composeErr.Id {
-	case 5: // manifest error: depsolve dependency failure
-		fallthrough
-	case 9: // osbuild error: manifest dependency failure
-		fallthrough
-	case 26: // ErrorJobDependency: generic dependency failure
-		fallthrough
-	case 28: // osbuild target errors are added to details
-		if composeErr.Details != nil {
-			intfs := (*composeErr.Details).([]interface{})
-			if len(intfs) == 0 {
-				return fbErr
-			}
-
-			// Try to remarshal the details as another composer.ComposeStatusError
-			jsonDetails, err := json.Marshal(intfs[0])
-			if err != nil {
-				logrus.Errorf("Error processing compose status error details: %v", err)
-				return fbErr
-			}
-			var newErr composer.ComposeStatusError
-			err = json.Unmarshal(jsonDetails, &newErr)
-			if err != nil {
-				logrus.Errorf("Error processing compose status error details: %v", err)
-				return fbErr
-			}
-
-			return parseComposeStatusError(&newErr)
-		}
-		return fbErr
-	default:
-		return fbErr
-	}
+	return ctx.JSON(http.StatusOK, status)
 }
 
 func (h *Handlers) DeleteCompose(ctx echo.Context, composeId uuid.UUID) error {
@@ -319,44 +313,49 @@ func (h *Handlers) DeleteCompose(ctx echo.Context, composeId uuid.UUID) error {
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	err = h.recordAudit(ctx, db.AuditActionDelete, composeId, nil)
+	if err != nil {
+		return err
+	}
 
 	return ctx.NoContent(http.StatusOK)
 }
 
-func (h *Handlers) GetComposeMetadata(ctx echo.Context, composeId uuid.UUID) error {
-	err := h.canUserAccessComposeId(ctx, composeId)
+func (h *Handlers) RestoreCompose(ctx echo.Context, composeId uuid.UUID) error {
+	idHeader, err := getIdentityHeader(ctx)
 	if err != nil {
 		return err
 	}
 
-	resp, err := h.server.cClient.ComposeMetadata(composeId)
+	err = h.server.db.RestoreCompose(composeId, idHeader.Identity.OrgID, h.server.restoreGracePeriod)
+	if err != nil {
+		if errors.Is(err, db.ComposeNotFoundError) {
+			return echo.NewHTTPError(http.StatusNotFound, "Compose not found or deleted outside of the restore grace period")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	err = h.recordAudit(ctx, db.AuditActionRestore, composeId, nil)
 	if err != nil {
 		return err
 	}
-	defer closeBody(resp.Body)
 
-	if resp.StatusCode == http.StatusNotFound {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		return echo.NewHTTPError(http.StatusNotFound, string(body))
-	} else if resp.StatusCode != http.StatusOK {
-		httpError := echo.NewHTTPError(http.StatusInternalServerError, "Failed querying compose status")
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			ctx.Logger().Errorf("Unable to parse composer's compose response: %v", err)
-		} else {
-			_ = httpError.SetInternal(fmt.Errorf("%s", body))
-		}
-		return httpError
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (h *Handlers) GetComposeMetadata(ctx echo.Context, composeId uuid.UUID) error {
+	composeEntry, err := h.getComposeByIdAndOrgId(ctx, composeId)
+	if err != nil {
+		return err
 	}
 
-	var cloudStat composer.ComposeMetadata
-	err = json.NewDecoder(resp.Body).Decode(&cloudStat)
+	cClient, err := h.composerClient(composeEntry.Backend)
 	if err != nil {
 		return err
 	}
+	cloudStat, err := cClient.ComposeMetadata(ctx.Request().Context(), composeId)
+	if err != nil {
+		return composerError(err, "Failed querying compose metadata")
+	}
 
 	var packages []PackageMetadata
 	if cloudStat.Packages != nil {
@@ -374,6 +373,13 @@ func (h *Handlers) GetComposeMetadata(ctx echo.Context, composeId uuid.UUID) err
 			}
 		}
 	}
+	if cloudStat.OstreeCommit != nil {
+		err = h.server.db.UpdateOSTreeCommit(composeId, *cloudStat.OstreeCommit)
+		if err != nil && !errors.Is(err, db.OSTreeCommitNotFoundError) {
+			ctx.Logger().Errorf("Error storing the commit of compose %v: %v", composeId, err)
+		}
+	}
+
 	status := ComposeMetadata{
 		OstreeCommit: cloudStat.OstreeCommit,
 		Packages:     &packages,
@@ -400,6 +406,15 @@ func (h *Handlers) getComposeByIdAndOrgId(ctx echo.Context, composeId uuid.UUID)
 	return composeEntry, nil
 }
 
+// return the client of the composer backend owning a compose
+func (h *Handlers) composerClient(backend string) (*composer.ComposerClient, error) {
+	cClient, err := h.server.composers.Client(backend)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return cClient, nil
+}
+
 // return an error if the user does not have the composeId associated to its OrgID in the DB, nil otherwise
 func (h *Handlers) canUserAccessComposeId(ctx echo.Context, composeId uuid.UUID) error {
 	_, err := h.getComposeByIdAndOrgId(ctx, composeId)
@@ -429,25 +444,62 @@ func (h *Handlers) GetComposes(ctx echo.Context, params GetComposesParams) error
 		offset = *params.Offset
 	}
 
-	// composes in the last 14 days
-	composes, count, err := h.server.db.GetComposes(idHeader.Identity.OrgID, (time.Hour * 24 * 14), limit, offset)
+	filter, query, err := composesFilter(params)
+	if err != nil {
+		return err
+	}
+
+	var keyset *db.Keyset
+	if params.Cursor != nil {
+		c, err := decodeCursor(*params.Cursor, "composes", string(filter.Sort))
+		if err != nil {
+			return err
+		}
+		keyset = c.keyset()
+	}
+	before := keyset != nil && keyset.Before
+
+	composes, count, err := h.server.db.GetComposes(idHeader.Identity.OrgID, filter, keyset, limit+1, offset)
 	if err != nil {
 		return err
 	}
+	from, to, more := pageBounds(len(composes), limit, before)
+	composes = composes[from:to]
 
 	data := []ComposesResponseItem{}
 	for _, c := range composes {
+		request, err := h.server.composeRedactor.redact(c.Request)
+		if err != nil {
+			return err
+		}
 		data = append(data, ComposesResponseItem{
 			CreatedAt: c.CreatedAt.Format(time.RFC3339),
 			Id:        c.Id,
 			ImageName: c.ImageName,
-			Request:   c.Request,
+			Request:   request,
 		})
 	}
 
-	lastOffset := count - 1
-	if lastOffset < 0 {
-		lastOffset = 0
+	composeCursor := func(c db.ComposeEntry, before bool) cursor {
+		return cursor{
+			List:      "composes",
+			Sort:      string(filter.Sort),
+			Before:    before,
+			CreatedAt: &c.CreatedAt,
+			ImageName: c.ImageName,
+			Id:        &c.Id,
+		}
+	}
+	var next, prev *string
+	if len(composes) > 0 {
+		prefix := fmt.Sprintf("%v/v%v/composes?", RoutePrefix(), spec.Info.Version)
+		hasNext, hasPrev := adjacentPages(before, more, keyset != nil || offset > 0)
+		if hasNext {
+			next = cursorLink(prefix, limit, composeCursor(composes[len(composes)-1], false), query)
+		}
+		if hasPrev {
+			prev = cursorLink(prefix, limit, composeCursor(composes[0], true), query)
+		}
 	}
 
 	return ctx.JSON(http.StatusOK, ComposesResponse{
@@ -457,18 +509,129 @@ func (h *Handlers) GetComposes(ctx echo.Context, params GetComposesParams) error
 			count,
 		},
 		Links: struct {
-			First string `json:"first"`
-			Last  string `json:"last"`
+			First string  `json:"first"`
+			Last  string  `json:"last"`
+			Next  *string `json:"next,omitempty"`
+			Prev  *string `json:"prev,omitempty"`
 		}{
-			fmt.Sprintf("%v/v%v/composes?offset=0&limit=%v",
-				RoutePrefix(), spec.Info.Version, limit),
-			fmt.Sprintf("%v/v%v/composes?offset=%v&limit=%v",
-				RoutePrefix(), spec.Info.Version, lastOffset, limit),
+			fmt.Sprintf("%v/v%v/composes?offset=0&limit=%v%v",
+				RoutePrefix(), spec.Info.Version, limit, query),
+			fmt.Sprintf("%v/v%v/composes?offset=%v&limit=%v%v",
+				RoutePrefix(), spec.Info.Version, lastPageOffset(count, limit), limit, query),
+			next,
+			prev,
 		},
 		Data: data,
 	})
 }
 
+// composesFilter translates the query parameters of GetComposes into a filter
+// for the database. It also returns the filtering parameters as a query string
+// suffix, so the links of the response can retain them.
+func composesFilter(params GetComposesParams) (db.ComposesFilter, string, error) {
+	query := url.Values{}
+	filter := db.ComposesFilter{}
+
+	// composes in the last 14 days, unless a time range was requested
+	if params.Since == nil && params.Until == nil {
+		filter.MaxAge = time.Hour * 24 * 14
+	}
+	if params.Since != nil && params.Until != nil && !params.Since.Before(*params.Until) {
+		return filter, "", echo.NewHTTPError(http.StatusBadRequest, "since must be before until")
+	}
+	if params.Since != nil {
+		filter.Since = params.Since
+		query.Set("since", params.Since.Format(time.RFC3339))
+	}
+	if params.Until != nil {
+		filter.Until = params.Until
+		query.Set("until", params.Until.Format(time.RFC3339))
+	}
+	if params.Distribution != nil {
+		filter.Distribution = common.StringToPtr(string(*params.Distribution))
+		query.Set("distribution", string(*params.Distribution))
+	}
+	if params.ImageType != nil {
+		filter.ImageTypes = imageTypeAliases(*params.ImageType)
+		query.Set("image_type", string(*params.ImageType))
+	}
+	if params.Architecture != nil {
+		filter.Architecture = common.StringToPtr(string(*params.Architecture))
+		query.Set("architecture", string(*params.Architecture))
+	}
+	if params.UploadType != nil {
+		filter.UploadType = common.StringToPtr(string(*params.UploadType))
+		query.Set("upload_type", string(*params.UploadType))
+	}
+	if params.ImageName != nil {
+		filter.ImageName = params.ImageName
+		query.Set("image_name", *params.ImageName)
+	}
+	if params.Status != nil {
+		filter.Status = common.StringToPtr(string(*params.Status))
+		query.Set("status", string(*params.Status))
+	}
+	if params.Sort != nil {
+		filter.Sort = db.ComposesSort(*params.Sort)
+		query.Set("sort", string(*params.Sort))
+	}
+	if params.LabelSelector != nil {
+		labels, err := parseLabelSelector(*params.LabelSelector)
+		if err != nil {
+			return filter, "", err
+		}
+		filter.Labels = labels
+		query.Set("label_selector", *params.LabelSelector)
+	}
+
+	if len(query) == 0 {
+		return filter, "", nil
+	}
+	return filter, "&" + query.Encode(), nil
+}
+
+// imageTypeAliases returns the image type along with the backwards compatible
+// aliases it is known under.
+func imageTypeAliases(it ImageTypes) []string {
+	aliases := map[ImageTypes][]ImageTypes{
+		ImageTypesAws:               {ImageTypesAws, ImageTypesAmi},
+		ImageTypesAmi:               {ImageTypesAws, ImageTypesAmi},
+		ImageTypesAzure:             {ImageTypesAzure, ImageTypesVhd},
+		ImageTypesVhd:               {ImageTypesAzure, ImageTypesVhd},
+		ImageTypesEdgeCommit:        {ImageTypesEdgeCommit, ImageTypesRhelEdgeCommit},
+		ImageTypesRhelEdgeCommit:    {ImageTypesEdgeCommit, ImageTypesRhelEdgeCommit},
+		ImageTypesEdgeInstaller:     {ImageTypesEdgeInstaller, ImageTypesRhelEdgeInstaller},
+		ImageTypesRhelEdgeInstaller: {ImageTypesEdgeInstaller, ImageTypesRhelEdgeInstaller},
+	}
+
+	types := []string{string(it)}
+	if a, ok := aliases[it]; ok {
+		types = nil
+		for _, t := range a {
+			types = append(types, string(t))
+		}
+	}
+	return types
+}
+
+// rejectRedactedSecrets refuses requests holding the masks of secrets, such
+// as requests read back from the api and resubmitted, which would otherwise
+// build images with the mask for a password or activation key
+func (h *Handlers) rejectRedactedSecrets(composeRequest ComposeRequest) error {
+	raw, err := json.Marshal(composeRequest)
+	if err != nil {
+		return err
+	}
+	paths, err := h.server.composeRedactor.redactedPaths(raw)
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("The request holds the placeholder %s instead of a secret at %s, set the real value, or reference a subscription profile through subscription_profile_id instead of the activation key", redactedSecret, strings.Join(paths, ", ")))
+	}
+	return nil
+}
+
 func (h *Handlers) ComposeImage(ctx echo.Context) error {
 	idHeader, err := getIdentityHeader(ctx)
 	if err != nil {
@@ -489,6 +652,11 @@ func (h *Handlers) ComposeImage(ctx echo.Context) error {
 		return err
 	}
 
+	err = h.rejectRedactedSecrets(composeRequest)
+	if err != nil {
+		return err
+	}
+
 	if (composeRequest.ImageRequests[0].UploadRequest == UploadRequest{}) {
 		return echo.NewHTTPError(http.StatusBadRequest, "Exactly one upload request should be included")
 	}
@@ -542,6 +710,50 @@ func (h *Handlers) ComposeImage(ctx echo.Context) error {
 		return err
 	}
 
+	err = validateDistroCustomizations(composeRequest.Customizations, d, composeRequest.ImageRequests[0].ImageType)
+	if err != nil {
+		return err
+	}
+
+	err = validateLabels(composeRequest.Labels)
+	if err != nil {
+		return err
+	}
+
+	err = validateOSTree(composeRequest.ImageRequests[0])
+	if err != nil {
+		return err
+	}
+
+	ostree, parentComposeId, err := h.buildOSTree(ctx, idHeader.Identity.OrgID, composeRequest.ImageRequests[0])
+	if err != nil {
+		return err
+	}
+
+	err = h.verifyOSTreeParent(ctx, ostree)
+	if err != nil {
+		return err
+	}
+
+	customizations := buildCustomizations(composeRequest.Customizations)
+	err = applyOscapProfile(composeRequest.Customizations, d, customizations)
+	if err != nil {
+		return err
+	}
+
+	err = h.resolveGPGKeys(ctx, customizations)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := h.resolveSubscriptionProfile(ctx, idHeader.Identity.OrgID, composeRequest.Customizations)
+	if err != nil {
+		return err
+	}
+	if subscription != nil {
+		customizations.Subscription = subscription
+	}
+
 	distro := d.Distribution.Name
 	if d.Distribution.ComposerName != nil {
 		distro = *d.Distribution.ComposerName
@@ -549,56 +761,51 @@ func (h *Handlers) ComposeImage(ctx echo.Context) error {
 
 	cloudCR := composer.ComposeRequest{
 		Distribution:   distro,
-		Customizations: buildCustomizations(composeRequest.Customizations),
+		Customizations: customizations,
 		ImageRequest: &composer.ImageRequest{
 			Architecture:  string(composeRequest.ImageRequests[0].Architecture),
 			ImageType:     imageType,
-			Ostree:        buildOSTreeOptions(composeRequest.ImageRequests[0].Ostree),
+			Ostree:        ostree,
 			Repositories:  repositories,
 			UploadOptions: &uploadOptions,
 		},
 	}
 
-	resp, err := h.server.cClient.Compose(cloudCR)
+	backend := h.server.composers.Route(idHeader.Identity.Internal.OrgID, string(composeRequest.Distribution), string(composeRequest.ImageRequests[0].Architecture))
+	cClient, err := h.composerClient(backend)
 	if err != nil {
 		return err
 	}
-	defer closeBody(resp.Body)
-	if resp.StatusCode != http.StatusCreated {
-		httpError := echo.NewHTTPError(http.StatusInternalServerError, "Failed posting compose request to osbuild-composer")
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			ctx.Logger().Errorf("Unable to parse composer's compose response: %v", err)
-		} else {
-			_ = httpError.SetInternal(fmt.Errorf("%s", body))
-			var serviceStat composer.Error
-			if err := json.Unmarshal(body, &serviceStat); err != nil {
-				return httpError
-			}
-			if serviceStat.Id == "10" {
-				httpError.Message = "Error resolving OSTree repo"
-				httpError.Code = http.StatusBadRequest
-			}
-		}
-		return httpError
+	composeResult, err := cClient.Compose(ctx.Request().Context(), cloudCR)
+	if err != nil {
+		return composerError(err, "Failed posting compose request to osbuild-composer")
 	}
 
-	var composeResult composer.ComposeId
-	err = json.NewDecoder(resp.Body).Decode(&composeResult)
+	rawCR, err := json.Marshal(composeRequest)
 	if err != nil {
 		return err
 	}
-
-	rawCR, err := json.Marshal(composeRequest)
+	rawCR, err = h.server.composeRedactor.redact(rawCR)
 	if err != nil {
 		return err
 	}
 
-	err = h.server.db.InsertCompose(composeResult.Id, idHeader.Identity.AccountNumber, idHeader.Identity.Internal.OrgID, composeRequest.ImageName, rawCR)
+	err = h.server.db.InsertCompose(composeResult.Id, idHeader.Identity.AccountNumber, idHeader.Identity.Internal.OrgID, composeRequest.ImageName, rawCR, backend)
 	if err != nil {
 		logrus.Error("Error inserting id into db", err)
 		return err
 	}
+	err = h.recordAudit(ctx, db.AuditActionCompose, composeResult.Id, rawCR)
+	if err != nil {
+		return err
+	}
+
+	if isOSTreeCommit(composeRequest.ImageRequests[0].ImageType) && ostree != nil && ostree.Ref != nil && *ostree.Ref != "" {
+		err = h.server.db.InsertOSTreeCommit(composeResult.Id, idHeader.Identity.OrgID, *ostree.Ref, ostree.Url, ostree.Parent, parentComposeId)
+		if err != nil {
+			ctx.Logger().Errorf("Error adding compose %v to the lineage of %s: %v", composeResult.Id, *ostree.Ref, err)
+		}
+	}
 
 	ctx.Logger().Info("Compose result", composeResult)
 
@@ -624,6 +831,10 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 		default:
 			return nil, "", echo.NewHTTPError(http.StatusBadRequest, "Invalid image type for upload target")
 		}
+		err = h.validateUploadOptions("AWSUploadRequestOptions", optionsJSON)
+		if err != nil {
+			return nil, "", err
+		}
 		var awsOptions AWSUploadRequestOptions
 		err = json.Unmarshal(optionsJSON, &awsOptions)
 		if err != nil {
@@ -634,38 +845,28 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 			return nil, "", echo.NewHTTPError(http.StatusBadRequest, "Expected at least one source or account to share the image with")
 		}
 
+		region, err := uploadRegion(awsOptions.Region, h.server.aws.Region, h.server.aws.AllowedRegions)
+		if err != nil {
+			return nil, "", err
+		}
+
 		var shareWithAccounts []string
 		if awsOptions.ShareWithAccounts != nil {
 			shareWithAccounts = append(shareWithAccounts, *awsOptions.ShareWithAccounts...)
 		}
 
 		if awsOptions.ShareWithSources != nil {
-			for _, source := range *awsOptions.ShareWithSources {
-				resp, err := h.server.pClient.GetUploadInfo(ctx.Request().Context(), source)
-				if err != nil {
-					logrus.Error(err)
-					return nil, "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to request source: %s", source))
-				}
-				defer closeBody(resp.Body)
-
-				var uploadInfo provisioning.V1SourceUploadInfoResponse
-				err = json.NewDecoder(resp.Body).Decode(&uploadInfo)
-				if err != nil {
-					return nil, "", echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Unable to resolve source: %s", source))
-				}
-
-				if uploadInfo.Aws == nil || uploadInfo.Aws.AccountId == nil || len(*uploadInfo.Aws.AccountId) != 12 {
-					return nil, "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to resolve source %s to an aws account id", source))
-				}
-
-				ctx.Logger().Info(fmt.Sprintf("Resolved source %s, to account id %s", strings.Replace(source, "\n", "", -1), *uploadInfo.Aws.AccountId))
-				shareWithAccounts = append(shareWithAccounts, *uploadInfo.Aws.AccountId)
+			accounts, err := h.resolveAWSSources(ctx, *awsOptions.ShareWithSources)
+			if err != nil {
+				return nil, "", err
 			}
+			shareWithAccounts = append(shareWithAccounts, accounts...)
 		}
 
 		return composer.AWSEC2UploadOptions{
-			Region:            h.server.aws.Region,
+			Region:            region,
 			ShareWithAccounts: shareWithAccounts,
+			SnapshotName:      awsOptions.SnapshotName,
 		}, composerImageType, nil
 	case UploadTypesAwsS3:
 		var composerImageType composer.ImageTypes
@@ -689,6 +890,10 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 		default:
 			return nil, "", echo.NewHTTPError(http.StatusBadRequest, "Invalid image type for upload target")
 		}
+		err = h.validateUploadOptions("AWSS3UploadRequestOptions", optionsJSON)
+		if err != nil {
+			return nil, "", err
+		}
 		var awsOptions AWSS3UploadRequestOptions
 		err = json.Unmarshal(optionsJSON, &awsOptions)
 		if err != nil {
@@ -696,6 +901,7 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 		}
 		return composer.AWSS3UploadOptions{
 			Region: h.server.aws.Region,
+			Public: awsOptions.Public,
 		}, composerImageType, nil
 	case UploadTypesGcp:
 		var composerImageType composer.ImageTypes
@@ -705,15 +911,24 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 		default:
 			return nil, "", echo.NewHTTPError(http.StatusBadRequest, "Invalid image type for upload target")
 		}
+		err = h.validateUploadOptions("GCPUploadRequestOptions", optionsJSON)
+		if err != nil {
+			return nil, "", err
+		}
 		var gcpOptions GCPUploadRequestOptions
 		err = json.Unmarshal(optionsJSON, &gcpOptions)
 		if err != nil {
 			return nil, "", echo.NewHTTPError(http.StatusBadRequest, "Unable to unmarshal into GCPUploadRequestOptions")
 		}
+		region, err := uploadRegion(gcpOptions.Region, h.server.gcp.Region, h.server.gcp.AllowedRegions)
+		if err != nil {
+			return nil, "", err
+		}
 		return composer.GCPUploadOptions{
 			Bucket:            &h.server.gcp.Bucket,
-			Region:            h.server.gcp.Region,
+			Region:            region,
 			ShareWithAccounts: &gcpOptions.ShareWithAccounts,
+			ImageName:         gcpOptions.ImageName,
 		}, composerImageType, nil
 	case UploadTypesAzure:
 		var composerImageType composer.ImageTypes
@@ -725,6 +940,10 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 		default:
 			return nil, "", echo.NewHTTPError(http.StatusBadRequest, "Invalid image type for upload target")
 		}
+		err = h.validateUploadOptions("AzureUploadRequestOptions", optionsJSON)
+		if err != nil {
+			return nil, "", err
+		}
 		var azureOptions AzureUploadRequestOptions
 		err = json.Unmarshal(optionsJSON, &azureOptions)
 		if err != nil {
@@ -773,6 +992,7 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 			SubscriptionId: subscriptionId,
 			ResourceGroup:  azureOptions.ResourceGroup,
 			ImageName:      azureOptions.ImageName,
+			Location:       azureOptions.Location,
 		}
 		return uploadOptions, composerImageType, nil
 	default:
@@ -780,6 +1000,70 @@ func (h *Handlers) buildUploadOptions(ctx echo.Context, ur UploadRequest, it Ima
 	}
 }
 
+// uploadRegion returns the region an upload goes to, the requested one or the
+// default one when none is requested.
+func uploadRegion(requested *string, defaultRegion string, allowed []string) (string, error) {
+	if requested == nil || *requested == defaultRegion {
+		return defaultRegion, nil
+	}
+	for _, r := range allowed {
+		if r == *requested {
+			return r, nil
+		}
+	}
+	return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Uploading to region %q is not allowed", *requested))
+}
+
+// validateUploadOptions validates the options against the schema of their
+// upload type. The request validation can't, as the upload request options
+// are any of the upload types' options.
+func (h *Handlers) validateUploadOptions(schema string, optionsJSON []byte) error {
+	var options interface{}
+	err := json.Unmarshal(optionsJSON, &options)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Unable to unmarshal UploadRequestOptions")
+	}
+	err = h.server.spec.Components.Schemas[schema].Value.VisitJSON(options)
+	if err != nil {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(err, &schemaErr) && len(schemaErr.JSONPointer()) > 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid %s at %q: %s", schema, strings.Join(schemaErr.JSONPointer(), "."), schemaErr.Reason))
+		}
+		if errors.As(err, &schemaErr) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid %s: %s", schema, schemaErr.Reason))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid %s: %v", schema, err))
+	}
+	return nil
+}
+
+// resolveAWSSources returns the AWS account ids of the sources.
+func (h *Handlers) resolveAWSSources(ctx echo.Context, sources []string) ([]string, error) {
+	var accounts []string
+	for _, source := range sources {
+		resp, err := h.server.pClient.GetUploadInfo(ctx.Request().Context(), source)
+		if err != nil {
+			logrus.Error(err)
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to request source: %s", source))
+		}
+		defer closeBody(resp.Body)
+
+		var uploadInfo provisioning.V1SourceUploadInfoResponse
+		err = json.NewDecoder(resp.Body).Decode(&uploadInfo)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Unable to resolve source: %s", source))
+		}
+
+		if uploadInfo.Aws == nil || uploadInfo.Aws.AccountId == nil || len(*uploadInfo.Aws.AccountId) != 12 {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to resolve source %s to an aws account id", source))
+		}
+
+		ctx.Logger().Info(fmt.Sprintf("Resolved source %s, to account id %s", strings.Replace(source, "\n", "", -1), *uploadInfo.Aws.AccountId))
+		accounts = append(accounts, *uploadInfo.Aws.AccountId)
+	}
+	return accounts, nil
+}
+
 func buildOSTreeOptions(ostreeOptions *OSTree) *composer.OSTree {
 	if ostreeOptions == nil {
 		return nil
@@ -803,24 +1087,24 @@ func validateCustomizations(cr *ComposeRequest) error {
 	}
 	it := cr.ImageRequests[0].ImageType
 
-	if cust.Users != nil && !strings.Contains(string(it), "installer") {
-		return echo.NewHTTPError(http.StatusBadRequest, "User customization only applies to installer image types")
+	err := validateUsers(cust, it)
+	if err != nil {
+		return err
 	}
 
-	if cust.Filesystem != nil {
-		var totalSize uint64
-		for _, v := range *cust.Filesystem {
-			totalSize += v.MinSize
-		}
+	err = validateOSCustomizations(cust)
+	if err != nil {
+		return err
+	}
 
-		if totalSize > FSMaxSize {
-			switch it {
-			case ImageTypesAmi, ImageTypesAws:
-				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Total AWS image size cannot exceed %d bytes", FSMaxSize))
-			case ImageTypesAzure, ImageTypesVhd:
-				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Total Azure image size cannot exceed %d bytes", FSMaxSize))
-			}
-		}
+	err = validateFilesystems(cust, it)
+	if err != nil {
+		return err
+	}
+
+	err = validateRepositories(cust)
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -923,37 +1207,45 @@ func buildCustomizations(cust *Customizations) *composer.Customizations {
 		res.Openscap = &composer.OpenSCAP{
 			ProfileId: cust.Openscap.ProfileId,
 		}
+		if cust.Openscap.Tailoring != nil {
+			res.Openscap.Tailoring = &composer.OpenSCAPTailoring{
+				Selected:   cust.Openscap.Tailoring.Selected,
+				Unselected: cust.Openscap.Tailoring.Unselected,
+			}
+		}
 	}
 
-	if cust.Filesystem != nil && len(*cust.Filesystem) > 1 {
-		var fsc []composer.Filesystem
-		for _, v := range *cust.Filesystem {
-			fsc = append(fsc, composer.Filesystem{
-				Mountpoint: v.Mountpoint,
-				MinSize:    v.MinSize,
-			})
-		}
-		res.Filesystem = &fsc
+	if cust.Filesystem != nil && len(*cust.Filesystem) > 0 {
+		filesystems := buildFilesystems(*cust.Filesystem)
+		res.Filesystem = &filesystems
+	}
+
+	if cust.PartitioningMode != nil {
+		partitioningMode := composer.CustomizationsPartitioningMode(*cust.PartitioningMode)
+		res.PartitioningMode = &partitioningMode
 	}
 
 	if cust.Users != nil {
-		var users []composer.User
-		for _, u := range *cust.Users {
-			groups := &[]string{"wheel"}
-			users = append(users, composer.User{
-				Name:   u.Name,
-				Key:    &u.SshKey,
-				Groups: groups,
-			})
-		}
+		users := buildUsers(*cust.Users)
 		res.Users = &users
 	}
 
+	if cust.Groups != nil {
+		groups := buildGroups(*cust.Groups)
+		res.Groups = &groups
+	}
+
+	buildOSCustomizations(cust, res)
+
 	return res
 }
 
 func (h *Handlers) CloneCompose(ctx echo.Context, composeId uuid.UUID) error {
-	err := h.canUserAccessComposeId(ctx, composeId)
+	composeEntry, err := h.getComposeByIdAndOrgId(ctx, composeId)
+	if err != nil {
+		return err
+	}
+	cClient, err := h.composerClient(composeEntry.Backend)
 	if err != nil {
 		return err
 	}
@@ -971,7 +1263,7 @@ func (h *Handlers) CloneCompose(ctx echo.Context, composeId uuid.UUID) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying the compose")
 	}
 
-	var resp *http.Response
+	var cloneResponse *composer.CloneComposeResponse
 	var rawCR json.RawMessage
 	if ImageTypes(imageType) == ImageTypesAws || ImageTypes(imageType) == ImageTypesAmi {
 		var awsEC2CloneReq AWSEC2Clone
@@ -991,72 +1283,205 @@ func (h *Handlers) CloneCompose(ctx echo.Context, composeId uuid.UUID) error {
 		}
 
 		if awsEC2CloneReq.ShareWithSources != nil {
-			for _, source := range *awsEC2CloneReq.ShareWithSources {
-				resp, err := h.server.pClient.GetUploadInfo(ctx.Request().Context(), source)
-				if err != nil {
-					logrus.Error(err)
-					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to request source: %s", source))
-				}
-				defer closeBody(resp.Body)
-
-				var uploadInfo provisioning.V1SourceUploadInfoResponse
-				err = json.NewDecoder(resp.Body).Decode(&uploadInfo)
-				if err != nil {
-					return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Unable to resolve source: %s", source))
-				}
-
-				if uploadInfo.Aws == nil || uploadInfo.Aws.AccountId == nil || len(*uploadInfo.Aws.AccountId) != 12 {
-					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to resolve source %s to an aws account id: %v", source, uploadInfo.Aws.AccountId))
-				}
-
-				logrus.Info(fmt.Sprintf("Resolved source %s, to account id %s", strings.Replace(source, "\n", "", -1), *uploadInfo.Aws.AccountId))
-				shareWithAccounts = append(shareWithAccounts, *uploadInfo.Aws.AccountId)
+			accounts, err := h.resolveAWSSources(ctx, *awsEC2CloneReq.ShareWithSources)
+			if err != nil {
+				return err
 			}
+			shareWithAccounts = append(shareWithAccounts, accounts...)
 		}
 
-		resp, err = h.server.cClient.CloneCompose(composeId, composer.AWSEC2CloneCompose{
+		cloneResponse, err = cClient.CloneCompose(ctx.Request().Context(), composeId, composer.AWSEC2CloneCompose{
 			Region:            awsEC2CloneReq.Region,
 			ShareWithAccounts: &shareWithAccounts,
 		})
 		if err != nil {
-			return err
+			return composerError(err, "Something went wrong creating the clone")
 		}
 	} else {
 		return echo.NewHTTPError(http.StatusBadRequest, "Cloning a compose is only available for AWS composes")
 	}
 
-	if resp == nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong creating the clone")
+	err = h.server.db.InsertClone(composeId, cloneResponse.Id, rawCR, nil)
+	if err != nil {
+		ctx.Logger().Errorf("Error inserting clone into db for compose %v: %v", err, composeId)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong saving the clone")
+	}
+	err = h.recordAudit(ctx, db.AuditActionClone, cloneResponse.Id, rawCR)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, CloneResponse{
+		Id: cloneResponse.Id,
+	})
+}
+
+func (h *Handlers) CloneComposeBatch(ctx echo.Context, composeId uuid.UUID) error {
+	composeEntry, err := h.getComposeByIdAndOrgId(ctx, composeId)
+	if err != nil {
+		return err
+	}
+	cClient, err := h.composerClient(composeEntry.Backend)
+	if err != nil {
+		return err
+	}
+
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+	imageType, err := h.server.db.GetComposeImageType(composeId, idHeader.Identity.OrgID)
+	if err != nil {
+		if errors.Is(err, db.ComposeNotFoundError) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to find compose %v", composeId))
+		}
+		ctx.Logger().Errorf("Error querying image type for compose %v: %v", composeId, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying the compose")
+	}
+	if ImageTypes(imageType) != ImageTypesAws && ImageTypes(imageType) != ImageTypesAmi {
+		return echo.NewHTTPError(http.StatusBadRequest, "Cloning a compose to several regions is only available for AWS composes")
+	}
+
+	var batchReq AWSEC2CloneBatch
+	err = ctx.Bind(&batchReq)
+	if err != nil {
+		return err
+	}
+
+	// the sources are resolved once for all regions
+	var shareWithAccounts []string
+	if batchReq.ShareWithAccounts != nil {
+		shareWithAccounts = append(shareWithAccounts, *batchReq.ShareWithAccounts...)
 	}
-	defer closeBody(resp.Body)
-	if resp.StatusCode != http.StatusCreated {
-		var cError composer.Error
-		err = json.NewDecoder(resp.Body).Decode(&cError)
+	if batchReq.ShareWithSources != nil {
+		accounts, err := h.resolveAWSSources(ctx, *batchReq.ShareWithSources)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to parse error returned by image-builder-composer service")
+			return err
+		}
+		shareWithAccounts = append(shareWithAccounts, accounts...)
+	}
+
+	batchId := uuid.New()
+	resp := CloneBatchResponse{
+		Id:     batchId,
+		Clones: []CloneBatchItem{},
+	}
+	// once clones were created the batch is returned, so they can be
+	// followed, along with the region which failed
+	fail := func(i int, err error) error {
+		if len(resp.Clones) == 0 {
+			return err
 		}
-		if cError.Code == ComposeRunningOrFailedError {
-			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("image-builder-composer compose failed: %s", cError.Reason))
+		httpError := httpErrorBody(toHTTPError(err))
+		resp.Failed = &CloneBatchFailure{
+			Region: batchReq.Regions[i],
+			Error:  httpError,
+		}
+		skipped := append([]string{}, batchReq.Regions[i+1:]...)
+		resp.SkippedRegions = &skipped
+		return ctx.JSON(http.StatusMultiStatus, resp)
+	}
+	for i, region := range batchReq.Regions {
+		// stored like the request of a single clone, so the clones of a batch
+		// list like any other
+		rawCR, err := json.Marshal(AWSEC2Clone{
+			Region:            region,
+			ShareWithAccounts: batchReq.ShareWithAccounts,
+			ShareWithSources:  batchReq.ShareWithSources,
+		})
+		if err != nil {
+			return err
+		}
+
+		cloneResponse, err := cClient.CloneCompose(ctx.Request().Context(), composeId, composer.AWSEC2CloneCompose{
+			Region:            region,
+			ShareWithAccounts: &shareWithAccounts,
+		})
+		if err != nil {
+			ctx.Logger().Errorf("Error cloning compose %v to %s, %d clones of batch %v were created: %v", composeId, region, len(resp.Clones), batchId, err)
+			return fail(i, composerError(err, "Something went wrong creating the clone"))
+		}
+
+		err = h.server.db.InsertClone(composeId, cloneResponse.Id, rawCR, &batchId)
+		if err != nil {
+			ctx.Logger().Errorf("Error inserting clone into db for compose %v: %v", composeId, err)
+			return fail(i, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong saving the clone"))
+		}
+		err = h.recordAudit(ctx, db.AuditActionClone, cloneResponse.Id, rawCR)
+		if err != nil {
+			return fail(i, err)
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("image-builder-composer service returned an error: %s", cError.Reason))
+
+		resp.Clones = append(resp.Clones, CloneBatchItem{
+			Id:     cloneResponse.Id,
+			Region: region,
+		})
 	}
 
-	var cloneResponse composer.CloneComposeResponse
-	err = json.NewDecoder(resp.Body).Decode(&cloneResponse)
+	return ctx.JSON(http.StatusCreated, resp)
+}
+
+func (h *Handlers) GetCloneBatchStatus(ctx echo.Context, id uuid.UUID) error {
+	idHeader, err := getIdentityHeader(ctx)
 	if err != nil {
-		ctx.Logger().Errorf("Unable to decode CloneComposeResponse: %v", err)
 		return err
 	}
 
-	err = h.server.db.InsertClone(composeId, cloneResponse.Id, rawCR)
+	cloneEntries, err := h.server.db.GetCloneBatch(id, idHeader.Identity.OrgID)
 	if err != nil {
-		ctx.Logger().Errorf("Error inserting clone into db for compose %v: %v", err, composeId)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong saving the clone")
+		if errors.Is(err, db.CloneBatchNotFoundError) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		ctx.Logger().Errorf("Error querying clone batch %v: %v", id, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying this clone batch")
 	}
 
-	return ctx.JSON(http.StatusCreated, CloneResponse{
-		Id: cloneResponse.Id,
-	})
+	batchStatus := CloneBatchStatus{
+		Clones: []CloneBatchStatusItem{},
+	}
+	statuses := map[UploadStatusStatus]int{}
+	for _, cloneEntry := range cloneEntries {
+		var cloneReq AWSEC2Clone
+		err = json.Unmarshal(cloneEntry.Request, &cloneReq)
+		if err != nil {
+			ctx.Logger().Errorf("Error unmarshalling request of clone %v: %v", cloneEntry.Id, err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying this clone batch")
+		}
+
+		cClient, err := h.composerClient(cloneEntry.Backend)
+		if err != nil {
+			return err
+		}
+		cloudStat, err := cClient.CloneStatus(ctx.Request().Context(), cloneEntry.Id)
+		if err != nil {
+			return composerError(err, "Failed querying clone status")
+		}
+
+		status := UploadStatusStatus(cloudStat.Status)
+		statuses[status]++
+		batchStatus.Clones = append(batchStatus.Clones, CloneBatchStatusItem{
+			Id:     cloneEntry.Id,
+			Region: cloneReq.Region,
+			UploadStatus: UploadStatus{
+				Status:  status,
+				Type:    UploadTypes(cloudStat.Type),
+				Options: cloudStat.Options,
+			},
+		})
+	}
+
+	switch {
+	case statuses[UploadStatusStatusFailure] > 0:
+		batchStatus.Status = UploadStatusStatusFailure
+	case statuses[UploadStatusStatusSuccess] == len(cloneEntries):
+		batchStatus.Status = UploadStatusStatusSuccess
+	case statuses[UploadStatusStatusRunning] > 0:
+		batchStatus.Status = UploadStatusStatusRunning
+	default:
+		batchStatus.Status = UploadStatusStatusPending
+	}
+
+	return ctx.JSON(http.StatusOK, batchStatus)
 }
 
 func (h *Handlers) GetCloneStatus(ctx echo.Context, id uuid.UUID) error {
@@ -1077,26 +1502,13 @@ func (h *Handlers) GetCloneStatus(ctx echo.Context, id uuid.UUID) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Requested clone cannot be found")
 	}
 
-	resp, err := h.server.cClient.CloneStatus(id)
+	cClient, err := h.composerClient(cloneEntry.Backend)
 	if err != nil {
-		ctx.Logger().Errorf("Error requesting clone status for clone %v: %v", id, err)
 		return err
 	}
-	defer closeBody(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		var cErr composer.Error
-		err = json.NewDecoder(resp.Body).Decode(&cErr)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to parse composer error")
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Unable to create clone job: %v", cErr.Reason))
-	}
-
-	var cloudStat composer.CloneStatus
-	err = json.NewDecoder(resp.Body).Decode(&cloudStat)
+	cloudStat, err := cClient.CloneStatus(ctx.Request().Context(), id)
 	if err != nil {
-		ctx.Logger().Errorf("Unable to decode clone status: %v", err)
-		return err
+		return composerError(err, "Failed querying clone status")
 	}
 
 	return ctx.JSON(http.StatusOK, UploadStatus{
@@ -1127,11 +1539,23 @@ func (h *Handlers) GetComposeClones(ctx echo.Context, composeId uuid.UUID, param
 		offset = *params.Offset
 	}
 
-	cloneEntries, count, err := h.server.db.GetClonesForCompose(composeId, idHeader.Identity.OrgID, limit, offset)
+	var keyset *db.Keyset
+	if params.Cursor != nil {
+		c, err := decodeCursor(*params.Cursor, "clones", "")
+		if err != nil {
+			return err
+		}
+		keyset = c.keyset()
+	}
+	before := keyset != nil && keyset.Before
+
+	cloneEntries, count, err := h.server.db.GetClonesForCompose(composeId, idHeader.Identity.OrgID, keyset, limit+1, offset)
 	if err != nil {
 		ctx.Logger().Errorf("Error querying clones for compose %v: %v", composeId, err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying clones for this compose")
 	}
+	from, to, more := pageBounds(len(cloneEntries), limit, before)
+	cloneEntries = cloneEntries[from:to]
 
 	data := []ClonesResponseItem{}
 	for _, c := range cloneEntries {
@@ -1142,16 +1566,31 @@ func (h *Handlers) GetComposeClones(ctx echo.Context, composeId uuid.UUID, param
 		})
 	}
 
-	lastOffset := count - 1
-	if lastOffset < 0 {
-		lastOffset = 0
-	}
-
 	spec, err := GetSwagger()
 	if err != nil {
 		return err
 	}
 
+	cloneCursor := func(c db.CloneEntry, before bool) cursor {
+		return cursor{
+			List:      "clones",
+			Before:    before,
+			CreatedAt: &c.CreatedAt,
+			Id:        &c.Id,
+		}
+	}
+	var next, prev *string
+	if len(cloneEntries) > 0 {
+		prefix := fmt.Sprintf("%v/v%v/composes/%v/clones?", RoutePrefix(), spec.Info.Version, composeId)
+		hasNext, hasPrev := adjacentPages(before, more, keyset != nil || offset > 0)
+		if hasNext {
+			next = cursorLink(prefix, limit, cloneCursor(cloneEntries[len(cloneEntries)-1], false), "")
+		}
+		if hasPrev {
+			prev = cursorLink(prefix, limit, cloneCursor(cloneEntries[0], true), "")
+		}
+	}
+
 	return ctx.JSON(http.StatusOK, ClonesResponse{
 		Meta: struct {
 			Count int `json:"count"`
@@ -1159,13 +1598,17 @@ func (h *Handlers) GetComposeClones(ctx echo.Context, composeId uuid.UUID, param
 			count,
 		},
 		Links: struct {
-			First string `json:"first"`
-			Last  string `json:"last"`
+			First string  `json:"first"`
+			Last  string  `json:"last"`
+			Next  *string `json:"next,omitempty"`
+			Prev  *string `json:"prev,omitempty"`
 		}{
 			fmt.Sprintf("%v/v%v/composes/%v/clones?offset=%v&limit=%v",
 				RoutePrefix(), spec.Info.Version, composeId, 0, limit),
 			fmt.Sprintf("%v/v%v/composes/%v/clones?offset=%v&limit=%v",
-				RoutePrefix(), spec.Info.Version, composeId, lastOffset, limit),
+				RoutePrefix(), spec.Info.Version, composeId, lastPageOffset(count, limit), limit),
+			next,
+			prev,
 		},
 		Data: data,
 	})
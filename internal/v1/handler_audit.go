@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// recordAudit stores who performed a mutating call on which compose, clone or
+// subscription profile. A mutation which can't be audited fails the request,
+// so it isn't reported as successful without an audit entry.
+func (h *Handlers) recordAudit(ctx echo.Context, action string, targetId uuid.UUID, request []byte) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	var requestHash string
+	if len(request) > 0 {
+		sum := sha256.Sum256(request)
+		requestHash = hex.EncodeToString(sum[:])
+	}
+
+	err = h.server.db.InsertAuditEntry(idHeader.Identity.OrgID, idHeader.Identity.AccountNumber, idHeader.Identity.User.Username, action, targetId, requestHash)
+	if err != nil {
+		ctx.Logger().Errorf("Unable to record %s of %v in the audit log: %v", action, targetId, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong recording the audit entry")
+	}
+	return nil
+}
+
+func (h *Handlers) GetAudit(ctx echo.Context, params GetAuditParams) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !idHeader.Identity.User.OrgAdmin {
+		return echo.NewHTTPError(http.StatusForbidden, "Only organization administrators can access the audit log")
+	}
+
+	limit := 100
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+	}
+
+	offset := 0
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+
+	entries, count, err := h.server.db.GetAuditEntries(idHeader.Identity.OrgID, limit, offset)
+	if err != nil {
+		ctx.Logger().Errorf("Error querying the audit log: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying the audit log")
+	}
+
+	data := []AuditResponseItem{}
+	for _, e := range entries {
+		data = append(data, AuditResponseItem{
+			Id:            e.Id,
+			AccountNumber: e.AccountNumber,
+			Username:      e.Username,
+			Action:        e.Action,
+			TargetId:      e.TargetId,
+			RequestHash:   e.RequestHash,
+			CreatedAt:     e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, AuditResponse{
+		Meta: struct {
+			Count int `json:"count"`
+		}{
+			count,
+		},
+		Links: struct {
+			First string `json:"first"`
+			Last  string `json:"last"`
+		}{
+			fmt.Sprintf("%v/v%v/audit?offset=0&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, limit),
+			fmt.Sprintf("%v/v%v/audit?offset=%v&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, lastPageOffset(count, limit), limit),
+		},
+		Data: data,
+	})
+}
@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/composer"
+	"github.com/osbuild/image-builder/internal/distribution"
+)
+
+func (h *Handlers) GetOscapProfiles(ctx echo.Context, distro string) error {
+	d, err := h.server.distroRegistry(ctx).Get(distro)
+	if err == distribution.DistributionNotFound {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	profiles := OscapProfiles{}
+	for _, p := range d.OscapProfiles {
+		profiles = append(profiles, OscapProfile{
+			ProfileId:   p.Id,
+			Name:        p.Name,
+			Description: p.Description,
+			Customizations: OscapCustomizations{
+				Packages: nonNilStrings(p.Packages),
+				Services: Services{
+					Enabled:  nonNilStrings(p.Services.Enabled),
+					Disabled: nonNilStrings(p.Services.Disabled),
+				},
+			},
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, profiles)
+}
+
+// applyOscapProfile checks the OpenSCAP customization against the profiles
+// of the distribution and merges the packages and services the profile needs
+// into the customizations sent to composer.
+func applyOscapProfile(cust *Customizations, d *distribution.DistributionFile, res *composer.Customizations) error {
+	if cust == nil || cust.Openscap == nil {
+		return nil
+	}
+
+	profile, err := d.OscapProfile(cust.Openscap.ProfileId)
+	if err == distribution.OscapProfileNotFound {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("OpenSCAP profile %s isn't available for %s", cust.Openscap.ProfileId, d.Distribution.Name))
+	}
+	if err != nil {
+		return err
+	}
+
+	if t := cust.Openscap.Tailoring; t != nil && t.Selected != nil && t.Unselected != nil {
+		unselected := map[string]bool{}
+		for _, r := range *t.Unselected {
+			unselected[r] = true
+		}
+		for _, r := range *t.Selected {
+			if unselected[r] {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("OpenSCAP rule %s can't be both selected and unselected", r))
+			}
+		}
+	}
+
+	var packages []string
+	if res.Packages != nil {
+		packages = append(packages, *res.Packages...)
+	}
+	packages = appendMissing(packages, profile.Packages)
+	if len(packages) > 0 {
+		res.Packages = &packages
+	}
+
+	enabled := appendMissing(nil, profile.Services.Enabled)
+	disabled := appendMissing(nil, profile.Services.Disabled)
+	if len(enabled) > 0 || len(disabled) > 0 {
+		res.Services = &composer.Services{}
+		if len(enabled) > 0 {
+			res.Services.Enabled = &enabled
+		}
+		if len(disabled) > 0 {
+			res.Services.Disabled = &disabled
+		}
+	}
+
+	return nil
+}
+
+// appendMissing appends the values not in list yet
+func appendMissing(list []string, values []string) []string {
+	seen := map[string]bool{}
+	for _, v := range list {
+		seen[v] = true
+	}
+	for _, v := range values {
+		if !seen[v] {
+			list = append(list, v)
+			seen[v] = true
+		}
+	}
+	return list
+}
+
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,327 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/composer"
+	"github.com/osbuild/image-builder/internal/db"
+	"github.com/osbuild/image-builder/internal/ostree"
+)
+
+const (
+	// lineageDepth is how many of the most recent commits of a ref are
+	// searched for a successful one to build on
+	lineageDepth = 100
+
+	// lineageLookups is how many of those commits, whose checksum isn't
+	// known yet, are looked up in composer
+	lineageLookups = 5
+)
+
+// isOSTreeCommit tells whether the image type is a commit, which is part of
+// the lineage of its ref
+func isOSTreeCommit(it ImageTypes) bool {
+	return it == ImageTypesEdgeCommit || it == ImageTypesRhelEdgeCommit
+}
+
+// isOSTreeImageType tells whether the image type is built from or into an
+// OSTree repository, and so takes OSTree options
+func isOSTreeImageType(it ImageTypes) bool {
+	switch it {
+	case ImageTypesEdgeCommit, ImageTypesRhelEdgeCommit, ImageTypesEdgeInstaller, ImageTypesRhelEdgeInstaller:
+		return true
+	}
+	return false
+}
+
+// validateOSTree checks the syntax and consistency of the OSTree options,
+// composer would only fail the compose once it tries to resolve them.
+func validateOSTree(ir ImageRequest) error {
+	o := ir.Ostree
+	if o == nil {
+		return nil
+	}
+
+	if !isOSTreeImageType(ir.ImageType) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("OSTree options don't apply to %s images", ir.ImageType))
+	}
+
+	if o.Ref != nil && *o.Ref != "" {
+		err := ostree.ValidateRef(*o.Ref)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	if o.Url != nil {
+		err := ostree.ValidateRepoURL(*o.Url)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	if o.Contenturl != nil {
+		if o.Url == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "The OSTree content url requires the url of the repository")
+		}
+		err := ostree.ValidateRepoURL(*o.Contenturl)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	if o.Parent != nil {
+		if !isOSTreeCommit(ir.ImageType) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Only edge commits can have a parent")
+		}
+		if o.Url == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "The OSTree parent is fetched from the url of the repository, which has to be set")
+		}
+		if !ostree.IsChecksum(*o.Parent) && ostree.ValidateRef(*o.Parent) != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid ostree parent %q, expected the checksum of a commit or a ref", *o.Parent))
+		}
+	}
+
+	return nil
+}
+
+// verifyOSTreeParent looks up the parent in the repository when the server
+// is configured to, so a missing parent fails the request rather than the
+// compose. A repository which can't be reached from here is left to
+// composer, it's no fault of the request.
+func (h *Handlers) verifyOSTreeParent(ctx echo.Context, options *composer.OSTree) error {
+	if h.server.ostreeRemote == nil || options == nil || options.Parent == nil || options.Url == nil {
+		return nil
+	}
+
+	_, err := h.server.ostreeRemote.ResolveParent(ctx.Request().Context(), *options.Url, *options.Parent)
+	if errors.Is(err, ostree.RefNotFound) || errors.Is(err, ostree.CommitNotFound) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("OSTree parent %s isn't in the repository %s", *options.Parent, *options.Url))
+	}
+	var unavailable *ostree.UnavailableError
+	if errors.As(err, &unavailable) {
+		ctx.Logger().Warnf("Skipping the check of ostree parent %s: %v", *options.Parent, err)
+		return nil
+	}
+	if err != nil {
+		ctx.Logger().Errorf("Unable to resolve ostree parent %s in %s: %v", *options.Parent, *options.Url, err)
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to resolve OSTree parent %s in the repository %s", *options.Parent, *options.Url))
+	}
+	return nil
+}
+
+// buildOSTree returns the OSTree options of the image request, with the
+// parent taken from the lineage of the ref when asked for, along with the
+// compose of that parent.
+func (h *Handlers) buildOSTree(ctx echo.Context, orgId string, ir ImageRequest) (*composer.OSTree, *uuid.UUID, error) {
+	options := buildOSTreeOptions(ir.Ostree)
+	if ir.Ostree == nil || ir.Ostree.Lineage == nil || !*ir.Ostree.Lineage {
+		return options, nil, nil
+	}
+
+	if !isOSTreeCommit(ir.ImageType) {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Only edge commits can be built on top of the lineage of a ref")
+	}
+	if ir.Ostree.Ref == nil || *ir.Ostree.Ref == "" {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Building on top of the lineage of a ref requires the ref")
+	}
+	if ir.Ostree.Parent != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "The parent is taken from the lineage of the ref, it can't be set as well")
+	}
+
+	parent, err := h.lastOSTreeCommit(ctx, orgId, *ir.Ostree.Ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent == nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("There is no successful commit of %s to build on", *ir.Ostree.Ref))
+	}
+
+	if options.Url == nil {
+		options.Url = parent.Url
+	}
+	if options.Url == nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("The url of the repository serving the commits of %s is unknown, it has to be set", *ir.Ostree.Ref))
+	}
+	options.Parent = parent.Commit
+	return options, &parent.ComposeId, nil
+}
+
+// lastOSTreeCommit returns the most recent successful commit of the ref, or
+// nil when there is none. Only the first few commits whose checksum isn't
+// known yet are looked up in composer, older ones are skipped unless their
+// checksum is stored.
+func (h *Handlers) lastOSTreeCommit(ctx echo.Context, orgId, ref string) (*db.OSTreeCommitEntry, error) {
+	entries, _, err := h.server.db.GetOSTreeCommits(orgId, ref, lineageDepth, 0)
+	if err != nil {
+		ctx.Logger().Errorf("Error querying the commits of %s: %v", ref, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying the commits of the ref")
+	}
+
+	lookups := 0
+	for i := range entries {
+		e := &entries[i]
+		if e.Commit == nil && lookups < lineageLookups &&
+			(e.Status == nil || *e.Status != string(composer.ImageStatusValueFailure)) {
+			lookups++
+			h.resolveOSTreeCommit(ctx, e)
+		}
+		if e.Commit != nil {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveOSTreeCommit asks composer for the checksum of a commit which isn't
+// known yet and stores it. The checksum stays unknown when composer can't be
+// reached, the compose didn't succeed (yet), or it isn't in the metadata.
+func (h *Handlers) resolveOSTreeCommit(ctx echo.Context, entry *db.OSTreeCommitEntry) {
+
+	cClient, err := h.composerClient(entry.Backend)
+	if err != nil {
+		ctx.Logger().Errorf("Unable to resolve the commit of compose %v: %v", entry.ComposeId, err)
+		return
+	}
+	cloudStat, err := cClient.ComposeStatus(ctx.Request().Context(), entry.ComposeId)
+	if err != nil {
+		ctx.Logger().Errorf("Unable to resolve the commit of compose %v: %v", entry.ComposeId, err)
+		return
+	}
+	status := string(cloudStat.ImageStatus.Status)
+	entry.Status = &status
+	err = h.server.db.UpdateComposeStatus(entry.ComposeId, status)
+	if err != nil {
+		ctx.Logger().Errorf("Error storing status of compose %v: %v", entry.ComposeId, err)
+	}
+	if cloudStat.ImageStatus.Status != composer.ImageStatusValueSuccess {
+		return
+	}
+
+	metadata, err := cClient.ComposeMetadata(ctx.Request().Context(), entry.ComposeId)
+	if err != nil {
+		ctx.Logger().Errorf("Unable to resolve the commit of compose %v: %v", entry.ComposeId, err)
+		return
+	}
+	if metadata.OstreeCommit == nil {
+		return
+	}
+	entry.Commit = metadata.OstreeCommit
+	err = h.server.db.UpdateOSTreeCommit(entry.ComposeId, *metadata.OstreeCommit)
+	if err != nil {
+		ctx.Logger().Errorf("Error storing the commit of compose %v: %v", entry.ComposeId, err)
+	}
+}
+
+func (h *Handlers) GetOSTreeRefs(ctx echo.Context, params GetOSTreeRefsParams) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	limit := 100
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+	}
+
+	offset := 0
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+
+	entries, count, err := h.server.db.GetOSTreeRefs(idHeader.Identity.OrgID, limit, offset)
+	if err != nil {
+		ctx.Logger().Errorf("Error querying the ostree refs: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying the refs")
+	}
+
+	data := []OSTreeRefsResponseItem{}
+	for _, e := range entries {
+		data = append(data, OSTreeRefsResponseItem{
+			Ref:          e.Ref,
+			Commits:      e.Commits,
+			LastCommitAt: e.LastCommitAt.Format(time.RFC3339),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, OSTreeRefsResponse{
+		Meta: struct {
+			Count int `json:"count"`
+		}{
+			count,
+		},
+		Links: struct {
+			First string `json:"first"`
+			Last  string `json:"last"`
+		}{
+			fmt.Sprintf("%v/v%v/ostree/refs?offset=0&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, limit),
+			fmt.Sprintf("%v/v%v/ostree/refs?offset=%v&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, lastPageOffset(count, limit), limit),
+		},
+		Data: data,
+	})
+}
+
+func (h *Handlers) GetOSTreeCommits(ctx echo.Context, params GetOSTreeCommitsParams) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	limit := 100
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+	}
+
+	offset := 0
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+
+	entries, count, err := h.server.db.GetOSTreeCommits(idHeader.Identity.OrgID, params.Ref, limit, offset)
+	if err != nil {
+		ctx.Logger().Errorf("Error querying the commits of %s: %v", params.Ref, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying the commits of the ref")
+	}
+
+	data := []OSTreeCommitsResponseItem{}
+	for _, e := range entries {
+		data = append(data, OSTreeCommitsResponseItem{
+			ComposeId:       e.ComposeId,
+			Ref:             e.Ref,
+			Url:             e.Url,
+			Parent:          e.Parent,
+			ParentComposeId: e.ParentComposeId,
+			Commit:          e.Commit,
+			Status:          e.Status,
+			CreatedAt:       e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	ref := url.QueryEscape(params.Ref)
+	return ctx.JSON(http.StatusOK, OSTreeCommitsResponse{
+		Meta: struct {
+			Count int `json:"count"`
+		}{
+			count,
+		},
+		Links: struct {
+			First string `json:"first"`
+			Last  string `json:"last"`
+		}{
+			fmt.Sprintf("%v/v%v/ostree/commits?ref=%v&offset=0&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, ref, limit),
+			fmt.Sprintf("%v/v%v/ostree/commits?ref=%v&offset=%v&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, ref, lastPageOffset(count, limit), limit),
+		},
+		Data: data,
+	})
+}
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/common"
+	"github.com/osbuild/image-builder/internal/composer"
+	"github.com/osbuild/image-builder/internal/gpgkey"
+)
+
+// maxGPGKeyURLs bounds how many keys a compose request has fetched
+const maxGPGKeyURLs = 16
+
+// validateRepositories checks the gpg keys of the payload and custom
+// repositories, and that the repositories checking signatures have a key to
+// check them with. dnf would only fail once composer depsolves.
+func validateRepositories(cust *Customizations) error {
+	urls := 0
+
+	if cust.PayloadRepositories != nil {
+		for i, r := range *cust.PayloadRepositories {
+			var keys []string
+			if r.Gpgkey != nil && *r.Gpgkey != "" {
+				keys = []string{*r.Gpgkey}
+			}
+			n, err := validateGPGKeys(fmt.Sprintf("Payload repository %d", i), keys, r.CheckGpg, r.CheckRepoGpg)
+			if err != nil {
+				return err
+			}
+			urls += n
+		}
+	}
+
+	if cust.CustomRepositories != nil {
+		for _, r := range *cust.CustomRepositories {
+			var keys []string
+			if r.Gpgkey != nil {
+				keys = *r.Gpgkey
+			}
+			n, err := validateGPGKeys(fmt.Sprintf("Custom repository %s", r.Id), keys, r.CheckGpg, r.CheckRepoGpg)
+			if err != nil {
+				return err
+			}
+			urls += n
+		}
+	}
+
+	if urls > maxGPGKeyURLs {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("At most %d gpg keys can be given as urls", maxGPGKeyURLs))
+	}
+	return nil
+}
+
+// validateGPGKeys checks that each key is either an armored public key or
+// the url to fetch one from, it returns how many keys are urls
+func validateGPGKeys(repo string, keys []string, checkGpg, checkRepoGpg *bool) (int, error) {
+	if len(keys) == 0 {
+		if checkGpg != nil && *checkGpg {
+			return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s checks package signatures but has no gpg key", repo))
+		}
+		if checkRepoGpg != nil && *checkRepoGpg {
+			return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s checks metadata signatures but has no gpg key", repo))
+		}
+		return 0, nil
+	}
+
+	urls := 0
+	for _, k := range keys {
+		if gpgkey.IsURL(k) {
+			urls++
+			continue
+		}
+		err := gpgkey.Validate(k)
+		if err != nil {
+			return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s has an invalid gpg key, expected an armored public key or an http or https url: %v", repo, err))
+		}
+	}
+	return urls, nil
+}
+
+// resolveGPGKeys replaces the keys given as urls by the keys they point to,
+// so the compose doesn't depend on the urls staying reachable. Repositories
+// with keys check package signatures unless told otherwise.
+func (h *Handlers) resolveGPGKeys(ctx echo.Context, cust *composer.Customizations) error {
+	if cust == nil {
+		return nil
+	}
+
+	fetched := map[string]string{}
+	fetch := func(key string) (string, error) {
+		if !gpgkey.IsURL(key) {
+			return key, nil
+		}
+		if k, ok := fetched[key]; ok {
+			return k, nil
+		}
+		k, err := h.server.gpgKeys.Fetch(ctx.Request().Context(), key)
+		if errors.Is(err, gpgkey.InvalidKeyError) {
+			return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("The gpg key at %s isn't an armored public key", key))
+		}
+		if err != nil {
+			ctx.Logger().Warnf("Unable to fetch gpg key %s: %v", key, err)
+			return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unable to fetch the gpg key at %s", key))
+		}
+		fetched[key] = k
+		return k, nil
+	}
+
+	if cust.PayloadRepositories != nil {
+		for i := range *cust.PayloadRepositories {
+			r := &(*cust.PayloadRepositories)[i]
+			if r.Gpgkey == nil || *r.Gpgkey == "" {
+				continue
+			}
+			k, err := fetch(*r.Gpgkey)
+			if err != nil {
+				return err
+			}
+			r.Gpgkey = &k
+			if r.CheckGpg == nil {
+				r.CheckGpg = common.BoolToPtr(true)
+			}
+		}
+	}
+
+	if cust.CustomRepositories != nil {
+		for i := range *cust.CustomRepositories {
+			r := &(*cust.CustomRepositories)[i]
+			if r.Gpgkey == nil || len(*r.Gpgkey) == 0 {
+				continue
+			}
+			keys := make([]string, len(*r.Gpgkey))
+			for j, key := range *r.Gpgkey {
+				k, err := fetch(key)
+				if err != nil {
+					return err
+				}
+				keys[j] = k
+			}
+			r.Gpgkey = &keys
+			if r.CheckGpg == nil {
+				r.CheckGpg = common.BoolToPtr(true)
+			}
+		}
+	}
+
+	return nil
+}
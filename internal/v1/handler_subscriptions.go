@@ -0,0 +1,282 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/common"
+	"github.com/osbuild/image-builder/internal/composer"
+	"github.com/osbuild/image-builder/internal/db"
+)
+
+// activationKeyAD binds an encrypted activation key to its profile
+func activationKeyAD(orgId string, id uuid.UUID) []byte {
+	return []byte(fmt.Sprintf("subscription_profiles/%s/%s", orgId, id))
+}
+
+func subscriptionProfile(e db.SubscriptionProfileEntry) SubscriptionProfile {
+	return SubscriptionProfile{
+		Id:           e.Id,
+		Name:         e.Name,
+		Organization: e.Organization,
+		ServerUrl:    e.ServerUrl,
+		BaseUrl:      e.BaseUrl,
+		Insights:     e.Insights,
+		Rhc:          e.Rhc,
+		CreatedAt:    e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    e.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// subscriptionProfileError returns the response to an error of the database,
+// the errors which aren't the caller's are logged and replaced by msg
+func subscriptionProfileError(ctx echo.Context, err error, msg string) error {
+	if errors.Is(err, db.SubscriptionProfileNotFoundError) {
+		return echo.NewHTTPError(http.StatusNotFound, err)
+	}
+	if errors.Is(err, db.SubscriptionProfileExistsError) {
+		return echo.NewHTTPError(http.StatusConflict, err)
+	}
+	ctx.Logger().Errorf("%s: %v", msg, err)
+	return echo.NewHTTPError(http.StatusInternalServerError, msg)
+}
+
+// bindSubscriptionProfile reads the request into an entry, encrypting the
+// activation key when there is one. The request is returned as well, with
+// the activation key redacted, to be recorded in the audit log.
+func (h *Handlers) bindSubscriptionProfile(ctx echo.Context, orgId string, id uuid.UUID) (db.SubscriptionProfileEntry, []byte, error) {
+	var req SubscriptionProfileRequest
+	err := ctx.Bind(&req)
+	if err != nil {
+		return db.SubscriptionProfileEntry{}, nil, err
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return db.SubscriptionProfileEntry{}, nil, echo.NewHTTPError(http.StatusBadRequest, "Subscription profile name can't be empty")
+	}
+
+	entry := db.SubscriptionProfileEntry{
+		Id:           id,
+		Name:         req.Name,
+		Organization: req.Organization,
+		ServerUrl:    req.ServerUrl,
+		BaseUrl:      req.BaseUrl,
+		Insights:     req.Insights,
+		Rhc:          req.Rhc != nil && *req.Rhc,
+	}
+	if req.ActivationKey != nil {
+		if *req.ActivationKey == "" {
+			return db.SubscriptionProfileEntry{}, nil, echo.NewHTTPError(http.StatusBadRequest, "Activation key can't be empty")
+		}
+		entry.ActivationKey, err = h.server.secrets.Encrypt([]byte(*req.ActivationKey), activationKeyAD(orgId, id))
+		if err != nil {
+			return db.SubscriptionProfileEntry{}, nil, err
+		}
+		req.ActivationKey = common.StringToPtr(redactedSecret)
+	}
+
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return db.SubscriptionProfileEntry{}, nil, err
+	}
+	return entry, raw, nil
+}
+
+// checkSecrets fails the request when the server has no key to encrypt
+// activation keys with
+func (h *Handlers) checkSecrets() error {
+	if h.server.secrets == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Subscription profiles aren't available")
+	}
+	return nil
+}
+
+func (h *Handlers) GetSubscriptionProfiles(ctx echo.Context, params GetSubscriptionProfilesParams) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	limit := 100
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+	}
+
+	offset := 0
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+
+	entries, count, err := h.server.db.GetSubscriptionProfiles(idHeader.Identity.OrgID, limit, offset)
+	if err != nil {
+		ctx.Logger().Errorf("Error querying the subscription profiles: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong querying the subscription profiles")
+	}
+
+	data := []SubscriptionProfile{}
+	for _, e := range entries {
+		data = append(data, subscriptionProfile(e))
+	}
+
+	return ctx.JSON(http.StatusOK, SubscriptionProfilesResponse{
+		Meta: struct {
+			Count int `json:"count"`
+		}{
+			count,
+		},
+		Links: struct {
+			First string `json:"first"`
+			Last  string `json:"last"`
+		}{
+			fmt.Sprintf("%v/v%v/subscriptions?offset=0&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, limit),
+			fmt.Sprintf("%v/v%v/subscriptions?offset=%v&limit=%v",
+				RoutePrefix(), h.server.spec.Info.Version, lastPageOffset(count, limit), limit),
+		},
+		Data: data,
+	})
+}
+
+func (h *Handlers) CreateSubscriptionProfile(ctx echo.Context) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.checkSecrets()
+	if err != nil {
+		return err
+	}
+
+	entry, raw, err := h.bindSubscriptionProfile(ctx, idHeader.Identity.OrgID, uuid.New())
+	if err != nil {
+		return err
+	}
+	if entry.ActivationKey == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Subscription profile needs an activation key")
+	}
+
+	err = h.server.db.InsertSubscriptionProfile(idHeader.Identity.OrgID, entry)
+	if err != nil {
+		return subscriptionProfileError(ctx, err, "Something went wrong storing the subscription profile")
+	}
+	err = h.recordAudit(ctx, db.AuditActionCreateSubscriptionProfile, entry.Id, raw)
+	if err != nil {
+		return err
+	}
+
+	created, err := h.server.db.GetSubscriptionProfile(entry.Id, idHeader.Identity.OrgID)
+	if err != nil {
+		return subscriptionProfileError(ctx, err, "Something went wrong querying the subscription profile")
+	}
+	return ctx.JSON(http.StatusCreated, subscriptionProfile(*created))
+}
+
+func (h *Handlers) GetSubscriptionProfile(ctx echo.Context, id uuid.UUID) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	entry, err := h.server.db.GetSubscriptionProfile(id, idHeader.Identity.OrgID)
+	if err != nil {
+		return subscriptionProfileError(ctx, err, "Something went wrong querying the subscription profile")
+	}
+	return ctx.JSON(http.StatusOK, subscriptionProfile(*entry))
+}
+
+func (h *Handlers) UpdateSubscriptionProfile(ctx echo.Context, id uuid.UUID) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.checkSecrets()
+	if err != nil {
+		return err
+	}
+
+	entry, raw, err := h.bindSubscriptionProfile(ctx, idHeader.Identity.OrgID, id)
+	if err != nil {
+		return err
+	}
+
+	err = h.server.db.UpdateSubscriptionProfile(idHeader.Identity.OrgID, entry)
+	if err != nil {
+		return subscriptionProfileError(ctx, err, "Something went wrong updating the subscription profile")
+	}
+	err = h.recordAudit(ctx, db.AuditActionUpdateSubscriptionProfile, id, raw)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.server.db.GetSubscriptionProfile(id, idHeader.Identity.OrgID)
+	if err != nil {
+		return subscriptionProfileError(ctx, err, "Something went wrong querying the subscription profile")
+	}
+	return ctx.JSON(http.StatusOK, subscriptionProfile(*updated))
+}
+
+func (h *Handlers) DeleteSubscriptionProfile(ctx echo.Context, id uuid.UUID) error {
+	idHeader, err := getIdentityHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.server.db.DeleteSubscriptionProfile(id, idHeader.Identity.OrgID)
+	if err != nil {
+		return subscriptionProfileError(ctx, err, "Something went wrong deleting the subscription profile")
+	}
+	err = h.recordAudit(ctx, db.AuditActionDeleteSubscriptionProfile, id, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// resolveSubscriptionProfile returns the subscription of the profile the
+// customizations reference, or nil when they don't reference one
+func (h *Handlers) resolveSubscriptionProfile(ctx echo.Context, orgId string, cust *Customizations) (*composer.Subscription, error) {
+	if cust == nil || cust.SubscriptionProfileId == nil {
+		return nil, nil
+	}
+	if cust.Subscription != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Either the subscription or a subscription profile can be set, not both")
+	}
+
+	err := h.checkSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	id := *cust.SubscriptionProfileId
+	entry, err := h.server.db.GetSubscriptionProfile(id, orgId)
+	if errors.Is(err, db.SubscriptionProfileNotFoundError) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Subscription profile %v not found", id))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := h.server.secrets.Decrypt(entry.ActivationKey, activationKeyAD(orgId, id))
+	if err != nil {
+		ctx.Logger().Errorf("Unable to decrypt the activation key of subscription profile %v: %v", id, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to read the activation key of the subscription profile")
+	}
+
+	return &composer.Subscription{
+		ActivationKey: string(key),
+		BaseUrl:       entry.BaseUrl,
+		Insights:      entry.Insights,
+		Rhc:           common.BoolToPtr(entry.Rhc),
+		Organization:  fmt.Sprintf("%d", entry.Organization),
+		ServerUrl:     entry.ServerUrl,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/common"
+	"github.com/osbuild/image-builder/internal/db"
+)
+
+var labelKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/-]{0,61}[a-zA-Z0-9])?$`)
+
+// validateLabels checks the label keys. The number of labels and the length of
+// the values are validated against the spec already.
+func validateLabels(labels *Labels) error {
+	if labels == nil {
+		return nil
+	}
+	for k := range labels.AdditionalProperties {
+		if !labelKeyRegex.MatchString(k) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid label key %q", k))
+		}
+	}
+	return nil
+}
+
+// parseLabelSelector parses a comma separated list of label requirements, each
+// one of 'key=value', 'key==value', 'key!=value', 'key' or '!key'.
+func parseLabelSelector(selector string) ([]db.LabelRequirement, error) {
+	var reqs []db.LabelRequirement
+	for _, s := range strings.Split(selector, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		var req db.LabelRequirement
+		if i := strings.Index(s, "!="); i >= 0 {
+			req = db.LabelRequirement{Key: s[:i], Value: common.StringToPtr(s[i+2:]), Negate: true}
+		} else if i := strings.Index(s, "=="); i >= 0 {
+			req = db.LabelRequirement{Key: s[:i], Value: common.StringToPtr(s[i+2:])}
+		} else if i := strings.Index(s, "="); i >= 0 {
+			req = db.LabelRequirement{Key: s[:i], Value: common.StringToPtr(s[i+1:])}
+		} else if strings.HasPrefix(s, "!") {
+			req = db.LabelRequirement{Key: s[1:], Negate: true}
+		} else {
+			req = db.LabelRequirement{Key: s}
+		}
+
+		req.Key = strings.TrimSpace(req.Key)
+		if !labelKeyRegex.MatchString(req.Key) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid label selector %q", s))
+		}
+		if req.Value != nil {
+			v := strings.TrimSpace(*req.Value)
+			req.Value = &v
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
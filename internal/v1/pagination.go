@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/db"
+)
+
+// cursor is the decoded form of the opaque cursor query parameter. It holds
+// the sort key of the row at the edge of a page, the next page starts right
+// after it, the previous one ends right before it.
+type cursor struct {
+	// Listing the cursor belongs to, a cursor can't be used with another one
+	List string `json:"l"`
+	// Sort order of the listing the cursor was made for
+	Sort   string `json:"o,omitempty"`
+	Before bool   `json:"b,omitempty"`
+
+	CreatedAt *time.Time `json:"c,omitempty"`
+	ImageName *string    `json:"n,omitempty"`
+	Id        *uuid.UUID `json:"i,omitempty"`
+
+	// Packages are keyed by name and summary
+	Name    string `json:"p,omitempty"`
+	Summary string `json:"s,omitempty"`
+}
+
+func (c cursor) encode() string {
+	// marshalling the cursor can't fail
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s, list, sort string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor")
+	}
+	err = json.Unmarshal(b, &c)
+	if err != nil || c.List != list {
+		return c, echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor")
+	}
+	if c.Sort != sort {
+		return c, echo.NewHTTPError(http.StatusBadRequest, "Cursor doesn't match the sort order")
+	}
+	return c, nil
+}
+
+func (c cursor) keyset() *db.Keyset {
+	ks := db.Keyset{
+		ImageName: c.ImageName,
+		Before:    c.Before,
+	}
+	if c.CreatedAt != nil {
+		ks.CreatedAt = *c.CreatedAt
+	}
+	if c.Id != nil {
+		ks.Id = *c.Id
+	}
+	return &ks
+}
+
+// pageBounds returns the bounds of a page of limit rows within the n rows
+// fetched. One row more than the limit is fetched to tell whether the listing
+// continues past the page, which is at the front when fetching backwards.
+func pageBounds(n, limit int, before bool) (int, int, bool) {
+	if n <= limit {
+		return 0, n, false
+	}
+	if before {
+		return n - limit, n, true
+	}
+	return 0, limit, true
+}
+
+// adjacentPages tells whether the page has a next and a previous page. more is
+// whether the listing continues past the page in the direction it was fetched,
+// paged whether the page was reached through a cursor or an offset.
+func adjacentPages(before, more, paged bool) (bool, bool) {
+	if before {
+		return true, more
+	}
+	return more, paged
+}
+
+// lastPageOffset returns the offset of the last page of count rows.
+func lastPageOffset(count, limit int) int {
+	if count <= 0 {
+		return 0
+	}
+	return (count - 1) / limit * limit
+}
+
+// cursorLink returns the link to the page at the cursor. prefix is the link up
+// to the query parameters and includes a trailing '?' or '&', query holds any
+// further parameters starting with '&'.
+func cursorLink(prefix string, limit int, c cursor, query string) *string {
+	link := fmt.Sprintf("%vlimit=%v&cursor=%v%v", prefix, limit, c.encode(), query)
+	return &link
+}
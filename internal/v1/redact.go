@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// redactedSecret replaces the secrets of stored and returned requests
+const redactedSecret = "<REDACTED>"
+
+// wildcard matches every item of an array, or every value of a map
+const wildcard = "*"
+
+// sensitivePaths lists the fields of compose requests to redact besides the
+// ones declared with the password format
+var sensitivePaths = [][]string{
+	// crypt(3) hashes can still be cracked offline
+	{"customizations", "users", wildcard, "password"},
+}
+
+// redactor masks the secrets of a request, the fields holding secrets are
+// collected from its schema
+type redactor struct {
+	paths [][]string
+}
+
+func newRedactor(spec *openapi3.T, schema string, extraPaths [][]string) (*redactor, error) {
+	ref, ok := spec.Components.Schemas[schema]
+	if !ok {
+		return nil, fmt.Errorf("Schema %s not found", schema)
+	}
+
+	var paths [][]string
+	secretPaths(ref, nil, map[*openapi3.Schema]bool{}, &paths)
+	paths = append(paths, extraPaths...)
+
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Join(paths[i], ".") < strings.Join(paths[j], ".")
+	})
+	return &redactor{
+		paths: paths,
+	}, nil
+}
+
+// secretPaths walks the schema down to the fields with the password format,
+// the schemas on the way are tracked so recursive schemas end
+func secretPaths(ref *openapi3.SchemaRef, path []string, visiting map[*openapi3.Schema]bool, paths *[][]string) {
+	if ref == nil || ref.Value == nil || visiting[ref.Value] {
+		return
+	}
+	s := ref.Value
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	if s.Format == "password" {
+		*paths = append(*paths, append([]string{}, path...))
+	}
+
+	for name, p := range s.Properties {
+		secretPaths(p, append(path, name), visiting, paths)
+	}
+	secretPaths(s.Items, append(path, wildcard), visiting, paths)
+	secretPaths(s.AdditionalProperties, append(path, wildcard), visiting, paths)
+	for _, refs := range []openapi3.SchemaRefs{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, r := range refs {
+			secretPaths(r, path, visiting, paths)
+		}
+	}
+}
+
+// redact returns the request with its secrets masked, requests without
+// secrets are returned as they are
+func (r *redactor) redact(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v interface{}
+	err := decoder.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := false
+	for _, p := range r.paths {
+		if redactPath(v, p) {
+			redacted = true
+		}
+	}
+	if !redacted {
+		return raw, nil
+	}
+	return json.Marshal(v)
+}
+
+// redactPath masks the values at the path, it tells whether any value was
+// masked
+func redactPath(v interface{}, path []string) bool {
+	redacted := false
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if path[0] != wildcard && path[0] != key {
+				continue
+			}
+			if len(path) > 1 {
+				redacted = redactPath(value, path[1:]) || redacted
+			} else if value != nil && value != redactedSecret {
+				node[key] = redactedSecret
+				redacted = true
+			}
+		}
+	case []interface{}:
+		if path[0] != wildcard {
+			return false
+		}
+		for i, value := range node {
+			if len(path) > 1 {
+				redacted = redactPath(value, path[1:]) || redacted
+			} else if value != nil && value != redactedSecret {
+				node[i] = redactedSecret
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// redactedPaths returns where the request holds the mask of a secret instead
+// of the secret, as requests read back from the api do
+func (r *redactor) redactedPaths(raw json.RawMessage) ([]string, error) {
+	var v interface{}
+	err := json.Unmarshal(raw, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+	for _, p := range r.paths {
+		findRedacted(v, p, nil, &found)
+	}
+	sort.Strings(found)
+	return found, nil
+}
+
+// findRedacted collects the masked values at the path, at is the path of v
+// with the keys and indexes of the request
+func findRedacted(v interface{}, path []string, at []string, found *[]string) {
+	visit := func(key string, value interface{}) {
+		if len(path) > 1 {
+			findRedacted(value, path[1:], append(at, key), found)
+		} else if value == redactedSecret {
+			*found = append(*found, strings.Join(append(at, key), "."))
+		}
+	}
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if path[0] == wildcard || path[0] == key {
+				visit(key, value)
+			}
+		}
+	case []interface{}:
+		if path[0] != wildcard {
+			return
+		}
+		for i, value := range node {
+			visit(strconv.Itoa(i), value)
+		}
+	}
+}
@@ -3,18 +3,23 @@ package v1
 
 import (
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/osbuild/image-builder/internal/common"
 	"github.com/osbuild/image-builder/internal/composer"
 	"github.com/osbuild/image-builder/internal/db"
 	"github.com/osbuild/image-builder/internal/distribution"
+	"github.com/osbuild/image-builder/internal/gpgkey"
+	"github.com/osbuild/image-builder/internal/ostree"
 	"github.com/osbuild/image-builder/internal/prometheus"
 	"github.com/osbuild/image-builder/internal/provisioning"
+	"github.com/osbuild/image-builder/internal/secrets"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/routers"
@@ -26,7 +31,7 @@ import (
 
 type Server struct {
 	echo       *echo.Echo
-	cClient    *composer.ComposerClient
+	composers  *composer.Backends
 	pClient    *provisioning.ProvisioningClient
 	spec       *openapi3.T
 	router     routers.Router
@@ -36,11 +41,17 @@ type Server struct {
 	quotaFile  string
 	allowList  common.AllowList
 	allDistros *distribution.AllDistroRegistry
+
+	restoreGracePeriod time.Duration
+	ostreeRemote       *ostree.Remote
+	secrets            *secrets.Cipher
+	composeRedactor    *redactor
+	gpgKeys            *gpgkey.Fetcher
 }
 
 type ServerConfig struct {
 	EchoServer *echo.Echo
-	CompClient *composer.ComposerClient
+	Composers  *composer.Backends
 	ProvClient *provisioning.ProvisioningClient
 	DBase      db.DB
 	AwsConfig  AWSConfig
@@ -48,15 +59,33 @@ type ServerConfig struct {
 	QuotaFile  string
 	AllowFile  string
 	AllDistros *distribution.AllDistroRegistry
+
+	// How long after deletion a compose can still be restored
+	RestoreGracePeriod time.Duration
+	// When set, the parents of OSTree commits are looked up in their
+	// repository before submitting the compose
+	OSTreeRemote *ostree.Remote
+	// Encrypts the activation keys of subscription profiles, the profiles
+	// are unavailable without it
+	Secrets *secrets.Cipher
+	// Fetches the gpg keys repositories refer to by url, defaults to a
+	// fetcher which only reaches public addresses
+	GPGKeyFetcher *gpgkey.Fetcher
 }
 
 type AWSConfig struct {
+	// Default region of uploads
 	Region string
+	// Regions users can choose to upload to instead
+	AllowedRegions []string
 }
 
 type GCPConfig struct {
+	// Default region of uploads
 	Region string
 	Bucket string
+	// Regions users can choose to upload to instead
+	AllowedRegions []string
 }
 
 type Handlers struct {
@@ -83,9 +112,19 @@ func Attach(conf *ServerConfig) error {
 		return err
 	}
 
+	composeRedactor, err := newRedactor(spec, "ComposeRequest", sensitivePaths)
+	if err != nil {
+		return err
+	}
+
+	gpgKeys := conf.GPGKeyFetcher
+	if gpgKeys == nil {
+		gpgKeys = gpgkey.NewFetcher(nil)
+	}
+
 	s := Server{
 		conf.EchoServer,
-		conf.CompClient,
+		conf.Composers,
 		conf.ProvClient,
 		spec,
 		router,
@@ -95,6 +134,11 @@ func Attach(conf *ServerConfig) error {
 		conf.QuotaFile,
 		allowList,
 		conf.AllDistros,
+		conf.RestoreGracePeriod,
+		conf.OSTreeRemote,
+		conf.Secrets,
+		composeRedactor,
+		gpgKeys,
 	}
 	var h Handlers
 	h.server = &s
@@ -171,20 +215,7 @@ func (b binder) Bind(i interface{}, ctx echo.Context) error {
 }
 
 func (s *Server) HTTPErrorHandler(err error, c echo.Context) {
-	var errors []HTTPError
-	he, ok := err.(*echo.HTTPError)
-	if ok {
-		if he.Internal != nil {
-			if herr, ok := he.Internal.(*echo.HTTPError); ok {
-				he = herr
-			}
-		}
-	} else {
-		he = &echo.HTTPError{
-			Code:    http.StatusInternalServerError,
-			Message: http.StatusText(http.StatusInternalServerError),
-		}
-	}
+	he := toHTTPError(err)
 
 	internalError := he.Code >= http.StatusInternalServerError && he.Code <= http.StatusNetworkAuthenticationRequired
 	if internalError {
@@ -195,10 +226,7 @@ func (s *Server) HTTPErrorHandler(err error, c echo.Context) {
 		}
 	}
 
-	errors = append(errors, HTTPError{
-		Title:  strconv.Itoa(he.Code),
-		Detail: fmt.Sprintf("%v", he.Message),
-	})
+	errors := []HTTPError{httpErrorBody(he)}
 
 	// Send response
 	if !c.Response().Committed {
@@ -215,6 +243,40 @@ func (s *Server) HTTPErrorHandler(err error, c echo.Context) {
 	}
 }
 
+// toHTTPError returns the response to an error returned by a handler
+func toHTTPError(err error) *echo.HTTPError {
+	he, ok := err.(*echo.HTTPError)
+	if ok {
+		if he.Internal != nil {
+			if herr, ok := he.Internal.(*echo.HTTPError); ok {
+				he = herr
+			}
+		}
+	} else if goerrors.Is(err, composer.ErrUnavailable) {
+		he = errServiceUnavailable.httpError(nil)
+	} else {
+		he = &echo.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: http.StatusText(http.StatusInternalServerError),
+		}
+	}
+	return he
+}
+
+// httpErrorBody is the error as listed in the body of error responses
+func httpErrorBody(he *echo.HTTPError) HTTPError {
+	httpError := HTTPError{
+		Title:  strconv.Itoa(he.Code),
+		Detail: fmt.Sprintf("%v", he.Message),
+	}
+	if ec, ok := he.Message.(errorCode); ok {
+		httpError.Detail = ec.Message
+		httpError.Code = common.StringToPtr(ec.Code)
+		httpError.Remediation = common.StringToPtr(ec.Remediation)
+	}
+	return httpError
+}
+
 func (s *Server) distroRegistry(ctx echo.Context) *distribution.DistroRegistry {
 	return s.allDistros.Available(s.isEntitled(ctx))
 }
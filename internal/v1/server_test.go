@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,8 +23,11 @@ import (
 	"github.com/osbuild/image-builder/internal/composer"
 	"github.com/osbuild/image-builder/internal/db"
 	"github.com/osbuild/image-builder/internal/distribution"
+	"github.com/osbuild/image-builder/internal/gpgkey"
 	"github.com/osbuild/image-builder/internal/logger"
+	"github.com/osbuild/image-builder/internal/ostree"
 	"github.com/osbuild/image-builder/internal/provisioning"
+	"github.com/osbuild/image-builder/internal/secrets"
 	"github.com/osbuild/image-builder/internal/tutils"
 
 	"github.com/labstack/echo/v4"
@@ -28,6 +35,44 @@ import (
 
 var dbc *tutils.PSQLContainer
 
+const (
+	testSSHKey       = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAABAgMEBQYHCAkKCwwNDg8QERITFBUWFxgZGhscHR4f user@example.com"
+	testSSHKey2      = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAABQDDTxEF"
+	testPasswordHash = "$6$zXS.nNeVFXliKkl9$6sWGvjnMUwPXZzXwhMnutklMyuj7iGpvq72HZ98FtbazGodPKRYSblOmEPpCuUeWEtFrRVVaQ6Tej4aP35u4A."
+)
+
+// testGPGKey is the Fedora 35 key, as published with the repositories
+const testGPGKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mQINBGAcScoBEADLf8YHkezJ6adlMYw7aGGIlJalt8Jj2x/B2K+hIfIuxGtpVj7e
+LRgDU76jaT5pVD5mFMJ3pkeneR/cTmqqQkNyQshX2oQXwEzUSb1CNMCfCGgkX8Q2
+zZkrIcCrF0Q2wrKblaudhU+iVanADsm18YEqsb5AU37dtUrM3QYdWg9R+XiPfV8R
+KBjT03vVBOdMSsY39LaCn6Ip1Ovp8IEo/IeEVY1qmCOPAaK0bJH3ufg4Cueks+TS
+wQWTeCLxuZL6OMXoOPKwvMQfxbg1XD8vuZ0Ktj/cNH2xau0xmsAu9HJpekvOPRxl
+yqtjyZfroVieFypwZgvQwtnnM8/gSEu/JVTrY052mEUT7Ccb74kcHFTFfMklnkG/
+0fU4ARa504H3xj0ktbe3vKcPXoPOuKBVsHSv00UGYAyPeuy+87cU/YEhM7k3SVKj
+6eIZgyiMO0wl1YGDRKculwks9A+ulkg1oTb4s3zmZvP07GoTxW42jaK5WS+NhZee
+860XoVhbc1KpS+jfZojsrEtZ8PbUZ+YvF8RprdWArjHbJk2JpRKAxThxsQAsBhG1
+0Lux2WaMB0g2I5PcMdJ/cqjo08ccrjBXuixWri5iu9MXp8qT/fSzNmsdIgn8/qZK
+i8Qulfu77uqhW/wt2btnitgRsqjhxMujYU4Zb4hktF8hKU/XX742qhL5KwARAQAB
+tDFGZWRvcmEgKDM1KSA8ZmVkb3JhLTM1LXByaW1hcnlAZmVkb3JhcHJvamVjdC5v
+cmc+iQJOBBMBCAA4FiEEeH6mrhFH7uVsQLMM20Y5cZhnxY8FAmAcScoCGw8FCwkI
+BwIGFQoJCAsCBBYCAwECHgECF4AACgkQ20Y5cZhnxY+NYA/7BYpglySAZYHhjyKh
+/+f6zPfVvbH20Eq3kI7OFBN0nLX+BU1muvS+qTuS3WLrB3m3GultpKREJKLtm5ED
+1rGzXAoT1yp9YI8LADdMCCOyjAjsoWU87YUuC+/bnjrTeR2LROCfyPC76W985iOV
+m5S+bsQDw7C2LrldAM4MDuoyZ1SitGaZ4KQLVt+TEa14isYSGCjzo7PY8V3JOk50
+gqWg82N/bm2EzS7T83WEDb1lvj4IlvxgIqKeg11zXYxmrYSZJJCfvzf+lNS6uxgH
+jx/J0ylZ2LibGr6GAAyO9UWrAZSwSM0EcjT8wECnxkSDuyqmWwVvNBXuEIV8Oe3Y
+MiU1fJN8sd7DpsFx5M+XdnMnQS+HrjTPKD3mWrlAdnEThdYV8jZkpWhDys3/99eO
+hk0rLny0jNwkauf/iU8Oc6XvMkjLRMJg5U9VKyJuWWtzwXnjMN5WRFBqK4sZomMM
+ftbTH1+5ybRW/A3vBbaxRW2t7UzNjczekSZEiaLN9L/HcJCIR1QF8682DdAlEF9d
+k2gQiYSQAaaJ0JJAzHvRkRJLLgK2YQYiHNVy2t3JyFfsram5wSCWOfhPeIyLBTZJ
+vrpNlPbefsT957Tf2BNIugzZrC5VxDSKkZgRh1VGvSIQnCyzkQy6EU2qPpiW59G/
+hPIXZrKocK3KLS9/izJQTRltjMA=
+=PfT7
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
 func TestMain(m *testing.M) {
 	code := runTests(m)
 	os.Exit(code)
@@ -96,6 +141,11 @@ func makeUploadOptions(t *testing.T, uploadOptions interface{}) *composer.Upload
 }
 
 func startServerWithCustomDB(t *testing.T, url, provURL string, dbase db.DB, distsDir string, allowFile string) (*echo.Echo, *httptest.Server) {
+	return startServerWithBackends(t, map[string]string{composer.DefaultBackend: url}, nil, provURL, dbase, distsDir, allowFile)
+}
+
+// backends maps the names of the composer backends to their urls
+func startServerWithBackends(t *testing.T, backends map[string]string, routes []composer.Route, provURL string, dbase db.DB, distsDir string, allowFile string) (*echo.Echo, *httptest.Server) {
 	var log = &logrus.Logger{
 		Out:       os.Stderr,
 		Formatter: new(logrus.TextFormatter),
@@ -120,12 +170,17 @@ func startServerWithCustomDB(t *testing.T, url, provURL string, dbase db.DB, dis
 		require.NoError(t, err)
 	}))
 
-	compClient, err := composer.NewClient(composer.ComposerClientConfig{
-		ComposerURL:  url,
-		TokenURL:     tokenServer.URL,
-		ClientId:     "rhsm-api",
-		OfflineToken: "offlinetoken",
-	})
+	compClients := map[string]*composer.ComposerClient{}
+	for name, url := range backends {
+		compClients[name], err = composer.NewClient(composer.ComposerClientConfig{
+			ComposerURL:  url,
+			TokenURL:     tokenServer.URL,
+			ClientId:     "rhsm-api",
+			OfflineToken: "offlinetoken",
+		})
+		require.NoError(t, err)
+	}
+	composers, err := composer.NewBackends(compClients, routes)
 	require.NoError(t, err)
 
 	provClient, err := provisioning.NewClient(provisioning.ProvisioningClientConfig{
@@ -140,16 +195,30 @@ func startServerWithCustomDB(t *testing.T, url, provURL string, dbase db.DB, dis
 	adr, err := distribution.LoadDistroRegistry(distsDir)
 	require.NoError(t, err)
 
+	secretsCipher, err := secrets.NewCipher(make([]byte, secrets.KeySize))
+	require.NoError(t, err)
+
 	echoServer := echo.New()
 	echoServer.HideBanner = true
 	serverConfig := &ServerConfig{
 		EchoServer: echoServer,
-		CompClient: compClient,
+		Composers:  composers,
 		ProvClient: provClient,
 		DBase:      dbase,
 		QuotaFile:  quotaFile,
 		AllowFile:  allowFile,
 		AllDistros: adr,
+		AwsConfig: AWSConfig{
+			AllowedRegions: []string{"eu-central-1"},
+		},
+		GcpConfig: GCPConfig{
+			AllowedRegions: []string{"europe-west3"},
+		},
+
+		RestoreGracePeriod: time.Hour,
+		Secrets:            secretsCipher,
+		// test repositories serve their keys on loopback
+		GPGKeyFetcher: gpgkey.NewFetcher(http.DefaultClient),
 	}
 
 	err = Attach(serverConfig)
@@ -279,6 +348,34 @@ func TestWithoutOsbuildComposerBackend(t *testing.T) {
 			}}, result)
 	})
 
+	t.Run("GetOscapProfiles", func(t *testing.T) {
+		respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/oscap/rhel-8/profiles", &tutils.AuthString0)
+		require.Equal(t, 200, respStatusCode)
+
+		var result OscapProfiles
+		err := json.Unmarshal([]byte(body), &result)
+		require.NoError(t, err)
+		require.NotEmpty(t, result)
+		var stig *OscapProfile
+		for i := range result {
+			if result[i].ProfileId == "xccdf_org.ssgproject.content_profile_stig" {
+				stig = &result[i]
+			}
+		}
+		require.NotNil(t, stig)
+		require.Equal(t, "DISA STIG for Red Hat Enterprise Linux 8", stig.Name)
+		require.Contains(t, stig.Customizations.Packages, "aide")
+		require.Contains(t, stig.Customizations.Services.Enabled, "auditd")
+
+		// no SCAP security guide for fedora
+		respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/oscap/fedora-38/profiles", &tutils.AuthString0)
+		require.Equal(t, 200, respStatusCode)
+		require.Equal(t, "[]", strings.TrimSpace(body))
+
+		respStatusCode, _ = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/oscap/none/profiles", &tutils.AuthString0)
+		require.Equal(t, 400, respStatusCode)
+	})
+
 	t.Run("GetPackages", func(t *testing.T) {
 		architectures := []string{"x86_64", "aarch64"}
 		for _, arch := range architectures {
@@ -322,6 +419,26 @@ func TestWithoutOsbuildComposerBackend(t *testing.T) {
 			require.NoError(t, err)
 			require.Greater(t, result.Meta.Count, 1)
 			require.Equal(t, result.Data[0], p2)
+			require.NotNil(t, result.Links.Prev)
+
+			// follow the cursors back and forth
+			respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086"+*result.Links.Prev, &tutils.AuthString0)
+			require.Equal(t, 200, respStatusCode)
+			result = PackagesResponse{}
+			err = json.Unmarshal([]byte(body), &result)
+			require.NoError(t, err)
+			require.Equal(t, []Package{p1}, result.Data)
+			require.Nil(t, result.Links.Prev)
+			require.NotNil(t, result.Links.Next)
+
+			respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086"+*result.Links.Next, &tutils.AuthString0)
+			require.Equal(t, 200, respStatusCode)
+			err = json.Unmarshal([]byte(body), &result)
+			require.NoError(t, err)
+			require.Equal(t, []Package{p2}, result.Data)
+
+			respStatusCode, _ = tutils.GetResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/packages?distribution=rhel-8&architecture=%s&search=ssh&cursor=garbage", arch), &tutils.AuthString0)
+			require.Equal(t, 400, respStatusCode)
 
 			respStatusCode, _ = tutils.GetResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/packages?distribution=rhel-8&architecture=%s&search=ssh&limit=-13", arch), &tutils.AuthString0)
 			require.Equal(t, 400, respStatusCode)
@@ -429,7 +546,7 @@ func TestGetComposeStatus(t *testing.T) {
 			"distribution": "rhel-9",
 			"image_requests": [],
 			"image_name": "myimage"
-		}`))
+		}`), "default")
 	require.NoError(t, err)
 
 	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, "", dbase, "../../distributions", "")
@@ -546,7 +663,7 @@ func TestGetComposeMetadata(t *testing.T) {
 	dbase, err := dbc.NewDB()
 	require.NoError(t, err)
 	imageName := "MyImageName"
-	err = dbase.InsertCompose(id, "500000", "000000", &imageName, json.RawMessage("{}"))
+	err = dbase.InsertCompose(id, "500000", "000000", &imageName, json.RawMessage("{}"), "default")
 	require.NoError(t, err)
 
 	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, "", dbase, "../../distributions", "")
@@ -618,11 +735,11 @@ func TestGetComposes(t *testing.T) {
 	require.Contains(t, body, "\"data\":[]")
 
 	imageName := "MyImageName"
-	err = dbase.InsertCompose(id, "500000", "000000", &imageName, json.RawMessage("{}"))
+	err = dbase.InsertCompose(id, "500000", "000000", &imageName, json.RawMessage("{}"), "default")
 	require.NoError(t, err)
-	err = dbase.InsertCompose(id2, "500000", "000000", &imageName, json.RawMessage("{}"))
+	err = dbase.InsertCompose(id2, "500000", "000000", &imageName, json.RawMessage("{}"), "default")
 	require.NoError(t, err)
-	err = dbase.InsertCompose(id3, "500000", "000000", &imageName, json.RawMessage("{}"))
+	err = dbase.InsertCompose(id3, "500000", "000000", &imageName, json.RawMessage("{}"), "default")
 	require.NoError(t, err)
 
 	composeEntry, err := dbase.GetCompose(id, "000000")
@@ -637,6 +754,133 @@ func TestGetComposes(t *testing.T) {
 	require.Equal(t, composeEntry.Id, result.Data[2].Id)
 }
 
+func TestGetComposesCursor(t *testing.T) {
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+
+	db_srv, tokenSrv := startServerWithCustomDB(t, "", "", dbase, "../../distributions", "")
+	defer func() {
+		err := db_srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	imageName := "MyImageName"
+	for i := 0; i < 3; i++ {
+		err = dbase.InsertCompose(uuid.New(), "500000", "000000", &imageName, json.RawMessage("{}"), "default")
+		require.NoError(t, err)
+	}
+
+	var first ComposesResponse
+	respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?limit=2", &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &first)
+	require.NoError(t, err)
+	require.Equal(t, 3, first.Meta.Count)
+	require.Len(t, first.Data, 2)
+	require.Nil(t, first.Links.Prev)
+	require.NotNil(t, first.Links.Next)
+	require.Equal(t, "/api/image-builder/v1.0/composes?offset=2&limit=2", first.Links.Last)
+
+	// a compose created in the meantime doesn't shift the pages
+	err = dbase.InsertCompose(uuid.New(), "500000", "000000", &imageName, json.RawMessage("{}"), "default")
+	require.NoError(t, err)
+
+	var second ComposesResponse
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086"+*first.Links.Next, &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &second)
+	require.NoError(t, err)
+	require.Equal(t, 4, second.Meta.Count)
+	require.Len(t, second.Data, 1)
+	require.NotEqual(t, first.Data[0].Id, second.Data[0].Id)
+	require.NotEqual(t, first.Data[1].Id, second.Data[0].Id)
+	require.Nil(t, second.Links.Next)
+	require.NotNil(t, second.Links.Prev)
+
+	var prev ComposesResponse
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086"+*second.Links.Prev, &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &prev)
+	require.NoError(t, err)
+	require.Equal(t, first.Data, prev.Data)
+	require.NotNil(t, prev.Links.Prev)
+
+	// a cursor is only valid for the sort order it was made for
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086"+*first.Links.Next+"&sort=created_at_asc", &tutils.AuthString0)
+	require.Equal(t, 400, respStatusCode)
+	require.Contains(t, body, "Cursor doesn't match the sort order")
+}
+
+func TestGetComposesFilters(t *testing.T) {
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+
+	db_srv, tokenSrv := startServerWithCustomDB(t, "", "", dbase, "../../distributions", "")
+	defer func() {
+		err := db_srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	rhel8 := uuid.New()
+	rhel9 := uuid.New()
+	imageName := "MyImageName"
+	err = dbase.InsertCompose(rhel8, "500000", "000000", &imageName, json.RawMessage(`{"distribution": "rhel-8", "image_requests": [{"architecture": "x86_64", "image_type": "ami", "upload_request": {"type": "aws"}}], "labels": {"team": "edge", "environment": "production"}}`), "default")
+	require.NoError(t, err)
+	err = dbase.InsertCompose(rhel9, "500000", "000000", &imageName, json.RawMessage(`{"distribution": "rhel-9", "image_requests": [{"architecture": "x86_64", "image_type": "guest-image", "upload_request": {"type": "aws.s3"}}], "labels": {"team": "edge"}}`), "default")
+	require.NoError(t, err)
+
+	var result ComposesResponse
+	respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?distribution=rhel-9&limit=10", &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Meta.Count)
+	require.Equal(t, rhel9, result.Data[0].Id)
+	require.Equal(t, "/api/image-builder/v1.0/composes?offset=0&limit=10&distribution=rhel-9", result.Links.First)
+
+	// aws is an alias of ami
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?image_type=aws", &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Meta.Count)
+	require.Equal(t, rhel8, result.Data[0].Id)
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?sort=created_at_asc", &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Meta.Count)
+	require.Equal(t, rhel8, result.Data[0].Id)
+
+	respStatusCode, _ = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?sort=unknown", &tutils.AuthString0)
+	require.Equal(t, 400, respStatusCode)
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?since=2022-02-01T00:00:00Z&until=2022-01-01T00:00:00Z", &tutils.AuthString0)
+	require.Equal(t, 400, respStatusCode)
+	require.Contains(t, body, "since must be before until")
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?label_selector="+url.QueryEscape("team=edge,environment!=production"), &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Meta.Count)
+	require.Equal(t, rhel9, result.Data[0].Id)
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?label_selector=environment", &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Meta.Count)
+	require.Equal(t, rhel8, result.Data[0].Id)
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes?label_selector="+url.QueryEscape("team=edge,=x"), &tutils.AuthString0)
+	require.Equal(t, 400, respStatusCode)
+	require.Contains(t, body, "Invalid label selector")
+}
+
 // note: these scenarios don't needs to talk to a simulated osbuild-composer API
 func TestComposeImage(t *testing.T) {
 	// note: any url will work, it'll only try to contact the osbuild-composer
@@ -711,6 +955,33 @@ func TestComposeImage(t *testing.T) {
 		require.Contains(t, body, "Expected at least one source or account to share the image with")
 	})
 
+	t.Run("ErrorsForInvalidLabelKey", func(t *testing.T) {
+		payload := ComposeRequest{
+			Distribution: "centos-8",
+			ImageRequests: []ImageRequest{
+				{
+					Architecture: "x86_64",
+					ImageType:    ImageTypesAws,
+					UploadRequest: UploadRequest{
+						Type: UploadTypesAws,
+						Options: AWSUploadRequestOptions{
+							ShareWithAccounts: &[]string{"test-account"},
+						},
+					},
+				},
+			},
+			Labels: &Labels{
+				AdditionalProperties: map[string]string{
+					"team":     "edge",
+					"-invalid": "key",
+				},
+			},
+		}
+		respStatusCode, body := tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", payload)
+		require.Equal(t, 400, respStatusCode)
+		require.Contains(t, body, `Invalid label key \"-invalid\"`)
+	})
+
 	azureRequest := func(source_id, subscription_id, tenant_id string) ImageRequest {
 		options := make(map[string]string)
 		options["resource_group"] = "group"
@@ -809,7 +1080,7 @@ func TestComposeImage(t *testing.T) {
 	})
 
 	t.Run("ErrorUserCustomizationNotAllowed", func(t *testing.T) {
-		// User customization only permitted for installer types
+		// the users of OSTree commits are created by their installer
 		payload := ComposeRequest{
 			Customizations: &Customizations{
 				Packages: &[]string{
@@ -819,11 +1090,34 @@ func TestComposeImage(t *testing.T) {
 				Users: &[]User{
 					{
 						Name:   "user-name0",
-						SshKey: "",
+						SshKey: common.StringToPtr(testSSHKey),
+					},
+				},
+			},
+			Distribution: "centos-8",
+			ImageRequests: []ImageRequest{
+				{
+					Architecture: "x86_64",
+					ImageType:    ImageTypesEdgeCommit,
+					UploadRequest: UploadRequest{
+						Type:    UploadTypesAwsS3,
+						Options: AWSS3UploadRequestOptions{},
 					},
+				},
+			},
+		}
+		respStatusCode, body := tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", payload)
+		require.Equal(t, 400, respStatusCode)
+		require.Contains(t, body, "User and group customizations don't apply to OSTree commits")
+	})
+
+	t.Run("ErrorUserWithoutCredentials", func(t *testing.T) {
+		payload := ComposeRequest{
+			Customizations: &Customizations{
+				Users: &[]User{
 					{
-						Name:   "user-name1",
-						SshKey: "",
+						Name:   "user-name0",
+						SshKey: common.StringToPtr(""),
 					},
 				},
 			},
@@ -843,7 +1137,7 @@ func TestComposeImage(t *testing.T) {
 		}
 		respStatusCode, body := tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", payload)
 		require.Equal(t, 400, respStatusCode)
-		require.Contains(t, body, "User customization only applies to installer image types")
+		require.Contains(t, body, "User user-name0 needs an SSH key or a password to log in")
 	})
 
 	t.Run("ErrorsForUnknownUploadType", func(t *testing.T) {
@@ -1019,7 +1313,13 @@ func TestComposeImageErrorResolvingOSTree(t *testing.T) {
 	}
 	respStatusCode, body := tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", payload)
 	require.Equal(t, 400, respStatusCode)
-	require.Contains(t, body, "Error resolving OSTree repo")
+	var result HTTPErrorList
+	err := json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	require.Equal(t, errOSTreeResolution.Message, result.Errors[0].Detail)
+	require.Equal(t, "IB-OSTREE-RESOLUTION", *result.Errors[0].Code)
+	require.NotNil(t, result.Errors[0].Remediation)
 }
 
 func TestComposeImageErrorsWhenCannotParseResponse(t *testing.T) {
@@ -1115,6 +1415,83 @@ func TestComposeImageReturnsIdWhenNoErrors(t *testing.T) {
 	require.Equal(t, id, result.Id)
 }
 
+func TestComposeBackendRouting(t *testing.T) {
+	id := uuid.New()
+	// composer backends answering composes and status requests, counting
+	// the requests they get
+	createApiSrv := func(calls *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if "Bearer" == r.Header.Get("Authorization") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			atomic.AddInt32(calls, 1)
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+				err := json.NewEncoder(w).Encode(composer.ComposeId{Id: id})
+				require.NoError(t, err)
+				return
+			}
+			err := json.NewEncoder(w).Encode(composer.ComposeStatus{
+				ImageStatus: composer.ImageStatus{
+					Status: composer.ImageStatusValueBuilding,
+				},
+			})
+			require.NoError(t, err)
+		}))
+	}
+	var defaultCalls, aarch64Calls int32
+	defaultSrv := createApiSrv(&defaultCalls)
+	defer defaultSrv.Close()
+	aarch64Srv := createApiSrv(&aarch64Calls)
+	defer aarch64Srv.Close()
+
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+
+	srv, tokenSrv := startServerWithBackends(t, map[string]string{
+		composer.DefaultBackend: defaultSrv.URL,
+		"aarch64":               aarch64Srv.URL,
+	}, []composer.Route{
+		{Architecture: "aarch64", Backend: "aarch64"},
+	}, "", dbase, "../../distributions", "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	payload := ComposeRequest{
+		Distribution: "centos-8",
+		ImageRequests: []ImageRequest{
+			{
+				Architecture: "aarch64",
+				ImageType:    ImageTypesAws,
+				UploadRequest: UploadRequest{
+					Type: UploadTypesAws,
+					Options: AWSUploadRequestOptions{
+						ShareWithAccounts: &[]string{"test-account"},
+					},
+				},
+			},
+		},
+	}
+	respStatusCode, _ := tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", payload)
+	require.Equal(t, http.StatusCreated, respStatusCode)
+	require.Equal(t, int32(1), atomic.LoadInt32(&aarch64Calls))
+
+	composeEntry, err := dbase.GetCompose(id, "000000")
+	require.NoError(t, err)
+	require.Equal(t, "aarch64", composeEntry.Backend)
+
+	// the status comes from the backend owning the compose
+	respStatusCode, _ = tutils.GetResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s", id), &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	require.Equal(t, int32(2), atomic.LoadInt32(&aarch64Calls))
+	require.Equal(t, int32(0), atomic.LoadInt32(&defaultCalls))
+}
+
 func TestComposeImageAllowList(t *testing.T) {
 	distsDir := "../distribution/testdata/distributions"
 	allowFile := "../common/testdata/allow.json"
@@ -1308,7 +1685,7 @@ func TestComposeCustomizations(t *testing.T) {
 							Baseurl:      common.StringToPtr("https://some-repo-base-url.org"),
 							CheckGpg:     common.BoolToPtr(true),
 							CheckRepoGpg: common.BoolToPtr(true),
-							Gpgkey:       common.StringToPtr("some-gpg-key"),
+							Gpgkey:       common.StringToPtr(testGPGKey),
 							IgnoreSsl:    common.BoolToPtr(false),
 							Rhsm:         false,
 						},
@@ -1326,26 +1703,45 @@ func TestComposeCustomizations(t *testing.T) {
 					Users: &[]User{
 						{
 							Name:   "user",
-							SshKey: "ssh-rsa AAAAB3NzaC1",
+							SshKey: common.StringToPtr(testSSHKey),
+						},
+						{
+							Name:     "admin",
+							SshKeys:  &[]string{testSSHKey, testSSHKey2},
+							Password: common.StringToPtr(testPasswordHash),
+							Groups:   &[]string{"wheel", "admins"},
+							Home:     common.StringToPtr("/var/home/admin"),
+							Shell:    common.StringToPtr("/bin/zsh"),
+							Uid:      common.IntToPtr(1100),
+							Gid:      common.IntToPtr(1100),
+						},
+					},
+					Groups: &[]Group{
+						{
+							Name: "admins",
+							Gid:  common.IntToPtr(1200),
 						},
 					},
 					CustomRepositories: &[]CustomRepository{
 						{
 							Id:       "some-repo-id",
 							Baseurl:  &[]string{"https://some-repo-base-url.org"},
-							Gpgkey:   &[]string{"some-gpg-key"},
+							Gpgkey:   &[]string{testGPGKey},
 							CheckGpg: common.BoolToPtr(true),
 						},
 					},
 					Openscap: &OpenSCAP{
-						ProfileId: "test-profile",
+						ProfileId: "xccdf_org.ssgproject.content_profile_cis",
+						Tailoring: &OpenSCAPTailoring{
+							Unselected: &[]string{"xccdf_org.ssgproject.content_rule_package_cups_removed"},
+						},
 					},
 				},
 				Distribution: "centos-8",
 				ImageRequests: []ImageRequest{
 					{
 						Architecture: "x86_64",
-						ImageType:    ImageTypesRhelEdgeInstaller,
+						ImageType:    ImageTypesGuestImage,
 						UploadRequest: UploadRequest{
 							Type:    UploadTypesAwsS3,
 							Options: AWSS3UploadRequestOptions{},
@@ -1359,13 +1755,21 @@ func TestComposeCustomizations(t *testing.T) {
 					Packages: &[]string{
 						"some",
 						"packages",
+						// the packages of the OpenSCAP profile
+						"aide",
+						"audit",
+						"chrony",
+						"firewalld",
+						"libselinux",
+						"rsyslog",
+						"sudo",
 					},
 					PayloadRepositories: &[]composer.Repository{
 						{
 							Baseurl:      common.StringToPtr("https://some-repo-base-url.org"),
 							CheckGpg:     common.BoolToPtr(true),
 							CheckRepoGpg: common.BoolToPtr(true),
-							Gpgkey:       common.StringToPtr("some-gpg-key"),
+							Gpgkey:       common.StringToPtr(testGPGKey),
 							IgnoreSsl:    common.BoolToPtr(false),
 							Rhsm:         common.BoolToPtr(false),
 						},
@@ -1383,25 +1787,48 @@ func TestComposeCustomizations(t *testing.T) {
 					Users: &[]composer.User{
 						{
 							Name:   "user",
-							Key:    common.StringToPtr("ssh-rsa AAAAB3NzaC1"),
+							Key:    common.StringToPtr(testSSHKey),
 							Groups: &[]string{"wheel"},
 						},
+						{
+							Name:     "admin",
+							Key:      common.StringToPtr(testSSHKey + "\n" + testSSHKey2),
+							Password: common.StringToPtr(testPasswordHash),
+							Groups:   &[]string{"wheel", "admins"},
+							Home:     common.StringToPtr("/var/home/admin"),
+							Shell:    common.StringToPtr("/bin/zsh"),
+							Uid:      common.IntToPtr(1100),
+							Gid:      common.IntToPtr(1100),
+						},
+					},
+					Groups: &[]composer.Group{
+						{
+							Name: "admins",
+							Gid:  common.IntToPtr(1200),
+						},
 					},
 					CustomRepositories: &[]composer.CustomRepository{
 						{
 							Id:       "some-repo-id",
 							Baseurl:  &[]string{"https://some-repo-base-url.org"},
-							Gpgkey:   &[]string{"some-gpg-key"},
+							Gpgkey:   &[]string{testGPGKey},
 							CheckGpg: common.BoolToPtr(true),
 						},
 					},
 					Openscap: &composer.OpenSCAP{
-						ProfileId: "test-profile",
+						ProfileId: "xccdf_org.ssgproject.content_profile_cis",
+						Tailoring: &composer.OpenSCAPTailoring{
+							Unselected: &[]string{"xccdf_org.ssgproject.content_rule_package_cups_removed"},
+						},
+					},
+					Services: &composer.Services{
+						Enabled:  &[]string{"auditd", "chronyd", "crond", "firewalld", "rsyslog"},
+						Disabled: &[]string{"avahi-daemon", "cups", "rpcbind"},
 					},
 				},
 				ImageRequest: &composer.ImageRequest{
 					Architecture: "x86_64",
-					ImageType:    composer.ImageTypesEdgeInstaller,
+					ImageType:    composer.ImageTypesGuestImage,
 					Ostree:       nil,
 					Repositories: []composer.Repository{
 
@@ -1600,11 +2027,6 @@ func TestComposeCustomizations(t *testing.T) {
 					{
 						Architecture: "x86_64",
 						ImageType:    ImageTypesAzure,
-						Ostree: &OSTree{
-							Ref:    strptr("test/edge/ref"),
-							Url:    strptr("https://ostree.srv/"),
-							Parent: strptr("test/edge/ref2"),
-						},
 						UploadRequest: UploadRequest{
 							Type: UploadTypesAzure,
 							Options: AzureUploadRequestOptions{
@@ -1623,11 +2045,6 @@ func TestComposeCustomizations(t *testing.T) {
 				ImageRequest: &composer.ImageRequest{
 					Architecture: "x86_64",
 					ImageType:    composer.ImageTypesAzure,
-					Ostree: &composer.OSTree{
-						Ref:    strptr("test/edge/ref"),
-						Url:    strptr("https://ostree.srv/"),
-						Parent: strptr("test/edge/ref2"),
-					},
 					Repositories: []composer.Repository{
 
 						{
@@ -1678,11 +2095,6 @@ func TestComposeCustomizations(t *testing.T) {
 					{
 						Architecture: "x86_64",
 						ImageType:    ImageTypesAzure,
-						Ostree: &OSTree{
-							Ref:    strptr("test/edge/ref"),
-							Url:    strptr("https://ostree.srv/"),
-							Parent: strptr("test/edge/ref2"),
-						},
 						UploadRequest: UploadRequest{
 							Type: UploadTypesAzure,
 							Options: AzureUploadRequestOptions{
@@ -1700,11 +2112,6 @@ func TestComposeCustomizations(t *testing.T) {
 				ImageRequest: &composer.ImageRequest{
 					Architecture: "x86_64",
 					ImageType:    composer.ImageTypesAzure,
-					Ostree: &composer.OSTree{
-						Ref:    strptr("test/edge/ref"),
-						Url:    strptr("https://ostree.srv/"),
-						Parent: strptr("test/edge/ref2"),
-					},
 					Repositories: []composer.Repository{
 
 						{
@@ -1826,44 +2233,22 @@ func TestComposeCustomizations(t *testing.T) {
 	}
 }
 
-// TestBuildOSTreeOptions checks if the buildOSTreeOptions utility function
-// properly transfers the ostree options to the Composer structure.
-func TestBuildOSTreeOptions(t *testing.T) {
-	cases := map[ImageRequest]*composer.OSTree{
-		{Ostree: nil}: nil,
-		{Ostree: &OSTree{Ref: strptr("someref")}}:                                     {Ref: strptr("someref")},
-		{Ostree: &OSTree{Ref: strptr("someref"), Url: strptr("https://example.org")}}: {Ref: strptr("someref"), Url: strptr("https://example.org")},
-		{Ostree: &OSTree{Url: strptr("https://example.org")}}:                         {Url: strptr("https://example.org")},
-	}
-
-	for in, expOut := range cases {
-		require.Equal(t, expOut, buildOSTreeOptions(in.Ostree), "input: %#v", in)
-	}
-}
-
-func TestReadinessProbeNotReady(t *testing.T) {
-	srv, tokenSrv := startServer(t, "", "")
-	defer func() {
-		err := srv.Shutdown(context.Background())
-		require.NoError(t, err)
-	}()
-	defer tokenSrv.Close()
-
-	respStatusCode, _ := tutils.GetResponseBody(t, "http://localhost:8086/ready", &tutils.AuthString0)
-	require.NotEqual(t, 200, respStatusCode)
-	require.NotEqual(t, 404, respStatusCode)
-}
-
-func TestReadinessProbeReady(t *testing.T) {
+func TestUploadOptions(t *testing.T) {
+	var composerRequest composer.ComposeRequest
 	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if "Bearer" == r.Header.Get("Authorization") {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
-		require.Equal(t, "Bearer accesstoken", r.Header.Get("Authorization"))
+		err := json.NewDecoder(r.Body).Decode(&composerRequest)
+		require.NoError(t, err)
+
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprint(w, "{\"version\":\"fake\"}")
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(composer.ComposeId{
+			Id: uuid.New(),
+		})
+		require.NoError(t, err)
 	}))
 	defer apiSrv.Close()
 
@@ -1874,14 +2259,1296 @@ func TestReadinessProbeReady(t *testing.T) {
 	}()
 	defer tokenSrv.Close()
 
-	respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/ready", &tutils.AuthString0)
-	require.Equal(t, 200, respStatusCode)
-	require.Contains(t, body, "{\"readiness\":\"ready\"}")
-}
-
-func TestMetrics(t *testing.T) {
-	srv, tokenSrv := startServer(t, "", "")
-	defer func() {
+	compose := func(imageType ImageTypes, uploadRequest UploadRequest) (int, string) {
+		composerRequest = composer.ComposeRequest{}
+		return tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", ComposeRequest{
+			Distribution: "centos-8",
+			ImageRequests: []ImageRequest{
+				{
+					Architecture:  "x86_64",
+					ImageType:     imageType,
+					UploadRequest: uploadRequest,
+				},
+			},
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		tests := []struct {
+			imageType     ImageTypes
+			uploadRequest UploadRequest
+			uploadOptions interface{}
+		}{
+			{
+				ImageTypesAws,
+				UploadRequest{
+					Type: UploadTypesAws,
+					Options: AWSUploadRequestOptions{
+						ShareWithAccounts: &[]string{"123456789012"},
+						SnapshotName:      common.StringToPtr("my-snapshot"),
+					},
+				},
+				composer.AWSEC2UploadOptions{
+					ShareWithAccounts: []string{"123456789012"},
+					SnapshotName:      common.StringToPtr("my-snapshot"),
+				},
+			},
+			{
+				ImageTypesAws,
+				UploadRequest{
+					Type: UploadTypesAws,
+					Options: AWSUploadRequestOptions{
+						ShareWithAccounts: &[]string{"123456789012"},
+						Region:            common.StringToPtr("eu-central-1"),
+					},
+				},
+				composer.AWSEC2UploadOptions{
+					Region:            "eu-central-1",
+					ShareWithAccounts: []string{"123456789012"},
+				},
+			},
+			{
+				ImageTypesGuestImage,
+				UploadRequest{
+					Type: UploadTypesAwsS3,
+					Options: AWSS3UploadRequestOptions{
+						Public: common.BoolToPtr(true),
+					},
+				},
+				composer.AWSS3UploadOptions{
+					Public: common.BoolToPtr(true),
+				},
+			},
+			{
+				ImageTypesGcp,
+				UploadRequest{
+					Type: UploadTypesGcp,
+					Options: GCPUploadRequestOptions{
+						ShareWithAccounts: []string{"user:alice@example.com"},
+						ImageName:         common.StringToPtr("my-image-1"),
+					},
+				},
+				composer.GCPUploadOptions{
+					Bucket:            common.StringToPtr(""),
+					ShareWithAccounts: &[]string{"user:alice@example.com"},
+					ImageName:         common.StringToPtr("my-image-1"),
+				},
+			},
+			{
+				ImageTypesGcp,
+				UploadRequest{
+					Type: UploadTypesGcp,
+					Options: GCPUploadRequestOptions{
+						ShareWithAccounts: []string{"user:alice@example.com"},
+						Region:            common.StringToPtr("europe-west3"),
+					},
+				},
+				composer.GCPUploadOptions{
+					Bucket:            common.StringToPtr(""),
+					Region:            "europe-west3",
+					ShareWithAccounts: &[]string{"user:alice@example.com"},
+				},
+			},
+			{
+				ImageTypesAzure,
+				UploadRequest{
+					Type: UploadTypesAzure,
+					Options: AzureUploadRequestOptions{
+						TenantId:       common.StringToPtr("tenant"),
+						SubscriptionId: common.StringToPtr("id"),
+						ResourceGroup:  "group",
+						Location:       common.StringToPtr("westeurope"),
+					},
+				},
+				composer.AzureUploadOptions{
+					TenantId:       "tenant",
+					SubscriptionId: "id",
+					ResourceGroup:  "group",
+					Location:       common.StringToPtr("westeurope"),
+				},
+			},
+		}
+		for _, tc := range tests {
+			respStatusCode, body := compose(tc.imageType, tc.uploadRequest)
+			require.Equal(t, http.StatusCreated, respStatusCode, body)
+			require.Equal(t, makeUploadOptions(t, tc.uploadOptions), composerRequest.ImageRequest.UploadOptions)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			imageType     ImageTypes
+			uploadRequest UploadRequest
+			err           string
+		}{
+			{
+				ImageTypesAws,
+				UploadRequest{
+					Type: UploadTypesAws,
+					Options: AWSUploadRequestOptions{
+						ShareWithAccounts: &[]string{"123456789012"},
+						SnapshotName:      common.StringToPtr(""),
+					},
+				},
+				`Invalid AWSUploadRequestOptions at \"snapshot_name\"`,
+			},
+			{
+				ImageTypesGcp,
+				UploadRequest{
+					Type: UploadTypesGcp,
+					Options: GCPUploadRequestOptions{
+						ShareWithAccounts: []string{"user:alice@example.com"},
+						ImageName:         common.StringToPtr("My_Image"),
+					},
+				},
+				`Invalid GCPUploadRequestOptions at \"image_name\"`,
+			},
+			{
+				ImageTypesAws,
+				UploadRequest{
+					Type: UploadTypesAws,
+					Options: AWSUploadRequestOptions{
+						ShareWithAccounts: &[]string{"123456789012"},
+						Region:            common.StringToPtr("ap-south-1"),
+					},
+				},
+				`Uploading to region \"ap-south-1\" is not allowed`,
+			},
+			{
+				ImageTypesGcp,
+				UploadRequest{
+					Type: UploadTypesGcp,
+					Options: GCPUploadRequestOptions{
+						ShareWithAccounts: []string{"user:alice@example.com"},
+						Region:            common.StringToPtr("eu-central-1"),
+					},
+				},
+				`Uploading to region \"eu-central-1\" is not allowed`,
+			},
+			{
+				ImageTypesGcp,
+				UploadRequest{
+					Type:    UploadTypesGcp,
+					Options: AWSS3UploadRequestOptions{},
+				},
+				`Invalid GCPUploadRequestOptions`,
+			},
+			{
+				ImageTypesAzure,
+				UploadRequest{
+					Type: UploadTypesAzure,
+					Options: AzureUploadRequestOptions{
+						TenantId:       common.StringToPtr("tenant"),
+						SubscriptionId: common.StringToPtr("id"),
+						ResourceGroup:  "group",
+						Location:       common.StringToPtr("West Europe"),
+					},
+				},
+				`Invalid AzureUploadRequestOptions at \"location\"`,
+			},
+		}
+		for _, tc := range tests {
+			respStatusCode, body := compose(tc.imageType, tc.uploadRequest)
+			require.Equal(t, http.StatusBadRequest, respStatusCode, body)
+			require.Contains(t, body, tc.err)
+			require.Nil(t, composerRequest.ImageRequest)
+		}
+	})
+}
+
+func TestUploadRegion(t *testing.T) {
+	allowed := []string{"eu-central-1", "us-west-2"}
+
+	region, err := uploadRegion(nil, "us-east-1", allowed)
+	require.NoError(t, err)
+	require.Equal(t, "us-east-1", region)
+
+	// the default region doesn't need to be listed
+	region, err = uploadRegion(common.StringToPtr("us-east-1"), "us-east-1", allowed)
+	require.NoError(t, err)
+	require.Equal(t, "us-east-1", region)
+
+	region, err = uploadRegion(common.StringToPtr("us-west-2"), "us-east-1", allowed)
+	require.NoError(t, err)
+	require.Equal(t, "us-west-2", region)
+
+	_, err = uploadRegion(common.StringToPtr("ap-south-1"), "us-east-1", allowed)
+	require.Error(t, err)
+	_, err = uploadRegion(common.StringToPtr("us-west-2"), "us-east-1", nil)
+	require.Error(t, err)
+}
+
+func TestValidateSSHKey(t *testing.T) {
+	require.NoError(t, validateSSHKey(testSSHKey))
+	require.NoError(t, validateSSHKey(testSSHKey2))
+
+	invalid := []string{
+		"",
+		"ssh-ed25519",
+		"ssh-rsa AAAAB3NzaC1",
+		"ssh-dss AAAAB3NzaC1kc3MAAAA=",
+		// an ed25519 key claiming to be an rsa one
+		"ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIAABAgMEBQYHCAkKCwwNDg8QERITFBUWFxgZGhscHR4f",
+		"AAAAB3NzaC1yc2EAAAADAQABAAAABQDDTxEF",
+		testSSHKey + "\n" + testSSHKey2,
+		"AAAA",
+	}
+	for _, key := range invalid {
+		require.Error(t, validateSSHKey(key), key)
+	}
+}
+
+func TestValidateUsers(t *testing.T) {
+	valid := Customizations{
+		Users: &[]User{
+			{Name: "user1", SshKeys: &[]string{testSSHKey}},
+			{Name: "user2", Password: common.StringToPtr(testPasswordHash), Uid: common.IntToPtr(1001)},
+		},
+		Groups: &[]Group{
+			{Name: "group1", Gid: common.IntToPtr(1001)},
+		},
+	}
+	require.NoError(t, validateUsers(&valid, ImageTypesGuestImage))
+	require.NoError(t, validateUsers(&valid, ImageTypesEdgeInstaller))
+	require.Error(t, validateUsers(&valid, ImageTypesRhelEdgeCommit))
+
+	invalid := []Customizations{
+		{Users: &[]User{{Name: "user1", Password: common.StringToPtr("hunter2")}}},
+		{Users: &[]User{{Name: "user1"}}},
+		{Users: &[]User{{Name: "user1", SshKeys: &[]string{"ssh-rsa invalid"}}}},
+		{Users: &[]User{
+			{Name: "user1", SshKey: common.StringToPtr(testSSHKey)},
+			{Name: "user1", SshKey: common.StringToPtr(testSSHKey)},
+		}},
+		{Users: &[]User{
+			{Name: "user1", SshKey: common.StringToPtr(testSSHKey), Uid: common.IntToPtr(1001)},
+			{Name: "user2", SshKey: common.StringToPtr(testSSHKey), Uid: common.IntToPtr(1001)},
+		}},
+		{Groups: &[]Group{{Name: "group1"}, {Name: "group1"}}},
+		{Groups: &[]Group{{Name: "group1", Gid: common.IntToPtr(1001)}, {Name: "group2", Gid: common.IntToPtr(1001)}}},
+	}
+	for _, cust := range invalid {
+		cust := cust
+		require.Error(t, validateUsers(&cust, ImageTypesAws))
+	}
+}
+
+func TestValidateOSCustomizations(t *testing.T) {
+	valid := Customizations{
+		Timezone: &Timezone{
+			Timezone:   common.StringToPtr("Europe/Prague"),
+			Ntpservers: &[]string{"0.pool.ntp.org", "192.168.1.1", "2001:db8::1"},
+		},
+		Firewall: &FirewallCustomization{
+			Ports: &[]string{"22:tcp", "60000-60010:udp", "65535:sctp"},
+			Services: &FirewallServices{
+				Enabled:  &[]string{"ssh"},
+				Disabled: &[]string{"cockpit"},
+			},
+		},
+	}
+	require.NoError(t, validateOSCustomizations(&valid))
+
+	invalid := []Customizations{
+		{Timezone: &Timezone{Timezone: common.StringToPtr("Europe/Atlantis")}},
+		{Timezone: &Timezone{Timezone: common.StringToPtr("Local")}},
+		{Timezone: &Timezone{Timezone: common.StringToPtr("")}},
+		{Timezone: &Timezone{Ntpservers: &[]string{"-ntp.example.com"}}},
+		{Firewall: &FirewallCustomization{Ports: &[]string{"0:tcp"}}},
+		{Firewall: &FirewallCustomization{Ports: &[]string{"65536:tcp"}}},
+		{Firewall: &FirewallCustomization{Ports: &[]string{"2000-1000:udp"}}},
+		{Firewall: &FirewallCustomization{Services: &FirewallServices{
+			Enabled:  &[]string{"ssh", "http"},
+			Disabled: &[]string{"http"},
+		}}},
+	}
+	for _, cust := range invalid {
+		cust := cust
+		require.Error(t, validateOSCustomizations(&cust))
+	}
+}
+
+func TestValidateDistroCustomizations(t *testing.T) {
+	adr, err := distribution.LoadDistroRegistry("../../distributions")
+	require.NoError(t, err)
+	rhel, err := adr.Available(true).Get("rhel-92")
+	require.NoError(t, err)
+	fedora, err := adr.Available(true).Get("fedora-38")
+	require.NoError(t, err)
+
+	fips := Customizations{Fips: common.BoolToPtr(true)}
+	require.NoError(t, validateDistroCustomizations(&fips, rhel, ImageTypesAws))
+	// the aliases of the image types of the distribution
+	require.NoError(t, validateDistroCustomizations(&fips, rhel, ImageTypesAmi))
+	require.Error(t, validateDistroCustomizations(&fips, rhel, ImageTypesEdgeCommit))
+	require.Error(t, validateDistroCustomizations(&fips, fedora, ImageTypesAws))
+	require.NoError(t, validateDistroCustomizations(&Customizations{Fips: common.BoolToPtr(false)}, fedora, ImageTypesAws))
+}
+
+func TestBuildOSCustomizations(t *testing.T) {
+	cust := Customizations{
+		Kernel:   &Kernel{Name: common.StringToPtr("kernel-debug"), Append: common.StringToPtr("nosmt=force")},
+		Timezone: &Timezone{Timezone: common.StringToPtr("UTC"), Ntpservers: &[]string{"0.pool.ntp.org"}},
+		Locale:   &Locale{Languages: &[]string{"en_US.UTF-8"}, Keyboard: common.StringToPtr("us")},
+		Hostname: common.StringToPtr("host.example.com"),
+		Firewall: &FirewallCustomization{
+			Ports:    &[]string{"22:tcp"},
+			Services: &FirewallServices{Enabled: &[]string{"ssh"}},
+		},
+		Fips: common.BoolToPtr(true),
+	}
+	require.Equal(t, &composer.Customizations{
+		Kernel:   &composer.Kernel{Name: common.StringToPtr("kernel-debug"), Append: common.StringToPtr("nosmt=force")},
+		Timezone: &composer.Timezone{Timezone: common.StringToPtr("UTC"), Ntpservers: &[]string{"0.pool.ntp.org"}},
+		Locale:   &composer.Locale{Languages: &[]string{"en_US.UTF-8"}, Keyboard: common.StringToPtr("us")},
+		Hostname: common.StringToPtr("host.example.com"),
+		Firewall: &composer.FirewallCustomization{
+			Ports:    &[]string{"22:tcp"},
+			Services: &composer.FirewallServices{Enabled: &[]string{"ssh"}},
+		},
+		Fips: &composer.FIPS{Enabled: common.BoolToPtr(true)},
+	}, buildCustomizations(&cust))
+}
+
+func TestFilesystemSize(t *testing.T) {
+	sizes := map[string]uint64{
+		`1073741824`:    1073741824,
+		`"1073741824"`:  1073741824,
+		`"512 MiB"`:     536870912,
+		`"20GB"`:        20000000000,
+		`"20 GiB"`:      21474836480,
+		`"1 TiB"`:       1099511627776,
+		`"4096 B"`:      4096,
+		`"16777216 TB"`: 16777216000000000000,
+	}
+	for in, out := range sizes {
+		var size FilesystemSize
+		require.NoError(t, json.Unmarshal([]byte(in), &size), in)
+		require.Equal(t, FilesystemSize(out), size, in)
+	}
+
+	invalid := []string{`-1`, `1.5`, `"20 gigabytes"`, `"GiB"`, `"20  GiB"`, `"18446744073709551616"`, `"16777216 TiB"`, `true`}
+	for _, in := range invalid {
+		var size FilesystemSize
+		require.Error(t, json.Unmarshal([]byte(in), &size), in)
+	}
+}
+
+func TestValidateFilesystems(t *testing.T) {
+	lvm := CustomizationsPartitioningMode("lvm")
+	valid := Customizations{
+		Filesystem: &[]Filesystem{
+			{Mountpoint: "/", MinSize: 2147483648},
+			{Mountpoint: "/boot", MinSize: 1073741824},
+			{Mountpoint: "/var/lib/pgsql", MinSize: 1073741824},
+			{Mountpoint: "/home/data", MinSize: 1073741824},
+		},
+		PartitioningMode: &lvm,
+	}
+	require.NoError(t, validateFilesystems(&valid, ImageTypesGuestImage))
+	require.NoError(t, validateFilesystems(&valid, ImageTypesAws))
+	require.Error(t, validateFilesystems(&valid, ImageTypesEdgeCommit))
+	require.Error(t, validateFilesystems(&Customizations{PartitioningMode: &lvm}, ImageTypesRhelEdgeInstaller))
+
+	large := Customizations{Filesystem: &[]Filesystem{{Mountpoint: "/", MinSize: FSMaxSize + 1}}}
+	require.Error(t, validateFilesystems(&large, ImageTypesAzure))
+	require.NoError(t, validateFilesystems(&large, ImageTypesGuestImage))
+	large = Customizations{Filesystem: &[]Filesystem{{Mountpoint: "/", MinSize: FSDefaultMaxSize + 1}}}
+	require.Error(t, validateFilesystems(&large, ImageTypesGuestImage))
+
+	for _, mountpoint := range []string{"/etc", "/boot/efi", "/var/run", "/var/lock/x", "/var/", "/var/../etc", "var", "/usr//local"} {
+		cust := Customizations{Filesystem: &[]Filesystem{{Mountpoint: mountpoint, MinSize: 1073741824}}}
+		require.Error(t, validateFilesystems(&cust, ImageTypesGuestImage), mountpoint)
+	}
+
+	duplicate := Customizations{Filesystem: &[]Filesystem{
+		{Mountpoint: "/var", MinSize: 1073741824},
+		{Mountpoint: "/var", MinSize: 1073741824},
+	}}
+	require.Error(t, validateFilesystems(&duplicate, ImageTypesGuestImage))
+}
+
+func TestBuildFilesystems(t *testing.T) {
+	raw := CustomizationsPartitioningMode("raw")
+	// a single filesystem used to be dropped
+	cust := Customizations{
+		Filesystem:       &[]Filesystem{{Mountpoint: "/var", MinSize: 1073741824}},
+		PartitioningMode: &raw,
+	}
+	composerRaw := composer.CustomizationsPartitioningMode("raw")
+	require.Equal(t, &composer.Customizations{
+		Filesystem:       &[]composer.Filesystem{{Mountpoint: "/var", MinSize: 1073741824}},
+		PartitioningMode: &composerRaw,
+	}, buildCustomizations(&cust))
+}
+
+func TestApplyOscapProfile(t *testing.T) {
+	adr, err := distribution.LoadDistroRegistry("../../distributions")
+	require.NoError(t, err)
+	d, err := adr.Available(true).Get("rhel-8")
+	require.NoError(t, err)
+
+	cust := Customizations{
+		Packages: &[]string{"vim", "aide"},
+		Openscap: &OpenSCAP{
+			ProfileId: "xccdf_org.ssgproject.content_profile_e8",
+			Tailoring: &OpenSCAPTailoring{
+				Selected:   &[]string{"xccdf_org.ssgproject.content_rule_package_usbguard_installed"},
+				Unselected: &[]string{"xccdf_org.ssgproject.content_rule_package_aide_installed"},
+			},
+		},
+	}
+	res := buildCustomizations(&cust)
+	require.NoError(t, applyOscapProfile(&cust, d, res))
+	require.Equal(t, &[]string{"vim", "aide", "audit", "chrony", "rsyslog", "sudo"}, res.Packages)
+	require.Equal(t, &composer.Services{Enabled: &[]string{"auditd", "chronyd", "rsyslog"}}, res.Services)
+	require.Equal(t, &composer.OpenSCAPTailoring{
+		Selected:   &[]string{"xccdf_org.ssgproject.content_rule_package_usbguard_installed"},
+		Unselected: &[]string{"xccdf_org.ssgproject.content_rule_package_aide_installed"},
+	}, res.Openscap.Tailoring)
+	// the request itself is stored unchanged
+	require.Equal(t, &[]string{"vim", "aide"}, cust.Packages)
+
+	cust.Openscap.Tailoring.Selected = cust.Openscap.Tailoring.Unselected
+	require.Error(t, applyOscapProfile(&cust, d, buildCustomizations(&cust)))
+
+	unknown := Customizations{Openscap: &OpenSCAP{ProfileId: "test-profile"}}
+	require.Error(t, applyOscapProfile(&unknown, d, buildCustomizations(&unknown)))
+
+	d, err = adr.Available(true).Get("fedora-38")
+	require.NoError(t, err)
+	cis := Customizations{Openscap: &OpenSCAP{ProfileId: "xccdf_org.ssgproject.content_profile_cis"}}
+	require.Error(t, applyOscapProfile(&cis, d, buildCustomizations(&cis)))
+}
+
+// TestBuildOSTreeOptions checks if the buildOSTreeOptions utility function
+// properly transfers the ostree options to the Composer structure.
+func TestBuildOSTreeOptions(t *testing.T) {
+	cases := map[ImageRequest]*composer.OSTree{
+		{Ostree: nil}: nil,
+		{Ostree: &OSTree{Ref: strptr("someref")}}:                                     {Ref: strptr("someref")},
+		{Ostree: &OSTree{Ref: strptr("someref"), Url: strptr("https://example.org")}}: {Ref: strptr("someref"), Url: strptr("https://example.org")},
+		{Ostree: &OSTree{Url: strptr("https://example.org")}}:                         {Url: strptr("https://example.org")},
+	}
+
+	for in, expOut := range cases {
+		require.Equal(t, expOut, buildOSTreeOptions(in.Ostree), "input: %#v", in)
+	}
+}
+
+func TestOSTreeLineage(t *testing.T) {
+	var composerRequest composer.ComposeRequest
+	lookups := 0
+	// composes which haven't finished yet
+	pending := map[string]bool{}
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "Bearer" == r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "Bearer accesstoken", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+
+		var err error
+		if r.Method == http.MethodGet {
+			lookups++
+		}
+		switch {
+		case r.Method == http.MethodPost:
+			err = json.NewDecoder(r.Body).Decode(&composerRequest)
+			require.NoError(t, err)
+			w.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(w).Encode(composer.ComposeId{
+				Id: uuid.New(),
+			})
+		case strings.HasSuffix(r.URL.Path, "/metadata"):
+			id := path.Base(path.Dir(r.URL.Path))
+			err = json.NewEncoder(w).Encode(composer.ComposeMetadata{
+				OstreeCommit: strptr("commit-" + id),
+			})
+		default:
+			status := composer.ImageStatusValueSuccess
+			if pending[path.Base(r.URL.Path)] {
+				status = composer.ImageStatusValueBuilding
+			}
+			err = json.NewEncoder(w).Encode(composer.ComposeStatus{
+				ImageStatus: composer.ImageStatus{
+					Status: status,
+				},
+			})
+		}
+		require.NoError(t, err)
+	}))
+	defer apiSrv.Close()
+
+	srv, tokenSrv := startServer(t, apiSrv.URL, "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	ref := fmt.Sprintf("lineage/%s/edge", uuid.New())
+	compose := func(it ImageTypes, ostree *OSTree) (int, string) {
+		payload := ComposeRequest{
+			Distribution: "rhel-88",
+			ImageRequests: []ImageRequest{
+				{
+					Architecture: "x86_64",
+					ImageType:    it,
+					Ostree:       ostree,
+					UploadRequest: UploadRequest{
+						Type:    UploadTypesAwsS3,
+						Options: AWSS3UploadRequestOptions{},
+					},
+				},
+			},
+		}
+		return tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", payload)
+	}
+	lineage := common.BoolToPtr(true)
+
+	t.Run("ErrorNoCommit", func(t *testing.T) {
+		respStatusCode, body := compose(ImageTypesEdgeCommit, &OSTree{Ref: &ref, Lineage: lineage})
+		require.Equal(t, http.StatusBadRequest, respStatusCode)
+		require.Contains(t, body, "There is no successful commit")
+	})
+
+	t.Run("ErrorNotACommit", func(t *testing.T) {
+		respStatusCode, body := compose(ImageTypesEdgeInstaller, &OSTree{Ref: &ref, Lineage: lineage})
+		require.Equal(t, http.StatusBadRequest, respStatusCode)
+		require.Contains(t, body, "Only edge commits can be built on top of the lineage of a ref")
+	})
+
+	t.Run("ErrorParentAndLineage", func(t *testing.T) {
+		respStatusCode, body := compose(ImageTypesEdgeCommit, &OSTree{Ref: &ref, Url: strptr("https://ostree.example.com/repo"), Parent: strptr("parent"), Lineage: lineage})
+		require.Equal(t, http.StatusBadRequest, respStatusCode)
+		require.Contains(t, body, "it can't be set as well")
+	})
+
+	var first, second ComposeResponse
+	respStatusCode, body := compose(ImageTypesEdgeCommit, &OSTree{Ref: &ref, Url: strptr("https://ostree.example.com/repo")})
+	require.Equal(t, http.StatusCreated, respStatusCode)
+	require.NoError(t, json.Unmarshal([]byte(body), &first))
+
+	respStatusCode, body = compose(ImageTypesEdgeCommit, &OSTree{Ref: &ref, Lineage: lineage})
+	require.Equal(t, http.StatusCreated, respStatusCode)
+	require.NoError(t, json.Unmarshal([]byte(body), &second))
+	require.Equal(t, &composer.OSTree{
+		Ref:    &ref,
+		Url:    strptr("https://ostree.example.com/repo"),
+		Parent: strptr("commit-" + first.Id.String()),
+	}, composerRequest.ImageRequest.Ostree)
+
+	// listing the commits doesn't look them up in composer, the commit
+	// built upon was resolved by the lineage
+	var commits OSTreeCommitsResponse
+	listCommits := func() {
+		respStatusCode, body := tutils.GetResponseBody(t,
+			fmt.Sprintf("http://localhost:8086/api/image-builder/v1/ostree/commits?ref=%s", url.QueryEscape(ref)), &tutils.AuthString0)
+		require.Equal(t, http.StatusOK, respStatusCode)
+		require.NoError(t, json.Unmarshal([]byte(body), &commits))
+	}
+	lookups = 0
+	listCommits()
+	require.Equal(t, 0, lookups)
+	require.Equal(t, 2, commits.Meta.Count)
+	require.Equal(t, second.Id, commits.Data[0].ComposeId)
+	require.Equal(t, "commit-"+first.Id.String(), *commits.Data[0].Parent)
+	require.Equal(t, first.Id, *commits.Data[0].ParentComposeId)
+	require.Nil(t, commits.Data[0].Commit)
+	require.Equal(t, first.Id, commits.Data[1].ComposeId)
+	require.Equal(t, "commit-"+first.Id.String(), *commits.Data[1].Commit)
+	require.Equal(t, string(composer.ImageStatusValueSuccess), *commits.Data[1].Status)
+	require.Nil(t, commits.Data[1].Parent)
+
+	// the checksum is stored once the metadata is fetched
+	respStatusCode, _ = tutils.GetResponseBody(t,
+		fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/metadata", second.Id), &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	listCommits()
+	require.Equal(t, "commit-"+second.Id.String(), *commits.Data[0].Commit)
+
+	var refs OSTreeRefsResponse
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/ostree/refs", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	require.NoError(t, json.Unmarshal([]byte(body), &refs))
+	require.Contains(t, refs.Data, OSTreeRefsResponseItem{
+		Ref:          ref,
+		Commits:      2,
+		LastCommitAt: commits.Data[0].CreatedAt,
+	})
+
+	// the lineage only looks up a few of the commits whose checksum isn't
+	// known, and falls back to the stored ones
+	for i := 0; i < lineageLookups+2; i++ {
+		var pendingCompose ComposeResponse
+		respStatusCode, body = compose(ImageTypesEdgeCommit, &OSTree{Ref: &ref, Url: strptr("https://ostree.example.com/repo")})
+		require.Equal(t, http.StatusCreated, respStatusCode)
+		require.NoError(t, json.Unmarshal([]byte(body), &pendingCompose))
+		pending[pendingCompose.Id.String()] = true
+	}
+	lookups = 0
+	respStatusCode, _ = compose(ImageTypesEdgeCommit, &OSTree{Ref: &ref, Lineage: lineage})
+	require.Equal(t, http.StatusCreated, respStatusCode)
+	require.Equal(t, lineageLookups, lookups)
+	require.Equal(t, strptr("commit-"+second.Id.String()), composerRequest.ImageRequest.Ostree.Parent)
+}
+
+func TestValidateOSTree(t *testing.T) {
+	commit := ImageRequest{ImageType: ImageTypesEdgeCommit}
+	installer := ImageRequest{ImageType: ImageTypesEdgeInstaller}
+	guest := ImageRequest{ImageType: ImageTypesGuestImage}
+	withOSTree := func(ir ImageRequest, o OSTree) ImageRequest {
+		ir.Ostree = &o
+		return ir
+	}
+	checksum := "02604b2da6e954bd34b8b82a835e5a77d2b60ffa02604b2da6e954bd34b8b82a"
+
+	valid := []ImageRequest{
+		guest,
+		commit,
+		withOSTree(commit, OSTree{Ref: strptr("")}),
+		withOSTree(commit, OSTree{Ref: strptr("rhel/8/x86_64/edge")}),
+		withOSTree(commit, OSTree{Ref: strptr("rhel/8/x86_64/edge"), Url: strptr("https://ostree.example.com/repo"), Parent: strptr("rhel/8/x86_64/edge")}),
+		withOSTree(commit, OSTree{Url: strptr("https://ostree.example.com/repo"), Contenturl: strptr("https://cdn.example.com/repo"), Parent: &checksum}),
+		withOSTree(installer, OSTree{Ref: strptr("rhel/8/x86_64/edge"), Url: strptr("http://ostree.example.com:8080")}),
+	}
+	for _, ir := range valid {
+		require.NoError(t, validateOSTree(ir), "input: %#v", ir.Ostree)
+	}
+
+	invalid := map[string]ImageRequest{
+		"don't apply to guest-image":       withOSTree(guest, OSTree{Ref: strptr("rhel/8/x86_64/edge")}),
+		"Invalid ostree ref":               withOSTree(commit, OSTree{Ref: strptr("/rhel/8")}),
+		"Invalid ostree repository url":    withOSTree(commit, OSTree{Url: strptr("ostree.example.com/repo")}),
+		"content url requires the url":     withOSTree(commit, OSTree{Contenturl: strptr("https://cdn.example.com/repo")}),
+		"has to be set":                    withOSTree(commit, OSTree{Parent: &checksum}),
+		"Only edge commits can have":       withOSTree(installer, OSTree{Url: strptr("https://ostree.example.com/repo"), Parent: &checksum}),
+		"Invalid ostree parent":            withOSTree(commit, OSTree{Url: strptr("https://ostree.example.com/repo"), Parent: strptr("rhel 8")}),
+		"Invalid ostree repository url \"": withOSTree(commit, OSTree{Url: strptr("https://ostree.example.com/repo"), Contenturl: strptr("file:///repo")}),
+	}
+	for msg, ir := range invalid {
+		err := validateOSTree(ir)
+		require.Error(t, err, "input: %#v", ir.Ostree)
+		require.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestVerifyOSTreeParent(t *testing.T) {
+	head := "02604b2da6e954bd34b8b82a835e5a77d2b60ffa02604b2da6e954bd34b8b82a"
+	repoSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repo/refs/heads/rhel/8/x86_64/edge":
+			fmt.Fprintln(w, head)
+		case "/broken/refs/heads/rhel/8/x86_64/edge":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/invalid/refs/heads/rhel/8/x86_64/edge":
+			fmt.Fprintln(w, "not a checksum")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer repoSrv.Close()
+
+	h := Handlers{server: &Server{ostreeRemote: ostree.NewRemote(repoSrv.Client())}}
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/compose", nil), httptest.NewRecorder())
+	options := func(url, parent string) *composer.OSTree {
+		return &composer.OSTree{Url: &url, Parent: &parent}
+	}
+
+	require.NoError(t, h.verifyOSTreeParent(ctx, nil))
+	require.NoError(t, h.verifyOSTreeParent(ctx, &composer.OSTree{Ref: strptr("rhel/8/x86_64/edge")}))
+	require.NoError(t, h.verifyOSTreeParent(ctx, options(repoSrv.URL+"/repo", "rhel/8/x86_64/edge")))
+
+	err := h.verifyOSTreeParent(ctx, options(repoSrv.URL+"/repo", "rhel/9/x86_64/edge"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "isn't in the repository")
+
+	err = h.verifyOSTreeParent(ctx, options(repoSrv.URL+"/repo", head))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "isn't in the repository")
+
+	err = h.verifyOSTreeParent(ctx, options(repoSrv.URL+"/invalid", "rhel/8/x86_64/edge"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unable to resolve OSTree parent")
+
+	// a repository which fails or can't be reached is left to composer
+	require.NoError(t, h.verifyOSTreeParent(ctx, options(repoSrv.URL+"/broken", "rhel/8/x86_64/edge")))
+	h.server.ostreeRemote = ostree.NewRemote(nil)
+	require.NoError(t, h.verifyOSTreeParent(ctx, options(repoSrv.URL+"/repo", "rhel/9/x86_64/edge")))
+
+	// without a remote the parent is left to composer
+	h.server.ostreeRemote = nil
+	require.NoError(t, h.verifyOSTreeParent(ctx, options(repoSrv.URL+"/repo", "rhel/9/x86_64/edge")))
+}
+
+func TestSubscriptionProfiles(t *testing.T) {
+	var composerRequest composer.ComposeRequest
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "Bearer" == r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "Bearer accesstoken", r.Header.Get("Authorization"))
+		err := json.NewDecoder(r.Body).Decode(&composerRequest)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(composer.ComposeId{
+			Id: uuid.New(),
+		})
+		require.NoError(t, err)
+	}))
+	defer apiSrv.Close()
+
+	srv, tokenSrv := startServer(t, apiSrv.URL, "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	subscriptionsURL := "http://localhost:8086/api/image-builder/v1/subscriptions"
+	profileRequest := SubscriptionProfileRequest{
+		Name:          fmt.Sprintf("profile-%s", uuid.New()),
+		Organization:  2040324,
+		ActivationKey: strptr("my-secret-key"),
+		ServerUrl:     "subscription.rhsm.redhat.com",
+		BaseUrl:       "http://cdn.redhat.com/",
+		Insights:      true,
+	}
+
+	withoutKey := profileRequest
+	withoutKey.ActivationKey = nil
+	respStatusCode, body := tutils.PostResponseBody(t, subscriptionsURL, withoutKey)
+	require.Equal(t, http.StatusBadRequest, respStatusCode)
+	require.Contains(t, body, "needs an activation key")
+
+	var profile SubscriptionProfile
+	respStatusCode, body = tutils.PostResponseBody(t, subscriptionsURL, profileRequest)
+	require.Equal(t, http.StatusCreated, respStatusCode)
+	require.NotContains(t, body, "my-secret-key")
+	require.NoError(t, json.Unmarshal([]byte(body), &profile))
+	require.Equal(t, profileRequest.Name, profile.Name)
+	require.Equal(t, 2040324, profile.Organization)
+	require.False(t, profile.Rhc)
+
+	respStatusCode, _ = tutils.PostResponseBody(t, subscriptionsURL, profileRequest)
+	require.Equal(t, http.StatusConflict, respStatusCode)
+
+	var profiles SubscriptionProfilesResponse
+	respStatusCode, body = tutils.GetResponseBody(t, subscriptionsURL, &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	require.NotContains(t, body, "my-secret-key")
+	require.NoError(t, json.Unmarshal([]byte(body), &profiles))
+	require.Contains(t, profiles.Data, profile)
+
+	respStatusCode, _ = tutils.GetResponseBody(t, fmt.Sprintf("%s/%s", subscriptionsURL, profile.Id), &tutils.AuthString1)
+	require.Equal(t, http.StatusNotFound, respStatusCode)
+
+	// updating without activation key keeps it
+	update := withoutKey
+	update.Name = profileRequest.Name + "-renamed"
+	update.Rhc = common.BoolToPtr(true)
+	respStatusCode, body = tutils.PutResponseBody(t, fmt.Sprintf("%s/%s", subscriptionsURL, profile.Id), update)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	require.NoError(t, json.Unmarshal([]byte(body), &profile))
+	require.Equal(t, update.Name, profile.Name)
+	require.True(t, profile.Rhc)
+
+	compose := func(cust *Customizations) (int, string) {
+		payload := ComposeRequest{
+			Customizations: cust,
+			Distribution:   "rhel-88",
+			ImageRequests: []ImageRequest{
+				{
+					Architecture: "x86_64",
+					ImageType:    ImageTypesGuestImage,
+					UploadRequest: UploadRequest{
+						Type:    UploadTypesAwsS3,
+						Options: AWSS3UploadRequestOptions{},
+					},
+				},
+			},
+		}
+		return tutils.PostResponseBody(t, "http://localhost:8086/api/image-builder/v1/compose", payload)
+	}
+
+	respStatusCode, _ = compose(&Customizations{SubscriptionProfileId: &profile.Id})
+	require.Equal(t, http.StatusCreated, respStatusCode)
+	require.Equal(t, &composer.Subscription{
+		ActivationKey: "my-secret-key",
+		BaseUrl:       "http://cdn.redhat.com/",
+		Insights:      true,
+		Rhc:           common.BoolToPtr(true),
+		Organization:  "2040324",
+		ServerUrl:     "subscription.rhsm.redhat.com",
+	}, composerRequest.Customizations.Subscription)
+
+	respStatusCode, _ = compose(&Customizations{Subscription: &Subscription{
+		Organization:  2040324,
+		ActivationKey: "inline-secret-key",
+		ServerUrl:     "subscription.rhsm.redhat.com",
+		BaseUrl:       "http://cdn.redhat.com/",
+	}})
+	require.Equal(t, http.StatusCreated, respStatusCode)
+	require.Equal(t, "inline-secret-key", composerRequest.Customizations.Subscription.ActivationKey)
+
+	// neither key is stored or returned with the composes
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	require.NotContains(t, body, "my-secret-key")
+	require.NotContains(t, body, "inline-secret-key")
+	require.Contains(t, body, profile.Id.String())
+	require.Contains(t, body, "REDACTED")
+
+	respStatusCode, body = compose(&Customizations{
+		SubscriptionProfileId: &profile.Id,
+		Subscription:          &Subscription{Organization: 2040324},
+	})
+	require.Equal(t, http.StatusBadRequest, respStatusCode)
+	require.Contains(t, body, "not both")
+
+	unknown := uuid.New()
+	respStatusCode, body = compose(&Customizations{SubscriptionProfileId: &unknown})
+	require.Equal(t, http.StatusBadRequest, respStatusCode)
+	require.Contains(t, body, "not found")
+
+	respStatusCode, _ = tutils.DeleteResponseBody(t, fmt.Sprintf("%s/%s", subscriptionsURL, profile.Id), &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	respStatusCode, _ = tutils.GetResponseBody(t, fmt.Sprintf("%s/%s", subscriptionsURL, profile.Id), &tutils.AuthString0)
+	require.Equal(t, http.StatusNotFound, respStatusCode)
+
+	// the changes of the profile are audited, most recent first
+	var audit AuditResponse
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/audit", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	require.NoError(t, json.Unmarshal([]byte(body), &audit))
+	var actions []string
+	for _, e := range audit.Data {
+		if e.TargetId == profile.Id {
+			actions = append(actions, e.Action)
+			// only the delete has no request body
+			require.Equal(t, e.Action == db.AuditActionDeleteSubscriptionProfile, e.RequestHash == "")
+		}
+	}
+	require.Equal(t, []string{
+		db.AuditActionDeleteSubscriptionProfile,
+		db.AuditActionUpdateSubscriptionProfile,
+		db.AuditActionCreateSubscriptionProfile,
+	}, actions)
+}
+
+// fillRequest sets every field of the generated type, strings are set to
+// their json path so they can be told apart after redaction
+func fillRequest(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillRequest(v.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" || !v.Field(i).CanSet() {
+				continue
+			}
+			fillRequest(v.Field(i), path+"."+name)
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillRequest(v.Index(0), path+".*")
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		value := reflect.New(v.Type().Elem()).Elem()
+		fillRequest(value, path+".*")
+		v.SetMapIndex(reflect.ValueOf("key"), value)
+	case reflect.String:
+		v.SetString(path)
+	}
+}
+
+// typeHasPath tells whether the json path exists in the generated type
+func typeHasPath(t reflect.Type, path []string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if len(path) == 0 {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == path[0] {
+				return typeHasPath(t.Field(i).Type, path[1:])
+			}
+		}
+	case reflect.Slice, reflect.Map:
+		return path[0] == wildcard && typeHasPath(t.Elem(), path[1:])
+	}
+	return false
+}
+
+// collectStrings counts the strings of a decoded request
+func collectStrings(v interface{}, res map[string]int) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for _, value := range node {
+			collectStrings(value, res)
+		}
+	case []interface{}:
+		for _, value := range node {
+			collectStrings(value, res)
+		}
+	case string:
+		res[node]++
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	spec, err := GetSwagger()
+	require.NoError(t, err)
+	r, err := newRedactor(spec, "ComposeRequest", sensitivePaths)
+	require.NoError(t, err)
+
+	require.Contains(t, r.paths, []string{"customizations", "subscription", "activation-key"})
+	require.Contains(t, r.paths, []string{"customizations", "users", "*", "password"})
+	for _, p := range r.paths {
+		require.True(t, typeHasPath(reflect.TypeOf(ComposeRequest{}), p), "path %v isn't in the generated types", p)
+	}
+
+	_, err = newRedactor(spec, "NoSuchSchema", nil)
+	require.Error(t, err)
+
+	var cr ComposeRequest
+	fillRequest(reflect.ValueOf(&cr).Elem(), "")
+	raw, err := json.Marshal(cr)
+	require.NoError(t, err)
+
+	redacted, err := r.redact(raw)
+	require.NoError(t, err)
+
+	var before, after interface{}
+	require.NoError(t, json.Unmarshal(raw, &before))
+	require.NoError(t, json.Unmarshal(redacted, &after))
+	beforeStrings := map[string]int{}
+	afterStrings := map[string]int{}
+	collectStrings(before, beforeStrings)
+	collectStrings(after, afterStrings)
+
+	// the secrets, and only them, were replaced
+	for _, p := range r.paths {
+		path := "." + strings.Join(p, ".")
+		require.Equal(t, 1, beforeStrings[path], path)
+		require.Zero(t, afterStrings[path], path)
+		delete(beforeStrings, path)
+	}
+	require.Equal(t, len(r.paths), afterStrings[redactedSecret])
+	delete(afterStrings, redactedSecret)
+	require.Equal(t, beforeStrings, afterStrings)
+
+	// redacting twice changes nothing
+	again, err := r.redact(redacted)
+	require.NoError(t, err)
+	require.Equal(t, redacted, again)
+
+	// requests without secrets are left as they are
+	plain := json.RawMessage(`{"distribution": "rhel-88", "customizations": {"users": [{"name": "user1"}], "subscription": null}}`)
+	result, err := r.redact(plain)
+	require.NoError(t, err)
+	require.Equal(t, plain, result)
+
+	result, err = r.redact(json.RawMessage(`{"customizations": {"subscription": {"organization": 12345678901234567890, "activation-key": "key"}}}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"customizations": {"subscription": {"organization": 12345678901234567890, "activation-key": "<REDACTED>"}}}`, string(result))
+
+	_, err = r.redact(json.RawMessage(`{"customizations":`))
+	require.Error(t, err)
+}
+
+func TestRejectRedactedSecrets(t *testing.T) {
+	spec, err := GetSwagger()
+	require.NoError(t, err)
+	r, err := newRedactor(spec, "ComposeRequest", sensitivePaths)
+	require.NoError(t, err)
+	h := Handlers{server: &Server{composeRedactor: r}}
+
+	paths, err := r.redactedPaths(json.RawMessage(`{"customizations": {"users": [{"name": "user1", "password": "<REDACTED>"}, {"name": "user2", "password": "hash"}], "subscription": {"activation-key": "<REDACTED>"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"customizations.subscription.activation-key", "customizations.users.0.password"}, paths)
+
+	// the mask only matters where secrets are
+	paths, err = r.redactedPaths(json.RawMessage(`{"image_name": "<REDACTED>", "customizations": {"users": [{"name": "<REDACTED>"}]}}`))
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	// as read back from the api
+	var cr ComposeRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"distribution": "rhel-88", "customizations": {"subscription": {"organization": 1, "activation-key": "<REDACTED>", "server-url": "subscription.rhsm.redhat.com", "base-url": "http://cdn.redhat.com/", "insights": true}}}`), &cr))
+	err = h.rejectRedactedSecrets(cr)
+	require.Error(t, err)
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.Code)
+	require.Contains(t, httpErr.Message, "customizations.subscription.activation-key")
+	require.Contains(t, httpErr.Message, "subscription_profile_id")
+
+	cr.Customizations.Subscription.ActivationKey = "key"
+	require.NoError(t, h.rejectRedactedSecrets(cr))
+}
+
+func TestValidateRepositories(t *testing.T) {
+	keyURL := "https://example.com/RPM-GPG-KEY"
+	payload := func(key *string, checkGpg, checkRepoGpg *bool) *Customizations {
+		return &Customizations{
+			PayloadRepositories: &[]Repository{
+				{
+					Baseurl:      common.StringToPtr("https://example.com/repo"),
+					Gpgkey:       key,
+					CheckGpg:     checkGpg,
+					CheckRepoGpg: checkRepoGpg,
+				},
+			},
+		}
+	}
+	custom := func(keys *[]string, checkGpg, checkRepoGpg *bool) *Customizations {
+		return &Customizations{
+			CustomRepositories: &[]CustomRepository{
+				{
+					Id:           "repo",
+					Baseurl:      &[]string{"https://example.com/repo"},
+					Gpgkey:       keys,
+					CheckGpg:     checkGpg,
+					CheckRepoGpg: checkRepoGpg,
+				},
+			},
+		}
+	}
+
+	valid := map[string]*Customizations{
+		"payload key":          payload(common.StringToPtr(testGPGKey), common.BoolToPtr(true), common.BoolToPtr(true)),
+		"payload url":          payload(common.StringToPtr(keyURL), common.BoolToPtr(true), nil),
+		"payload without key":  payload(nil, common.BoolToPtr(false), nil),
+		"payload empty key":    payload(common.StringToPtr(""), nil, nil),
+		"custom keys":          custom(&[]string{testGPGKey, keyURL}, common.BoolToPtr(true), nil),
+		"custom without keys":  custom(nil, nil, common.BoolToPtr(false)),
+		"custom key unchecked": custom(&[]string{testGPGKey}, common.BoolToPtr(false), nil),
+	}
+	for name, cust := range valid {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, validateRepositories(cust))
+		})
+	}
+
+	invalid := map[string]*Customizations{
+		"payload check without key":      payload(nil, common.BoolToPtr(true), nil),
+		"payload repo check without key": payload(common.StringToPtr(""), nil, common.BoolToPtr(true)),
+		"payload invalid key":            payload(common.StringToPtr("some-gpg-key"), nil, nil),
+		"payload file url":               payload(common.StringToPtr("file:///etc/pki/rpm-gpg/RPM-GPG-KEY"), nil, nil),
+		"custom check without key":       custom(&[]string{}, common.BoolToPtr(true), nil),
+		"custom repo check without key":  custom(nil, nil, common.BoolToPtr(true)),
+		"custom invalid key":             custom(&[]string{testGPGKey, "some-gpg-key"}, common.BoolToPtr(true), nil),
+		"custom truncated key":           custom(&[]string{testGPGKey[:len(testGPGKey)/2]}, nil, nil),
+	}
+	for name, cust := range invalid {
+		t.Run(name, func(t *testing.T) {
+			err := validateRepositories(cust)
+			require.Error(t, err)
+			require.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+		})
+	}
+
+	var urls []string
+	for i := 0; i <= maxGPGKeyURLs; i++ {
+		urls = append(urls, fmt.Sprintf("%s-%d", keyURL, i))
+	}
+	require.Error(t, validateRepositories(custom(&urls, nil, nil)))
+	require.NoError(t, validateRepositories(custom(&[]string{}, nil, nil)))
+}
+
+func TestResolveGPGKeys(t *testing.T) {
+	fetches := 0
+	keySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/RPM-GPG-KEY":
+			fetches++
+			fmt.Fprint(w, testGPGKey)
+		case "/index.html":
+			fmt.Fprint(w, "<html></html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer keySrv.Close()
+	keyURL := keySrv.URL + "/RPM-GPG-KEY"
+
+	h := Handlers{server: &Server{gpgKeys: gpgkey.NewFetcher(keySrv.Client())}}
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/compose", nil), httptest.NewRecorder())
+
+	require.NoError(t, h.resolveGPGKeys(ctx, nil))
+
+	cust := &composer.Customizations{
+		PayloadRepositories: &[]composer.Repository{
+			{
+				Baseurl: common.StringToPtr("https://example.com/payload"),
+				Gpgkey:  common.StringToPtr(keyURL),
+			},
+			{
+				Baseurl: common.StringToPtr("https://example.com/unsigned"),
+			},
+		},
+		CustomRepositories: &[]composer.CustomRepository{
+			{
+				Id:       "repo",
+				Baseurl:  &[]string{"https://example.com/repo"},
+				Gpgkey:   &[]string{testGPGKey, keyURL},
+				CheckGpg: common.BoolToPtr(false),
+			},
+		},
+	}
+	require.NoError(t, h.resolveGPGKeys(ctx, cust))
+	payload := (*cust.PayloadRepositories)[0]
+	require.Equal(t, testGPGKey, *payload.Gpgkey)
+	// repositories with a key check signatures unless told otherwise
+	require.True(t, *payload.CheckGpg)
+	require.Nil(t, (*cust.PayloadRepositories)[1].Gpgkey)
+	require.Nil(t, (*cust.PayloadRepositories)[1].CheckGpg)
+	require.Equal(t, []string{testGPGKey, testGPGKey}, *(*cust.CustomRepositories)[0].Gpgkey)
+	require.False(t, *(*cust.CustomRepositories)[0].CheckGpg)
+	// the same url is fetched once
+	require.Equal(t, 1, fetches)
+
+	for path, msg := range map[string]string{
+		"/index.html": "isn't an armored public key",
+		"/missing":    "Unable to fetch the gpg key",
+	} {
+		err := h.resolveGPGKeys(ctx, &composer.Customizations{
+			CustomRepositories: &[]composer.CustomRepository{
+				{
+					Id:     "repo",
+					Gpgkey: &[]string{keySrv.URL + path},
+				},
+			},
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+		require.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestComposerError(t *testing.T) {
+	err := composerError(&composer.APIError{StatusCode: http.StatusNotFound, Body: []byte("not found")}, "Failed querying compose status")
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusNotFound, httpErr.Code)
+	require.Equal(t, errComposerNotFound, httpErr.Message)
+
+	apiErr := &composer.APIError{StatusCode: http.StatusInternalServerError, Err: composer.Error{Id: "1001", Code: "IMAGE-BUILDER-COMPOSER-1001", Reason: "oops"}}
+	err = composerError(apiErr, "Failed querying compose status")
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusInternalServerError, httpErr.Code)
+	require.Equal(t, "IB-COMPOSER-ERROR", httpErr.Message.(errorCode).Code)
+	require.Equal(t, "Failed querying compose status", httpErr.Message.(errorCode).Message)
+	require.Equal(t, apiErr, httpErr.Internal)
+
+	// known errors are translated by the catalogue
+	err = composerError(&composer.APIError{StatusCode: http.StatusBadRequest, Err: composer.Error{Id: "31", Code: "IMAGE-BUILDER-COMPOSER-31"}}, "Something went wrong creating the clone")
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.Code)
+	require.Equal(t, "IB-COMPOSE-NOT-READY", httpErr.Message.(errorCode).Code)
+
+	// user errors are 4xx, the errors of image-builder's requests stay 500
+	err = composerError(&composer.APIError{StatusCode: http.StatusBadRequest, Err: composer.Error{Id: "6", Code: "IMAGE-BUILDER-COMPOSER-6"}}, "Failed posting compose request to osbuild-composer")
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.Code)
+	require.Equal(t, "IB-UNSUPPORTED-IMAGE-TYPE", httpErr.Message.(errorCode).Code)
+	err = composerError(&composer.APIError{StatusCode: http.StatusBadRequest, Err: composer.Error{Id: "8", Code: "IMAGE-BUILDER-COMPOSER-8"}}, "Failed posting compose request to osbuild-composer")
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.Code)
+	require.Equal(t, "IB-PACKAGE-RESOLUTION", httpErr.Message.(errorCode).Code)
+	err = composerError(&composer.APIError{StatusCode: http.StatusBadRequest, Err: composer.Error{Id: "20", Code: "IMAGE-BUILDER-COMPOSER-20"}}, "Failed posting compose request to osbuild-composer")
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusInternalServerError, httpErr.Code)
+	require.Equal(t, "IB-COMPOSER-ERROR", httpErr.Message.(errorCode).Code)
+	require.Equal(t, "Failed posting compose request to osbuild-composer", httpErr.Message.(errorCode).Message)
+
+	// unknown 4xx errors keep composer's status and reason
+	err = composerError(&composer.APIError{StatusCode: http.StatusUnprocessableEntity, Err: composer.Error{Id: "99", Code: "IMAGE-BUILDER-COMPOSER-99", Reason: "Something is off"}}, "Failed posting compose request to osbuild-composer")
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+	require.Equal(t, "IB-INVALID-REQUEST", httpErr.Message.(errorCode).Code)
+	require.Equal(t, "Something is off", httpErr.Message.(errorCode).Message)
+
+	// unreachable composers are turned into a 503 by HTTPErrorHandler
+	require.Equal(t, composer.ErrCircuitOpen, composerError(composer.ErrCircuitOpen, "Failed querying compose status"))
+}
+
+func TestParseComposeStatusError(t *testing.T) {
+	var targetDetails interface{} = []interface{}{
+		map[string]interface{}{"id": 11, "reason": "uploading the image failed"},
+	}
+	parsed := parseComposeStatusError(&composer.ComposeStatusError{
+		Id:      28,
+		Reason:  "target errors",
+		Details: &targetDetails,
+	})
+	require.Equal(t, 11, parsed.Id)
+	require.Equal(t, "IB-UPLOAD-FAILED", *parsed.Code)
+
+	// errors outside the catalogue are still reported
+	parsed = parseComposeStatusError(&composer.ComposeStatusError{Id: 999, Reason: "new error"})
+	require.Equal(t, 999, parsed.Id)
+	require.Equal(t, "new error", parsed.Reason)
+	require.Equal(t, "IB-BUILD-FAILED", *parsed.Code)
+
+	// details which aren't errors are kept as they are
+	var details interface{} = "not a list"
+	parsed = parseComposeStatusError(&composer.ComposeStatusError{Id: 26, Reason: "dependency failed", Details: &details})
+	require.Equal(t, 26, parsed.Id)
+	require.Equal(t, &details, parsed.Details)
+}
+
+func TestReadinessProbeNotReady(t *testing.T) {
+	srv, tokenSrv := startServer(t, "", "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/ready", &tutils.AuthString0)
+	require.Equal(t, http.StatusServiceUnavailable, respStatusCode)
+	require.Contains(t, body, "The image building service is unavailable")
+}
+
+func TestReadinessProbeReady(t *testing.T) {
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "Bearer" == r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "Bearer accesstoken", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "{\"version\":\"fake\"}")
+	}))
+	defer apiSrv.Close()
+
+	srv, tokenSrv := startServer(t, apiSrv.URL, "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/ready", &tutils.AuthString0)
+	require.Equal(t, 200, respStatusCode)
+	require.Contains(t, body, "{\"readiness\":\"ready\"}")
+}
+
+func TestMetrics(t *testing.T) {
+	srv, tokenSrv := startServer(t, "", "")
+	defer func() {
 		err := srv.Shutdown(context.Background())
 		require.NoError(t, err)
 	}()
@@ -1941,7 +3608,7 @@ func TestComposeStatusError(t *testing.T) {
 	dbase, err := dbc.NewDB()
 	require.NoError(t, err)
 	imageName := "MyImageName"
-	err = dbase.InsertCompose(id, "600000", "000001", &imageName, json.RawMessage("{}"))
+	err = dbase.InsertCompose(id, "600000", "000001", &imageName, json.RawMessage("{}"), "default")
 	require.NoError(t, err)
 
 	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, "", dbase, "../../distributions", "")
@@ -1962,8 +3629,11 @@ func TestComposeStatusError(t *testing.T) {
 		ImageStatus: ImageStatus{
 			Status: "failure",
 			Error: &ComposeStatusError{
-				Id:     23,
-				Reason: "Marking errors: package",
+				Id:          23,
+				Reason:      "Marking errors: package",
+				Code:        common.StringToPtr("IB-PACKAGE-RESOLUTION"),
+				Message:     common.StringToPtr("The packages of the image couldn't be resolved"),
+				Remediation: common.StringToPtr("Check that the packages and custom repositories exist for the distribution and architecture."),
 			},
 		},
 		Request: ComposeRequest{},
@@ -2024,7 +3694,7 @@ func TestGetClones(t *testing.T) {
       "image_type": "aws"
     }
   ]
-}`))
+}`), "default")
 	require.NoError(t, err)
 	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, provSrv.URL, dbase, "../../distributions", "")
 	defer func() {
@@ -2067,6 +3737,286 @@ func TestGetClones(t *testing.T) {
 	require.Equal(t, cloneReqExp, cloneReqRecv)
 }
 
+func TestCloneOnlyAWS(t *testing.T) {
+	gcpId := uuid.New()
+	azureId := uuid.New()
+
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// composer only clones AWS composes, others are rejected before
+		t.Errorf("Unexpected request to composer: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer apiSrv.Close()
+
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+	for id, imageType := range map[uuid.UUID]string{gcpId: "gcp", azureId: "azure"} {
+		err = dbase.InsertCompose(id, "500000", "000000", nil, json.RawMessage(fmt.Sprintf(`
+{
+  "image_requests": [
+    {
+      "image_type": "%s"
+    }
+  ]
+}`, imageType)), "default")
+		require.NoError(t, err)
+	}
+	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, "", dbase, "../../distributions", "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	for _, id := range []uuid.UUID{gcpId, azureId} {
+		respStatusCode, body := tutils.PostResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/clone", id), AWSEC2Clone{
+			Region: "us-east-2",
+		})
+		require.Equal(t, http.StatusBadRequest, respStatusCode)
+		require.Contains(t, body, "only available for AWS composes")
+	}
+}
+
+func TestCloneBatch(t *testing.T) {
+	id := uuid.New()
+	awsAccountId := "123456123456"
+
+	var regions []string
+	cloneStatus := map[uuid.UUID]composer.UploadStatusValue{}
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "Bearer" == r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == "POST" {
+			var cloneReq composer.AWSEC2CloneCompose
+			err := json.NewDecoder(r.Body).Decode(&cloneReq)
+			require.NoError(t, err)
+			require.Equal(t, []string{"123456789012", awsAccountId}, *cloneReq.ShareWithAccounts)
+			regions = append(regions, cloneReq.Region)
+
+			cloneId := uuid.New()
+			cloneStatus[cloneId] = composer.Running
+			w.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(w).Encode(composer.CloneComposeResponse{
+				Id: cloneId,
+			})
+			require.NoError(t, err)
+			return
+		}
+
+		cloneId, err := uuid.Parse(path.Base(r.URL.Path))
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(composer.CloneStatus{
+			Options: composer.AWSEC2UploadStatus{
+				Ami:    "ami-1",
+				Region: "us-east-2",
+			},
+			Status: cloneStatus[cloneId],
+			Type:   composer.UploadTypesAws,
+		})
+		require.NoError(t, err)
+	}))
+	defer apiSrv.Close()
+
+	provCalls := 0
+	provSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provCalls++
+		awsId := struct {
+			AccountId *string `json:"account_id,omitempty"`
+		}{
+			AccountId: &awsAccountId,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(w).Encode(provisioning.V1SourceUploadInfoResponse{
+			Aws: &awsId,
+		})
+		require.NoError(t, err)
+	}))
+	defer provSrv.Close()
+
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+	err = dbase.InsertCompose(id, "500000", "000000", nil, json.RawMessage(`
+{
+  "image_requests": [
+    {
+      "image_type": "aws"
+    }
+  ]
+}`), "default")
+	require.NoError(t, err)
+	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, provSrv.URL, dbase, "../../distributions", "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	respStatusCode, body := tutils.PostResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/clone-batch", id), AWSEC2CloneBatch{
+		Regions:           []string{"us-east-1", "us-east-2", "eu-central-1"},
+		ShareWithAccounts: &[]string{"123456789012"},
+		ShareWithSources:  &[]string{"1"},
+	})
+	require.Equal(t, http.StatusCreated, respStatusCode, body)
+	var batchResp CloneBatchResponse
+	err = json.Unmarshal([]byte(body), &batchResp)
+	require.NoError(t, err)
+	require.Len(t, batchResp.Clones, 3)
+	require.Equal(t, []string{"us-east-1", "us-east-2", "eu-central-1"}, regions)
+	// the source is resolved once for all regions
+	require.Equal(t, 1, provCalls)
+
+	// the clones of the batch are clones of the compose
+	var csResp ClonesResponse
+	respStatusCode, body = tutils.GetResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/clones", id), &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	err = json.Unmarshal([]byte(body), &csResp)
+	require.NoError(t, err)
+	require.Len(t, csResp.Data, 3)
+
+	batchStatus := func(auth string) (int, CloneBatchStatus) {
+		respStatusCode, body := tutils.GetResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/clone-batches/%s", batchResp.Id), &auth)
+		var status CloneBatchStatus
+		if respStatusCode == http.StatusOK {
+			require.NoError(t, json.Unmarshal([]byte(body), &status))
+		}
+		return respStatusCode, status
+	}
+
+	respStatusCode, status := batchStatus(tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	require.Equal(t, UploadStatusStatusRunning, status.Status)
+	require.Len(t, status.Clones, 3)
+	for i, c := range status.Clones {
+		require.Equal(t, batchResp.Clones[i].Id, c.Id)
+		require.Equal(t, batchResp.Clones[i].Region, c.Region)
+	}
+
+	for cloneId := range cloneStatus {
+		cloneStatus[cloneId] = composer.Success
+	}
+	_, status = batchStatus(tutils.AuthString0)
+	require.Equal(t, UploadStatusStatusSuccess, status.Status)
+
+	cloneStatus[batchResp.Clones[1].Id] = composer.Failure
+	_, status = batchStatus(tutils.AuthString0)
+	require.Equal(t, UploadStatusStatusFailure, status.Status)
+	require.Equal(t, UploadStatusStatusFailure, status.Clones[1].UploadStatus.Status)
+
+	respStatusCode, _ = batchStatus(tutils.AuthString1)
+	require.Equal(t, http.StatusNotFound, respStatusCode)
+}
+
+func TestCloneBatchRegionFails(t *testing.T) {
+	id := uuid.New()
+
+	var regions []string
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "Bearer" == r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == "POST" {
+			var cloneReq composer.AWSEC2CloneCompose
+			err := json.NewDecoder(r.Body).Decode(&cloneReq)
+			require.NoError(t, err)
+			regions = append(regions, cloneReq.Region)
+
+			if cloneReq.Region == "us-east-2" {
+				w.WriteHeader(http.StatusBadRequest)
+				err = json.NewEncoder(w).Encode(composer.Error{
+					Id:     "30",
+					Code:   "IMAGE-BUILDER-COMPOSER-30",
+					Reason: "Request could not be validated",
+				})
+				require.NoError(t, err)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(w).Encode(composer.CloneComposeResponse{
+				Id: uuid.New(),
+			})
+			require.NoError(t, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(w).Encode(composer.CloneStatus{
+			Options: composer.AWSEC2UploadStatus{
+				Ami:    "ami-1",
+				Region: "us-east-1",
+			},
+			Status: composer.Running,
+			Type:   composer.UploadTypesAws,
+		})
+		require.NoError(t, err)
+	}))
+	defer apiSrv.Close()
+
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+	err = dbase.InsertCompose(id, "500000", "000000", nil, json.RawMessage(`
+{
+  "image_requests": [
+    {
+      "image_type": "aws"
+    }
+  ]
+}`), "default")
+	require.NoError(t, err)
+	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, "", dbase, "../../distributions", "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	// failing the first region creates no batch
+	respStatusCode, body := tutils.PostResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/clone-batch", id), AWSEC2CloneBatch{
+		Regions:           []string{"us-east-2", "us-east-1"},
+		ShareWithAccounts: &[]string{"123456789012"},
+	})
+	require.Equal(t, http.StatusBadRequest, respStatusCode, body)
+	require.Contains(t, body, "IB-INVALID-REQUEST")
+	require.Equal(t, []string{"us-east-2"}, regions)
+
+	// failing partway through returns the clones created so far
+	regions = nil
+	respStatusCode, body = tutils.PostResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/clone-batch", id), AWSEC2CloneBatch{
+		Regions:           []string{"us-east-1", "us-east-2", "eu-central-1"},
+		ShareWithAccounts: &[]string{"123456789012"},
+	})
+	require.Equal(t, http.StatusMultiStatus, respStatusCode, body)
+	require.Equal(t, []string{"us-east-1", "us-east-2"}, regions)
+	var batchResp CloneBatchResponse
+	err = json.Unmarshal([]byte(body), &batchResp)
+	require.NoError(t, err)
+	require.Len(t, batchResp.Clones, 1)
+	require.Equal(t, "us-east-1", batchResp.Clones[0].Region)
+	require.NotNil(t, batchResp.Failed)
+	require.Equal(t, "us-east-2", batchResp.Failed.Region)
+	require.Equal(t, "400", batchResp.Failed.Error.Title)
+	require.Equal(t, "IB-INVALID-REQUEST", *batchResp.Failed.Error.Code)
+	require.NotNil(t, batchResp.SkippedRegions)
+	require.Equal(t, []string{"eu-central-1"}, *batchResp.SkippedRegions)
+
+	// the clones created before the failure can be followed
+	respStatusCode, body = tutils.GetResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/clone-batches/%s", batchResp.Id), &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode, body)
+	var status CloneBatchStatus
+	err = json.Unmarshal([]byte(body), &status)
+	require.NoError(t, err)
+	require.Len(t, status.Clones, 1)
+	require.Equal(t, batchResp.Clones[0].Id, status.Clones[0].Id)
+}
+
 func TestGetCloneStatus(t *testing.T) {
 	cloneId := uuid.New()
 	id := uuid.New()
@@ -2112,7 +4062,7 @@ func TestGetCloneStatus(t *testing.T) {
       "image_type": "aws"
     }
   ]
-}`))
+}`), "default")
 	require.NoError(t, err)
 	srv, tokenSrv := startServerWithCustomDB(t, apiSrv.URL, "", dbase, "../../distributions", "")
 	defer func() {
@@ -2150,6 +4100,100 @@ func TestGetCloneStatus(t *testing.T) {
 	require.Equal(t, "us-east-2", awsUS.Region)
 }
 
+func TestRestoreCompose(t *testing.T) {
+	id := uuid.New()
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+	err = dbase.InsertCompose(id, "500000", "000000", nil, json.RawMessage("{}"), "default")
+	require.NoError(t, err)
+
+	srv, tokenSrv := startServerWithCustomDB(t, "", "", dbase, "../../distributions", "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	// composes which aren't deleted can't be restored
+	respStatusCode, _ := tutils.PostResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/restore", id), nil)
+	require.Equal(t, http.StatusNotFound, respStatusCode)
+
+	respStatusCode, _ = tutils.DeleteResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s", id), &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+
+	var result ComposesResponse
+	respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Meta.Count)
+
+	respStatusCode, _ = tutils.PostResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s/restore", id), nil)
+	require.Equal(t, http.StatusOK, respStatusCode)
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/composes", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Meta.Count)
+	require.Equal(t, id, result.Data[0].Id)
+
+	var audit AuditResponse
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/audit", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	err = json.Unmarshal([]byte(body), &audit)
+	require.NoError(t, err)
+	require.Equal(t, 2, audit.Meta.Count)
+	require.Equal(t, "restore", audit.Data[0].Action)
+}
+
+func TestGetAudit(t *testing.T) {
+	id := uuid.New()
+	dbase, err := dbc.NewDB()
+	require.NoError(t, err)
+	err = dbase.InsertCompose(id, "500000", "000000", nil, json.RawMessage("{}"), "default")
+	require.NoError(t, err)
+
+	srv, tokenSrv := startServerWithCustomDB(t, "", "", dbase, "../../distributions", "")
+	defer func() {
+		err := srv.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+	defer tokenSrv.Close()
+
+	var result AuditResponse
+	respStatusCode, body := tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/audit", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Meta.Count)
+	require.Contains(t, body, "\"data\":[]")
+
+	respStatusCode, _ = tutils.DeleteResponseBody(t, fmt.Sprintf("http://localhost:8086/api/image-builder/v1/composes/%s", id), &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/audit", &tutils.AuthString0)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Meta.Count)
+	require.Equal(t, "delete", result.Data[0].Action)
+	require.Equal(t, id, result.Data[0].TargetId)
+	require.Equal(t, "user", result.Data[0].Username)
+	require.Empty(t, result.Data[0].RequestHash)
+
+	// other orgs can't see the entries
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/audit", &tutils.AuthString1)
+	require.Equal(t, http.StatusOK, respStatusCode)
+	err = json.Unmarshal([]byte(body), &result)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Meta.Count)
+
+	respStatusCode, body = tutils.GetResponseBody(t, "http://localhost:8086/api/image-builder/v1/audit", &tutils.AuthString0NonAdmin)
+	require.Equal(t, http.StatusForbidden, respStatusCode)
+	require.Contains(t, body, "Only organization administrators can access the audit log")
+}
+
 func TestValidateSpec(t *testing.T) {
 	spec, err := GetSwagger()
 	require.NoError(t, err)
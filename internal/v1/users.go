@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/image-builder/internal/composer"
+)
+
+// Users who don't list their groups are made administrators, as they were
+// before the groups could be chosen
+const defaultUserGroup = "wheel"
+
+var (
+	// crypt(3) hashes of the methods the distributions support
+	passwordHashRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`^\$[56]\$(rounds=[0-9]+\$)?[./0-9A-Za-z]{1,16}\$[./0-9A-Za-z]{43,86}$`),
+		regexp.MustCompile(`^\$y\$[./0-9A-Za-z]+\$[./0-9A-Za-z]{1,86}\$[./0-9A-Za-z]{43}$`),
+		regexp.MustCompile(`^\$2b\$[0-9]{2}\$[./0-9A-Za-z]{53}$`),
+	}
+
+	sshKeyTypes = map[string]bool{
+		"ssh-rsa":                            true,
+		"ssh-ed25519":                        true,
+		"ecdsa-sha2-nistp256":                true,
+		"ecdsa-sha2-nistp384":                true,
+		"ecdsa-sha2-nistp521":                true,
+		"sk-ssh-ed25519@openssh.com":         true,
+		"sk-ecdsa-sha2-nistp256@openssh.com": true,
+	}
+)
+
+// validateUsers checks the users and groups customizations, the name and id
+// formats are checked by the request validation.
+func validateUsers(cust *Customizations, it ImageTypes) error {
+	if cust.Users == nil && cust.Groups == nil {
+		return nil
+	}
+
+	// users of OSTree commits are created by the installer deploying them
+	if it == ImageTypesEdgeCommit || it == ImageTypesRhelEdgeCommit {
+		return echo.NewHTTPError(http.StatusBadRequest, "User and group customizations don't apply to OSTree commits, add them to the installer instead")
+	}
+
+	if cust.Groups != nil {
+		names := map[string]bool{}
+		gids := map[int]bool{}
+		for _, g := range *cust.Groups {
+			if names[g.Name] {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Group %s is listed more than once", g.Name))
+			}
+			names[g.Name] = true
+			if g.Gid != nil {
+				if gids[*g.Gid] {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Gid %d of group %s is taken by another group", *g.Gid, g.Name))
+				}
+				gids[*g.Gid] = true
+			}
+		}
+	}
+
+	if cust.Users != nil {
+		names := map[string]bool{}
+		uids := map[int]bool{}
+		for _, u := range *cust.Users {
+			if names[u.Name] {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("User %s is listed more than once", u.Name))
+			}
+			names[u.Name] = true
+			if u.Uid != nil {
+				if uids[*u.Uid] {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Uid %d of user %s is taken by another user", *u.Uid, u.Name))
+				}
+				uids[*u.Uid] = true
+			}
+
+			keys := userSSHKeys(u)
+			for _, key := range keys {
+				err := validateSSHKey(key)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid SSH key of user %s: %v", u.Name, err))
+				}
+			}
+			if u.Password != nil && !isPasswordHash(*u.Password) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("The password of user %s isn't a supported crypt(3) hash, plain text passwords aren't accepted", u.Name))
+			}
+			if len(keys) == 0 && u.Password == nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("User %s needs an SSH key or a password to log in", u.Name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// userSSHKeys returns all keys of the user, an empty ssh_key counts as none
+func userSSHKeys(u User) []string {
+	var keys []string
+	if u.SshKey != nil && *u.SshKey != "" {
+		keys = append(keys, *u.SshKey)
+	}
+	if u.SshKeys != nil {
+		keys = append(keys, *u.SshKeys...)
+	}
+	return keys
+}
+
+// validateSSHKey checks that the key is an authorized_keys line of a
+// supported key type, without options. The key blob has to start with its
+// type, as every OpenSSH public key does.
+func validateSSHKey(key string) error {
+	if strings.ContainsAny(key, "\r\n") {
+		return fmt.Errorf("a key spans a single line")
+	}
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return fmt.Errorf("expected the key type followed by the key")
+	}
+	if !sshKeyTypes[fields[0]] {
+		return fmt.Errorf("unsupported key type %q", fields[0])
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return fmt.Errorf("the key isn't base64 encoded")
+	}
+	if len(blob) < 4 {
+		return fmt.Errorf("the key is truncated")
+	}
+	typeLen := binary.BigEndian.Uint32(blob)
+	if uint64(typeLen) > uint64(len(blob)-4) || !bytes.Equal(blob[4:4+typeLen], []byte(fields[0])) {
+		return fmt.Errorf("the key isn't a %s key", fields[0])
+	}
+	return nil
+}
+
+func isPasswordHash(password string) bool {
+	for _, re := range passwordHashRegexes {
+		if re.MatchString(password) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildUsers(users []User) []composer.User {
+	var res []composer.User
+	for _, u := range users {
+		groups := []string{defaultUserGroup}
+		if u.Groups != nil {
+			groups = *u.Groups
+		}
+		user := composer.User{
+			Name:     u.Name,
+			Groups:   &groups,
+			Password: u.Password,
+			Home:     u.Home,
+			Shell:    u.Shell,
+			Uid:      u.Uid,
+			Gid:      u.Gid,
+		}
+		// composer takes the keys as the content of authorized_keys
+		if keys := userSSHKeys(u); len(keys) > 0 {
+			key := strings.Join(keys, "\n")
+			user.Key = &key
+		}
+		res = append(res, user)
+	}
+	return res
+}
+
+func buildGroups(groups []Group) []composer.Group {
+	var res []composer.Group
+	for _, g := range groups {
+		res = append(res, composer.Group{
+			Name: g.Name,
+			Gid:  g.Gid,
+		})
+	}
+	return res
+}